@@ -6,9 +6,16 @@ import (
 	"os"
 
 	category_model "pos-go/models/category_model"
+	ingredient_model "pos-go/models/ingredient_model"
 	menu_model "pos-go/models/menu_model"
 	promo_model "pos-go/models/promo_model"
+	purchase_model "pos-go/models/purchase_model"
+	refund_model "pos-go/models/refund_model"
+	report_model "pos-go/models/report_model"
 	settlement_model "pos-go/models/settlement_model"
+	stock_model "pos-go/models/stock_model"
+	table_model "pos-go/models/table_model"
+	tax_model "pos-go/models/tax_model"
 	transaction_model "pos-go/models/transaction_model"
 	user_model "pos-go/models/user_model"
 
@@ -50,10 +57,32 @@ func ConnectDatabase() {
 		&user_model.User{},
 		&category_model.Category{},
 		&menu_model.Menu{},
+		&menu_model.ModifierGroup{},
+		&menu_model.ModifierOption{},
 		&transaction_model.Transaction{},
 		&transaction_model.TransactionItem{},
+		&transaction_model.TransactionItemModifier{},
 		&promo_model.Promo{},
 		&settlement_model.Settlement{},
+		&settlement_model.SettlementDenomination{},
+		&settlement_model.SettlementRevision{},
+		&settlement_model.Shift{},
+		&settlement_model.ShiftCashMovement{},
+		&refund_model.Refund{},
+		&refund_model.RefundItem{},
+		&tax_model.TaxRule{},
+		&transaction_model.TransactionTax{},
+		&transaction_model.Payment{},
+		&transaction_model.ReceiptToken{},
+		&transaction_model.OrderCounter{},
+		&stock_model.StockMovement{},
+		&ingredient_model.Ingredient{},
+		&ingredient_model.RecipeLine{},
+		&ingredient_model.IngredientMovement{},
+		&purchase_model.Purchase{},
+		&purchase_model.PurchaseItem{},
+		&table_model.Table{},
+		&report_model.ZReport{},
 	)
 	if err != nil {
 		log.Fatal("Migrasi gagal:", err)
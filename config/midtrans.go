@@ -2,15 +2,24 @@ package config
 
 import (
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
 	"os"
 )
 
 var MidtransClient snap.Client
 
+// MidtransCoreClient untuk Core API (refund, cek status)
+var MidtransCoreClient coreapi.Client
+
+// MidtransServerKey dipakai juga untuk verifikasi signature_key pada webhook notifikasi
+var MidtransServerKey string
+
 func InitMidtrans() {
 	serverKey := os.Getenv("server_key_mid")
+	MidtransServerKey = serverKey
 
 	MidtransClient.New(serverKey, midtrans.Sandbox)
+	MidtransCoreClient.New(serverKey, midtrans.Sandbox)
 	// Untuk production: midtrans.Production
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+	"pos-go/utils"
+	"strconv"
+	"strings"
+)
+
+// PrinterConfig pengaturan cetak struk & tiket dapur ke printer thermal ESC/POS
+type PrinterConfig struct {
+	PaperWidth     int         // lebar kertas default (mm): 58 atau 80
+	ReceiptHeader  []string    // baris teks di atas struk (nama toko, alamat, ...)
+	ReceiptFooter  []string    // baris teks di bawah struk
+	ReceiptQR      string      // isi QR di bawah struk jika link struk digital (RECEIPT_PUBLIC_URL) tidak diatur. Kosong = tanpa QR
+	Logo           image.Image // logo di atas struk, nil jika tidak ada
+	ReceiptPrinter string      // alamat printer kasir (host:port, raw TCP); kosong = hanya unduh
+	KitchenPrinter string      // alamat printer dapur; kosong = pakai ReceiptPrinter
+}
+
+// Printer pengaturan printer dari env (lihat InitPrinter)
+var Printer = PrinterConfig{PaperWidth: 58}
+
+// InitPrinter membaca env PRINTER_PAPER_WIDTH, RECEIPT_HEADER / RECEIPT_FOOTER (baris dipisah "|"), RECEIPT_QR,
+// RECEIPT_LOGO_PATH (PNG / JPEG), RECEIPT_PRINTER_ADDR dan KITCHEN_PRINTER_ADDR (mis. 192.168.1.50:9100).
+func InitPrinter() {
+	if v := os.Getenv("PRINTER_PAPER_WIDTH"); v != "" {
+		width, err := strconv.Atoi(v)
+		if err != nil || !utils.PaperSupported(width) {
+			log.Fatal("PRINTER_PAPER_WIDTH harus 58 atau 80:", v)
+		}
+		Printer.PaperWidth = width
+	}
+	Printer.ReceiptHeader = splitLines(os.Getenv("RECEIPT_HEADER"))
+	Printer.ReceiptFooter = splitLines(os.Getenv("RECEIPT_FOOTER"))
+	Printer.ReceiptQR = os.Getenv("RECEIPT_QR")
+	Printer.ReceiptPrinter = os.Getenv("RECEIPT_PRINTER_ADDR")
+	Printer.KitchenPrinter = os.Getenv("KITCHEN_PRINTER_ADDR")
+	if Printer.KitchenPrinter == "" {
+		Printer.KitchenPrinter = Printer.ReceiptPrinter
+	}
+
+	if path := os.Getenv("RECEIPT_LOGO_PATH"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Println("Logo struk tidak bisa dibuka, struk dicetak tanpa logo:", err)
+			return
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		if err != nil {
+			log.Println("Logo struk bukan PNG / JPEG yang valid, struk dicetak tanpa logo:", err)
+			return
+		}
+		Printer.Logo = img
+	}
+}
+
+func splitLines(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	lines := strings.Split(s, "|")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"log"
+	"os"
+	"pos-go/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StoreCalendar zona waktu toko dan jam pergantian hari bisnis. Dipakai untuk semua batas hari laporan, settlement,
+// dan timestamp struk. Env STORE_TIMEZONE (nama IANA, default Asia/Jakarta) dan BUSINESS_DAY_CUTOFF_HOUR (0-23, default 0).
+var StoreCalendar = utils.BusinessCalendar{Location: time.UTC}
+
+// SettlementNoteThreshold selisih settlement (nilai absolut) yang mewajibkan catatan kasir.
+// Diatur lewat env SETTLEMENT_NOTE_THRESHOLD (rupiah), default Rp 10.000.
+var SettlementNoteThreshold utils.Money = 10000
+
+// ReceiptPublicURL awalan link struk digital, mis. https://pos.example.com/receipt (link = ReceiptPublicURL + "/" + token).
+// Diatur lewat env RECEIPT_PUBLIC_URL; kosong = link tidak dicetak sebagai QR di struk.
+var ReceiptPublicURL string
+
+// ReceiptTokenTTL masa berlaku default token struk digital. Env RECEIPT_TOKEN_TTL_DAYS, default 0 = tidak kedaluwarsa.
+var ReceiptTokenTTL time.Duration
+
+// OrderNumberPrefix awalan nomor pesanan harian (A -> A-001, A-002, ...). Env ORDER_NUMBER_PREFIX (maks. 4 huruf/angka),
+// default "A"; kosong = nomor tanpa awalan.
+var OrderNumberPrefix = "A"
+
+// InitStore membaca pengaturan toko dari env
+func InitStore() {
+	tz := os.Getenv("STORE_TIMEZONE")
+	if tz == "" {
+		tz = "Asia/Jakarta"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Fatal("STORE_TIMEZONE tidak valid:", tz)
+	}
+	StoreCalendar.Location = loc
+
+	if v := os.Getenv("BUSINESS_DAY_CUTOFF_HOUR"); v != "" {
+		hour, err := strconv.Atoi(v)
+		if err != nil || hour < 0 || hour > 23 {
+			log.Fatal("BUSINESS_DAY_CUTOFF_HOUR harus 0-23:", v)
+		}
+		StoreCalendar.CutoffHour = hour
+	}
+
+	if v := os.Getenv("SETTLEMENT_NOTE_THRESHOLD"); v != "" {
+		threshold, err := utils.ParseMoney(v)
+		if err != nil || threshold < 0 {
+			log.Fatal("SETTLEMENT_NOTE_THRESHOLD tidak valid:", v)
+		}
+		SettlementNoteThreshold = threshold
+	}
+
+	ReceiptPublicURL = strings.TrimRight(os.Getenv("RECEIPT_PUBLIC_URL"), "/")
+	if v := os.Getenv("RECEIPT_TOKEN_TTL_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			log.Fatal("RECEIPT_TOKEN_TTL_DAYS tidak valid:", v)
+		}
+		ReceiptTokenTTL = time.Duration(days) * 24 * time.Hour
+	}
+
+	if v, ok := os.LookupEnv("ORDER_NUMBER_PREFIX"); ok {
+		prefix := strings.ToUpper(strings.TrimSpace(v))
+		if len(prefix) > 4 || strings.IndexFunc(prefix, func(r rune) bool {
+			return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+		}) >= 0 {
+			log.Fatal("ORDER_NUMBER_PREFIX harus maksimal 4 huruf / angka:", v)
+		}
+		OrderNumberPrefix = prefix
+	}
+}
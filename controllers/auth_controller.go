@@ -218,7 +218,14 @@ func GetCurrentUser(c *gin.Context) {
 }
 
 func GetAllUsers(c *gin.Context) {
-	users, err := authService.GetAllUsers()
+	var filter dto.UserListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.UserSortColumns, "created_at")
+
+	users, total, err := authService.GetAllUsers(filter, page)
 	if err != nil {
 		if errors.Is(err, services.ErrGetUsersFailed) {
 			utils.ErrorResponseInternal(c, "Gagal mengambil daftar user")
@@ -228,7 +235,7 @@ func GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponseOK(c, "Berhasil mengambil daftar user", users)
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar user", utils.NewPagedData(users, page, total))
 }
 
 func DeleteUser(c *gin.Context) {
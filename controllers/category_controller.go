@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"pos-go/dto"
 	"pos-go/services"
 	"pos-go/utils"
@@ -68,3 +69,45 @@ func GetAllCategories(c *gin.Context) {
 
 	utils.SuccessResponseOK(c, "Berhasil mengambil daftar category", categories)
 }
+
+func UpdateCategory(c *gin.Context) {
+	var input dto.UpdateCategoryDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errorsMap := make(map[string]string)
+
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range ve {
+				field := fe.Field()
+
+				switch fe.Tag() {
+				case "max":
+					errorsMap[field] = "Maksimal " + fe.Param() + " karakter"
+				default:
+					errorsMap[field] = "Field tidak valid"
+				}
+			}
+
+			utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+			return
+		}
+
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	category, err := categoryService.UpdateCategory(c.Param("id"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			utils.ErrorResponseNotFound(c, "Category tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNameExists) {
+			utils.ErrorResponseBadRequest(c, "Nama category sudah digunakan", nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengupdate category")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Category berhasil diupdate", category)
+}
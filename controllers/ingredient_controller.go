@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var ingredientService services.IngredientService = services.NewIngredientService()
+
+// ingredientValidationErrors menerjemahkan error validasi bahan / resep / pembelian
+func ingredientValidationErrors(c *gin.Context, err error) {
+	errorsMap := make(map[string]string)
+
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		for _, fe := range ve {
+			field := fe.Field()
+			switch fe.Tag() {
+			case "required":
+				errorsMap[field] = "Field wajib diisi"
+			case "gt":
+				errorsMap[field] = "Nilai harus lebih dari " + fe.Param()
+			case "min":
+				errorsMap[field] = "Nilai minimal " + fe.Param()
+			case "max":
+				errorsMap[field] = "Maksimal " + fe.Param() + " karakter"
+			case "oneof":
+				errorsMap[field] = "Pilihan tidak valid"
+			case "uuid":
+				errorsMap[field] = "ID tidak valid"
+			default:
+				errorsMap[field] = "Field tidak valid"
+			}
+		}
+		utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+		return
+	}
+
+	utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+}
+
+// currentUserID membaca user_id dari context (diset AuthMiddleware)
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists || userIDVal == nil {
+		utils.ErrorResponseUnauthorized(c, "User tidak ditemukan")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDVal.(string))
+	if err != nil {
+		utils.ErrorResponseUnauthorized(c, "User ID tidak valid")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func CreateIngredient(c *gin.Context) {
+	var input dto.CreateIngredientDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	ingredient, err := ingredientService.CreateIngredient(input)
+	if err != nil {
+		if errors.Is(err, services.ErrIngredientNameExists) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal membuat bahan")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Bahan berhasil dibuat", ingredient)
+}
+
+func GetAllIngredients(c *gin.Context) {
+	var filter dto.IngredientListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.IngredientSortColumns, "created_at")
+
+	ingredients, total, err := ingredientService.GetAllIngredients(filter, page)
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar bahan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar bahan", utils.NewPagedData(ingredients, page, total))
+}
+
+// GetLowStockIngredients GET /ingredient/low-stock — bahan dengan stok di bawah / sama dengan batas minimum (admin)
+func GetLowStockIngredients(c *gin.Context) {
+	ingredients, err := ingredientService.GetLowStockIngredients()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar bahan stok menipis")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar bahan stok menipis", ingredients)
+}
+
+func UpdateIngredient(c *gin.Context) {
+	var input dto.UpdateIngredientDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	ingredient, err := ingredientService.UpdateIngredient(c.Param("id"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrIngredientNotFound) {
+			utils.ErrorResponseNotFound(c, "Bahan tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrIngredientNameExists) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengupdate bahan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Bahan berhasil diupdate", ingredient)
+}
+
+func DeleteIngredient(c *gin.Context) {
+	if err := ingredientService.DeleteIngredient(c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrIngredientNotFound) {
+			utils.ErrorResponseNotFound(c, "Bahan tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrIngredientInUse) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menghapus bahan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Bahan berhasil dihapus", nil)
+}
+
+// AdjustIngredient POST /ingredient/:id/adjust — admin mencatat waste / hasil stock opname. Body: { type, quantity, note }.
+func AdjustIngredient(c *gin.Context) {
+	var input dto.IngredientAdjustmentDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	movement, err := ingredientService.AdjustIngredient(c.Param("id"), input, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrIngredientNotFound) {
+			utils.ErrorResponseNotFound(c, "Bahan tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrInvalidIngredientAdjustment) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menyesuaikan stok bahan")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Stok bahan berhasil disesuaikan", movement)
+}
+
+// GetIngredientMovements GET /ingredient/:id/movements — riwayat perubahan stok bahan (admin)
+func GetIngredientMovements(c *gin.Context) {
+	var filter dto.IngredientMovementListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.IngredientMovementSortColumns, "created_at")
+
+	movements, total, err := ingredientService.GetIngredientMovements(c.Param("id"), filter, page)
+	if err != nil {
+		if errors.Is(err, services.ErrIngredientNotFound) {
+			utils.ErrorResponseNotFound(c, "Bahan tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrGetIngredientsFailed) {
+			utils.ErrorResponseInternal(c, "Gagal mengambil riwayat stok bahan")
+			return
+		}
+		utils.ErrorResponseBadRequest(c, "Format tanggal tidak valid (YYYY-MM-DD)", nil)
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil riwayat stok bahan", utils.NewPagedData(movements, page, total))
+}
+
+// GetRecipe GET /menu/:id/recipe — resep menu (admin)
+func GetRecipe(c *gin.Context) {
+	lines, err := ingredientService.GetRecipe(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengambil resep")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil resep", lines)
+}
+
+// SetRecipe PUT /menu/:id/recipe — ganti seluruh resep menu. Body: { lines: [{ ingredient_id, quantity }] }.
+func SetRecipe(c *gin.Context) {
+	var input dto.SetRecipeDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	lines, err := ingredientService.SetRecipe(c.Param("id"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrIngredientNotFound) || errors.Is(err, services.ErrDuplicateRecipeIngredient) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menyimpan resep")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Resep berhasil disimpan", lines)
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"io"
+	"pos-go/services"
+	"pos-go/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KitchenStream GET /kitchen/stream — Server-Sent Events pesanan baru & perubahan status.
+// Koki menerima pesanan pending/cooking, kasir menerima pesanan ready, admin menerima semua.
+// Query station (opsional, mis. grill / drinks) membatasi pesanan & item ke stasiun tersebut.
+// Event pertama "snapshot" berisi antrian saat ini; "ping" dikirim berkala agar koneksi tidak diputus proxy.
+func KitchenStream(c *gin.Context) {
+	roleVal, exists := c.Get("role")
+	if !exists {
+		utils.ErrorResponseUnauthorized(c, "Role tidak ditemukan")
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(roleVal.(string)))
+	station := services.NormalizeStation(c.Query("station"))
+
+	// Subscribe sebelum mengambil snapshot agar tidak ada event yang terlewat di antaranya
+	events, unsubscribe := services.OrderEvents.Subscribe()
+	defer unsubscribe()
+
+	queue, err := transactionService.GetKitchenQueue(role, station)
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil antrian pesanan")
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", queue)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				// Hub ditutup (server shutdown)
+				return false
+			}
+			if services.EventVisibleToRole(event, role) {
+				if stationEvent, ok := services.EventForStation(event, station); ok {
+					c.SSEvent(event.Type, stationEvent)
+				}
+			}
+			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("ping", t.Format(time.RFC3339))
+			return true
+		}
+	})
+}
@@ -71,6 +71,11 @@ func CreateMenu(c *gin.Context) {
 		}
 	}
 
+	// track_stock sama seperti is_available (default: false)
+	if trackStock, err := strconv.ParseBool(c.PostForm("track_stock")); err == nil {
+		input.TrackStock = trackStock
+	}
+
 	// Create menu via service
 	menu, err := menuService.CreateMenu(input)
 	if err != nil {
@@ -95,7 +100,14 @@ func CreateMenu(c *gin.Context) {
 }
 
 func GetAllMenus(c *gin.Context) {
-	menus, err := menuService.GetAllMenus()
+	var filter dto.MenuListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.MenuSortColumns, "created_at")
+
+	menus, total, err := menuService.GetAllMenus(filter, page)
 	if err != nil {
 		if errors.Is(err, services.ErrGetMenusFailed) {
 			utils.ErrorResponseInternal(c, "Gagal mengambil daftar menu")
@@ -105,7 +117,7 @@ func GetAllMenus(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponseOK(c, "Berhasil mengambil daftar menu", menus)
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar menu", utils.NewPagedData(menus, page, total))
 }
 
 func GetPublicMenus(c *gin.Context) {
@@ -181,6 +193,11 @@ func UpdateMenu(c *gin.Context) {
 		}
 	}
 
+	// track_stock hanya diubah jika dikirim
+	if trackStock, err := strconv.ParseBool(c.PostForm("track_stock")); err == nil {
+		input.TrackStock = &trackStock
+	}
+
 	// Update menu via service
 	menu, err := menuService.UpdateMenu(menuID, input)
 	if err != nil {
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var modifierService services.ModifierService = services.NewModifierService()
+
+// GetModifierGroups GET /menu/:id/modifiers — grup modifier menu beserta semua opsi (admin)
+func GetModifierGroups(c *gin.Context) {
+	groups, err := modifierService.GetModifierGroups(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengambil modifier menu")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil modifier menu", groups)
+}
+
+// SetModifierGroups PUT /menu/:id/modifiers — ganti seluruh grup modifier menu.
+// Body: { groups: [{ name, is_required, min_select, max_select, options: [{ name, price_delta, is_available }] }] }.
+func SetModifierGroups(c *gin.Context) {
+	var input dto.SetModifierGroupsDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errorsMap := make(map[string]string)
+
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range ve {
+				field := fe.Field()
+				switch fe.Tag() {
+				case "required":
+					errorsMap[field] = "Field wajib diisi"
+				case "min":
+					errorsMap[field] = "Nilai minimal " + fe.Param()
+				case "max":
+					errorsMap[field] = "Maksimal " + fe.Param() + " karakter"
+				default:
+					errorsMap[field] = "Field tidak valid"
+				}
+			}
+			utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+			return
+		}
+
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	groups, err := modifierService.SetModifierGroups(c.Param("id"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrInvalidModifierGroup) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menyimpan modifier menu")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Modifier menu berhasil disimpan", groups)
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var paymentService services.PaymentService = services.NewPaymentService()
+
+// paymentError memetakan error payment / split bill ke response
+func paymentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+	case errors.Is(err, services.ErrPaymentNotFound):
+		utils.ErrorResponseNotFound(c, err.Error())
+	case errors.Is(err, services.ErrPaymentExceedsRemaining), errors.Is(err, services.ErrInvalidSplit),
+		errors.Is(err, services.ErrPaymentNotPending), errors.Is(err, services.ErrTenderedBelowAmount):
+		utils.ErrorResponseBadRequest(c, err.Error(), nil)
+	case errors.Is(err, services.ErrPaymentNotAllowed), errors.Is(err, services.ErrStatusConflict):
+		utils.ErrorResponseConflict(c, err.Error())
+	default:
+		utils.ErrorResponseInternal(c, fallback)
+	}
+}
+
+// CreatePayment POST /transaction/:id/payments — kasir mencatat satu tender (tunai langsung paid, non-cash mendapat snap token)
+func CreatePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	payment, err := paymentService.CreatePayment(id, req, &cashierID)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) || errors.Is(err, services.ErrPaymentExceedsRemaining) ||
+			errors.Is(err, services.ErrPaymentNotAllowed) || errors.Is(err, services.ErrStatusConflict) ||
+			errors.Is(err, services.ErrTenderedBelowAmount) {
+			paymentError(c, err, "")
+			return
+		}
+		// Error dari Midtrans / token pembayaran: payment tidak disimpan
+		utils.ErrorResponseInternal(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Pembayaran berhasil dicatat", payment)
+}
+
+// GetPayments GET /transaction/:id/payments — daftar tender dan sisa tagihan
+func GetPayments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	payments, err := paymentService.GetPayments(id)
+	if err != nil {
+		paymentError(c, err, "Gagal mengambil data pembayaran")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil data pembayaran", payments)
+}
+
+// CancelPayment DELETE /transaction/:id/payments/:paymentId — batalkan tender non-cash yang masih pending
+func CancelPayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	paymentID, err := uuid.Parse(c.Param("paymentId"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID pembayaran tidak valid", nil)
+		return
+	}
+
+	if err := paymentService.CancelPayment(id, paymentID); err != nil {
+		paymentError(c, err, "Gagal membatalkan pembayaran")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Pembayaran berhasil dibatalkan", nil)
+}
+
+// SplitBill POST /transaction/:id/split — pratinjau pembagian tagihan rata / per item (tidak menyimpan apa pun)
+func SplitBill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	var req dto.SplitBillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	split, err := paymentService.SplitBill(id, req)
+	if err != nil {
+		paymentError(c, err, "Gagal menghitung pembagian tagihan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Pembagian tagihan berhasil dihitung", split)
+}
+
+// handlePaymentNotification meneruskan notifikasi Midtrans untuk payment split bill
+func handlePaymentNotification(c *gin.Context, notification dto.MidtransNotification, paymentID uuid.UUID) {
+	var status string
+	switch notification.TransactionStatus {
+	case "capture", "settlement":
+		status = services.TenderStatusPaid
+	case "pending":
+		status = services.TenderStatusPending
+	case "expire":
+		status = services.TenderStatusExpired
+	case "deny", "cancel":
+		status = services.TenderStatusFailed
+	default:
+		log.Printf("Notifikasi Midtrans diabaikan (order_id=%s): status %q tidak dikenal", notification.OrderID, notification.TransactionStatus)
+		c.JSON(200, gin.H{"status": "ignored"})
+		return
+	}
+
+	if err := paymentService.UpdatePaymentStatus(paymentID, status); err != nil {
+		if errors.Is(err, services.ErrInvalidTransition) {
+			log.Printf("Notifikasi Midtrans diabaikan (order_id=%s, status=%s): %v", notification.OrderID, notification.TransactionStatus, err)
+			c.JSON(200, gin.H{"status": "ignored"})
+			return
+		}
+		utils.ErrorResponseInternal(c, "Failed to update payment")
+		return
+	}
+
+	c.JSON(200, gin.H{"status": "success"})
+}
@@ -56,13 +56,20 @@ func CreatePromo(c *gin.Context) {
 }
 
 func GetAllPromos(c *gin.Context) {
-	promos, err := promoService.GetAllPromos()
+	var filter dto.PromoListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.PromoSortColumns, "created_at")
+
+	promos, total, err := promoService.GetAllPromos(filter, page)
 	if err != nil {
 		utils.ErrorResponseInternal(c, "Gagal mengambil daftar promo")
 		return
 	}
 
-	utils.SuccessResponseOK(c, "Berhasil mengambil daftar promo", promos)
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar promo", utils.NewPagedData(promos, page, total))
 }
 
 func GetPromoByID(c *gin.Context) {
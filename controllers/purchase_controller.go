@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var purchaseService services.PurchaseService = services.NewPurchaseService()
+
+// CreatePurchase POST /purchase — catat penerimaan barang dari supplier (stok bahan bertambah)
+func CreatePurchase(c *gin.Context) {
+	var input dto.CreatePurchaseDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	purchase, err := purchaseService.CreatePurchase(input, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrIngredientNotFound) || errors.Is(err, services.ErrInvalidReceivedAt) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menyimpan pembelian")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Pembelian berhasil dicatat", purchase)
+}
+
+func GetAllPurchases(c *gin.Context) {
+	var filter dto.PurchaseListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.PurchaseSortColumns, "received_at")
+
+	purchases, total, err := purchaseService.GetAllPurchases(filter, page)
+	if err != nil {
+		if errors.Is(err, services.ErrGetPurchasesFailed) {
+			utils.ErrorResponseInternal(c, "Gagal mengambil daftar pembelian")
+			return
+		}
+		utils.ErrorResponseBadRequest(c, "Format tanggal tidak valid (YYYY-MM-DD)", nil)
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar pembelian", utils.NewPagedData(purchases, page, total))
+}
+
+func GetPurchaseByID(c *gin.Context) {
+	purchase, err := purchaseService.GetPurchaseByID(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrPurchaseNotFound) {
+			utils.ErrorResponseNotFound(c, "Pembelian tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengambil detail pembelian")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil detail pembelian", purchase)
+}
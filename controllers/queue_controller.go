@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNowServing GET /queue/now-serving — nomor pesanan hari ini yang siap diambil, untuk layar antrian pelanggan (publik).
+// Hanya nomor pesanan & jenis pesanan; nama dan data pelanggan tidak ditampilkan.
+func GetNowServing(c *gin.Context) {
+	res, err := transactionService.GetNowServing()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil antrian pesanan")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	utils.SuccessResponseOK(c, "Antrian pesanan berhasil diambil", res)
+}
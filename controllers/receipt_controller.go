@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"pos-go/config"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicReceiptTemplate halaman struk digital (mobile friendly, tanpa aset eksternal)
+var publicReceiptTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"rp": func(m utils.Money) string { return m.Rupiah() },
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Struk</title>
+<style>
+body{font-family:ui-monospace,Menlo,Consolas,monospace;background:#f4f4f4;margin:0;padding:16px;color:#222}
+.receipt{max-width:380px;margin:0 auto;background:#fff;padding:20px;box-shadow:0 1px 4px rgba(0,0,0,.1)}
+h1{font-size:16px;text-align:center;margin:0 0 4px}
+.center{text-align:center}.muted{color:#777;font-size:12px}
+hr{border:0;border-top:1px dashed #999;margin:12px 0}
+.row{display:flex;justify-content:space-between;gap:8px}
+.mod{padding-left:12px;font-size:12px;color:#555}
+.total{font-weight:bold;font-size:16px}
+</style>
+</head>
+<body>
+<div class="receipt">
+{{range $i, $l := .Header}}{{if eq $i 0}}<h1>{{$l}}</h1>{{else}}<div class="center muted">{{$l}}</div>{{end}}{{end}}
+<hr>
+{{with .Receipt.OrderNumber}}<div class="row"><span>No. Pesanan</span><b>{{.}}</b></div>{{end}}
+<div class="row"><span>Waktu</span><span>{{.Receipt.CreatedAt}}</span></div>
+<div class="row"><span>Kasir</span><span>{{.Receipt.ClosedByUserName}}</span></div>
+{{if .Receipt.CustomerName}}<div class="row"><span>Pelanggan</span><span>{{.Receipt.CustomerName}}</span></div>{{end}}
+<div class="row"><span>Jenis</span><span>{{.Receipt.OrderType}}{{with .Receipt.TableNumber}} - Meja {{.}}{{end}}</span></div>
+<hr>
+{{range .Receipt.Items}}
+<div>{{.MenuName}}</div>
+{{range .Modifiers}}<div class="mod">+ {{.OptionName}}</div>{{end}}
+<div class="row"><span>&nbsp;&nbsp;{{.Quantity}} x {{rp .MenuPrice}}</span><span>{{rp .Subtotal}}</span></div>
+{{end}}
+<hr>
+<div class="row"><span>Subtotal</span><span>{{rp .Receipt.Subtotal}}</span></div>
+{{if .Receipt.Discount}}<div class="row"><span>Diskon</span><span>-{{rp .Receipt.Discount}}</span></div>{{end}}
+{{range .Receipt.Taxes}}<div class="row"><span>{{.Name}}{{if .IsInclusive}} (termasuk){{end}}</span><span>{{rp .Amount}}</span></div>{{end}}
+<div class="row total"><span>TOTAL</span><span>{{rp .Receipt.TotalAmount}}</span></div>
+<div class="row"><span>Bayar ({{.Receipt.PaymentMethod}})</span><span>{{.Receipt.PaymentStatus}}</span></div>
+{{if .Receipt.AmountTendered}}<div class="row"><span>Tunai</span><span>{{rp .Receipt.AmountTendered}}</span></div>
+<div class="row"><span>Kembali</span><span>{{rp .Receipt.ChangeAmount}}</span></div>{{end}}
+<hr>
+{{range .Footer}}<div class="center muted">{{.}}</div>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// GetPublicReceipt GET /receipt/:token — struk digital publik (tanpa login). HTML untuk browser, JSON jika
+// format=json atau header Accept meminta application/json. Tidak pernah menampilkan ID transaksi.
+func GetPublicReceipt(c *gin.Context) {
+	receipt, err := transactionService.GetPublicReceipt(c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReceiptTokenNotFound):
+			utils.ErrorResponseNotFound(c, err.Error())
+		case errors.Is(err, services.ErrReceiptTokenExpired):
+			utils.ErrorResponse(c, http.StatusGone, err.Error(), nil)
+		default:
+			utils.ErrorResponseInternal(c, "Gagal mengambil struk")
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		utils.SuccessResponseOK(c, "Struk berhasil diambil", receipt)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := publicReceiptTemplate.Execute(c.Writer, struct {
+		Header  []string
+		Footer  []string
+		Receipt *dto.PublicReceiptResponse
+	}{config.Printer.ReceiptHeader, config.Printer.ReceiptFooter, receipt}); err != nil {
+		log.Printf("Gagal merender struk digital: %v", err)
+	}
+}
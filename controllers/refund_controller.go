@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var refundService services.RefundService = services.NewRefundService()
+
+// CreateRefund POST /transaction/:id/refund — admin menyetujui refund full / partial. Body: { type, reason, items }.
+func CreateRefund(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	var req dto.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorsMap := make(map[string]string)
+
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range ve {
+				field := fe.Field()
+				switch fe.Tag() {
+				case "required":
+					errorsMap[field] = "Field wajib diisi"
+				case "oneof":
+					errorsMap[field] = "Pilihan tidak valid"
+				case "min":
+					errorsMap[field] = "Nilai minimal " + fe.Param()
+				default:
+					errorsMap[field] = "Field tidak valid"
+				}
+			}
+			utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+			return
+		}
+
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	userIDVal, exists := c.Get("user_id")
+	if !exists || userIDVal == nil {
+		utils.ErrorResponseUnauthorized(c, "User tidak ditemukan")
+		return
+	}
+	approvedBy, err := uuid.Parse(userIDVal.(string))
+	if err != nil {
+		utils.ErrorResponseUnauthorized(c, "User ID tidak valid")
+		return
+	}
+
+	refund, err := refundService.CreateRefund(id, req, approvedBy)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrRefundNotAllowed) || errors.Is(err, services.ErrRefundItemsRequired) ||
+			errors.Is(err, services.ErrRefundItemInvalid) || errors.Is(err, services.ErrRefundAmountZero) ||
+			errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrRefundExceedsTender) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrRefundGatewayFailed) {
+			utils.ErrorResponse(c, http.StatusBadGateway, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrStatusConflict) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal memproses refund")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Refund berhasil diproses", refund)
+}
+
+// GetRefunds GET /transaction/:id/refunds — riwayat refund satu transaksi.
+func GetRefunds(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	refunds, err := refundService.GetRefundsByTransaction(id)
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil data refund")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil data refund", refunds)
+}
@@ -2,6 +2,10 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"pos-go/dto"
 	"pos-go/services"
 	"pos-go/utils"
 	"strconv"
@@ -12,12 +16,20 @@ import (
 
 var reportService services.ReportService = services.NewReportService()
 
-// GetReportByDate mengembalikan laporan harian (agregasi + list transaksi) untuk tanggal yang diberikan.
-// Query: date (YYYY-MM-DD). Akses: admin (semua transaksi) & kasir (hanya transaksi yang ditutup oleh kasir itu).
+// GetReportByDate mengembalikan laporan harian (agregasi + list transaksi) untuk tanggal yang diberikan,
+// atau laporan periode dengan rincian per menu, kategori, jam, hari, jenis pesanan, metode bayar dan promo jika from & to diisi.
+// Query: date atau from & to (YYYY-MM-DD, inklusif); format=csv|xlsx untuk unduh list transaksi + rincian item
+// (CSV: satu sheet, pilih dengan sheet=transaksi|item). Akses: admin (semua transaksi) & kasir (hanya transaksi yang ditutup oleh kasir itu).
 func GetReportByDate(c *gin.Context) {
 	dateStr := c.Query("date")
-	if dateStr == "" {
-		utils.ErrorResponseBadRequest(c, "Parameter date (YYYY-MM-DD) wajib diisi", nil)
+	from, to := c.Query("from"), c.Query("to")
+	rangeMode := from != "" || to != ""
+	if rangeMode && (from == "" || to == "") {
+		utils.ErrorResponseBadRequest(c, "Parameter from dan to (YYYY-MM-DD) wajib diisi bersamaan", nil)
+		return
+	}
+	if !rangeMode && dateStr == "" {
+		utils.ErrorResponseBadRequest(c, "Parameter date atau from & to (YYYY-MM-DD) wajib diisi", nil)
 		return
 	}
 
@@ -41,6 +53,52 @@ func GetReportByDate(c *gin.Context) {
 		}
 	}
 
+	if format := c.Query("format"); format != "" {
+		if !rangeMode {
+			from, to = dateStr, dateStr
+		}
+		filename := "laporan-" + from
+		if to != from {
+			filename += "_" + to
+		}
+		writeExport(c, format, filename, func(w utils.TableWriter) error {
+			return reportService.ExportReport(from, to, cashierID, w)
+		}, func(err error) {
+			if errors.Is(err, services.ErrDatabaseError) {
+				utils.ErrorResponseInternal(c, "Gagal mengekspor laporan")
+				return
+			}
+			if errors.Is(err, services.ErrInvalidDateRange) {
+				utils.ErrorResponseBadRequest(c, "Parameter to tidak boleh lebih awal dari from", nil)
+				return
+			}
+			utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		})
+		return
+	}
+
+	if rangeMode {
+		report, err := reportService.GetReportByRange(from, to, cashierID)
+		if err != nil {
+			if errors.Is(err, services.ErrDatabaseError) {
+				utils.ErrorResponseInternal(c, "Gagal mengambil laporan")
+				return
+			}
+			if errors.Is(err, services.ErrInvalidDateRange) {
+				utils.ErrorResponseBadRequest(c, "Parameter to tidak boleh lebih awal dari from", nil)
+				return
+			}
+			if errors.Is(err, services.ErrReportRangeTooLong) {
+				utils.ErrorResponseBadRequest(c, err.Error(), nil)
+				return
+			}
+			utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+			return
+		}
+		utils.SuccessResponseOK(c, "Laporan periode berhasil diambil", report)
+		return
+	}
+
 	report, err := reportService.GetReportByDate(dateStr, cashierID)
 	if err != nil {
 		if errors.Is(err, services.ErrDatabaseError) {
@@ -54,6 +112,35 @@ func GetReportByDate(c *gin.Context) {
 	utils.SuccessResponseOK(c, "Laporan harian berhasil diambil", report)
 }
 
+// writeExport mengirim hasil export sebagai file unduhan (format csv / xlsx) secara streaming.
+// Jika export gagal sebelum ada byte yang terkirim, onError menulis response error JSON seperti biasa;
+// jika gagal di tengah unduhan, status sudah terkirim sehingga koneksi hanya diputus dan error dicatat di log.
+func writeExport(c *gin.Context, format, filename string, export func(w utils.TableWriter) error, onError func(err error)) {
+	w, contentType, err := utils.NewTableWriter(format, c.Writer, c.Query("sheet"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, err.Error(), nil)
+		return
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	c.Status(http.StatusOK)
+
+	if err = export(w); err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		onError(err)
+		return
+	}
+	log.Printf("Ekspor %s.%s terputus: %v", filename, format, err)
+	c.Abort()
+}
+
 // GetReportCharts mengembalikan data grafik harian dan bulanan. Query: days (default 7), months (default 6). Akses: admin.
 func GetReportCharts(c *gin.Context) {
 	days := 7
@@ -82,3 +169,97 @@ func GetReportCharts(c *gin.Context) {
 	utils.SuccessResponseOK(c, "Data grafik berhasil diambil", charts)
 }
 
+
+// GetIngredientUsageReport pemakaian bahan teoritis (resep) vs aktual per bahan.
+// Query: date_from, date_to (YYYY-MM-DD, inklusif). Akses: admin.
+func GetIngredientUsageReport(c *gin.Context) {
+	dateFrom := c.Query("date_from")
+	dateTo := c.Query("date_to")
+	if dateFrom == "" || dateTo == "" {
+		utils.ErrorResponseBadRequest(c, "Parameter date_from dan date_to (YYYY-MM-DD) wajib diisi", nil)
+		return
+	}
+
+	report, err := reportService.GetIngredientUsageReport(dateFrom, dateTo)
+	if err != nil {
+		if errors.Is(err, services.ErrDatabaseError) {
+			utils.ErrorResponseInternal(c, "Gagal mengambil laporan pemakaian bahan")
+			return
+		}
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Laporan pemakaian bahan berhasil diambil", report)
+}
+
+// zReportError memetakan error Z-report ke response HTTP
+func zReportError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrZReportAlreadyClosed), errors.Is(err, services.ErrZReportOpenShifts):
+		utils.ErrorResponseConflict(c, err.Error())
+	case errors.Is(err, services.ErrZReportDayNotOver):
+		utils.ErrorResponseBadRequest(c, err.Error(), nil)
+	case errors.Is(err, services.ErrDatabaseError):
+		utils.ErrorResponseInternal(c, fallback)
+	default:
+		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+	}
+}
+
+// GetZReport GET /report/z?date=YYYY-MM-DD — Z-report (laporan tutup hari) sebagai PDF; format=json untuk data mentah.
+// Hari yang belum ditutup dicetak sebagai draft tanpa nomor. Akses: admin.
+func GetZReport(c *gin.Context) {
+	dateStr := c.Query("date")
+	if dateStr == "" {
+		utils.ErrorResponseBadRequest(c, "Parameter date (YYYY-MM-DD) wajib diisi", nil)
+		return
+	}
+
+	report, err := reportService.GetZReport(dateStr)
+	if err != nil {
+		zReportError(c, err, "Gagal mengambil Z-report")
+		return
+	}
+	if c.Query("format") == "json" {
+		utils.SuccessResponseOK(c, "Z-report berhasil diambil", report)
+		return
+	}
+
+	filename := "z-report-" + dateStr
+	if report.Number != nil {
+		filename = fmt.Sprintf("z-report-%04d-%s", *report.Number, dateStr)
+	}
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, filename))
+	c.Status(http.StatusOK)
+	if err := services.WriteZReportPDF(report, c.Writer); err != nil {
+		log.Printf("Gagal menulis PDF %s: %v", filename, err)
+		c.Abort()
+	}
+}
+
+// CloseZReport POST /report/z/close — tutup hari: Z-report diberi nomor berurutan dan isinya dikunci. Akses: admin.
+func CloseZReport(c *gin.Context) {
+	var req dto.CloseZReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter date (YYYY-MM-DD) wajib diisi", nil)
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	report, err := reportService.CloseZReport(req.Date, userID)
+	if err != nil {
+		zReportError(c, err, "Gagal menutup hari")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Hari berhasil ditutup", report)
+}
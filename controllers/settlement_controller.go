@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"errors"
+	"pos-go/dto"
 	"pos-go/services"
 	"pos-go/utils"
 
@@ -11,7 +12,28 @@ import (
 
 var settlementService services.SettlementService = services.NewSettlementService()
 
-// GetSettlement GET /settlement?date=YYYY-MM-DD — expected_cash + settlement (jika sudah ada) untuk user yang login.
+// settlementError memetakan error shift / settlement ke response
+func settlementError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrNoOpenShift), errors.Is(err, services.ErrShiftAlreadyOpen):
+		utils.ErrorResponseConflict(c, err.Error())
+	case errors.Is(err, services.ErrSettlementNotSubmitted), errors.Is(err, services.ErrSettlementNotRejected):
+		utils.ErrorResponseConflict(c, err.Error())
+	case errors.Is(err, services.ErrActualCashRequired), errors.Is(err, services.ErrDenominationMismatch),
+		errors.Is(err, services.ErrSettlementNoteRequired), errors.Is(err, services.ErrReviewNoteRequired):
+		utils.ErrorResponseBadRequest(c, err.Error(), nil)
+	case errors.Is(err, services.ErrSettlementNotFound):
+		utils.ErrorResponseNotFound(c, err.Error())
+	case errors.Is(err, services.ErrSettlementNotOwner):
+		utils.ErrorResponseForbidden(c, err.Error())
+	case errors.Is(err, services.ErrDatabaseError):
+		utils.ErrorResponseInternal(c, fallback)
+	default:
+		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+	}
+}
+
+// GetSettlement GET /settlement?date=YYYY-MM-DD — shift user yang login yang dibuka di tanggal tersebut (rekap tunai + settlement).
 func GetSettlement(c *gin.Context) {
 	dateStr := c.Query("date")
 	if dateStr == "" {
@@ -19,70 +41,44 @@ func GetSettlement(c *gin.Context) {
 		return
 	}
 
-	userIDVal, exists := c.Get("user_id")
-	if !exists || userIDVal == nil {
-		utils.ErrorResponseUnauthorized(c, "User tidak ditemukan")
-		return
-	}
-	userID, err := uuid.Parse(userIDVal.(string))
-	if err != nil {
-		utils.ErrorResponseUnauthorized(c, "User ID tidak valid")
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	resp, err := settlementService.GetSettlementWithExpected(dateStr, userID)
+	resp, err := settlementService.GetSettlementsByDate(dateStr, userID)
 	if err != nil {
-		if errors.Is(err, services.ErrDatabaseError) {
-			utils.ErrorResponseInternal(c, "Gagal mengambil data settlement")
-			return
-		}
-		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		settlementError(c, err, "Gagal mengambil data settlement")
 		return
 	}
 
 	utils.SuccessResponseOK(c, "Data settlement berhasil diambil", resp)
 }
 
-// CreateSettlement POST /settlement — simpan settlement (tutup kasir). Body: { date, actual_cash }.
+// CreateSettlement POST /settlement — tutup shift yang sedang open. Body: { actual_cash, denominations, note }.
 func CreateSettlement(c *gin.Context) {
-	var req struct {
-		Date       string  `json:"date" binding:"required"`
-		ActualCash float64 `json:"actual_cash" binding:"required"`
-	}
+	var req dto.CreateSettlementRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponseBadRequest(c, "Format data tidak valid. Perlu date (YYYY-MM-DD) dan actual_cash", nil)
+		ingredientValidationErrors(c, err)
 		return
 	}
 
-	userIDVal, exists := c.Get("user_id")
-	if !exists || userIDVal == nil {
-		utils.ErrorResponseUnauthorized(c, "User tidak ditemukan")
-		return
-	}
-	userID, err := uuid.Parse(userIDVal.(string))
-	if err != nil {
-		utils.ErrorResponseUnauthorized(c, "User ID tidak valid")
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	settlement, err := settlementService.CreateSettlement(userID, req.Date, req.ActualCash)
+	settlement, err := settlementService.CreateSettlement(userID, req)
 	if err != nil {
-		if errors.Is(err, services.ErrSettlementAlreadyExists) {
-			utils.ErrorResponseBadRequest(c, "Settlement untuk tanggal ini sudah ada", nil)
-			return
-		}
-		if errors.Is(err, services.ErrDatabaseError) {
-			utils.ErrorResponseInternal(c, "Gagal menyimpan settlement")
-			return
-		}
-		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		settlementError(c, err, "Gagal menyimpan settlement")
 		return
 	}
 
-	utils.SuccessResponseCreated(c, "Settlement berhasil disimpan", settlement)
+	utils.SuccessResponseCreated(c, "Settlement berhasil disimpan, shift ditutup", settlement)
 }
 
-// GetSettlementStatusByDate GET /settlement/status-by-date?date=YYYY-MM-DD — admin only. Daftar kasir + expected cash + status settlement.
+// GetSettlementStatusByDate GET /settlement/status-by-date?date=YYYY-MM-DD — admin only. Daftar shift + expected cash + status settlement.
+// format=csv|xlsx untuk unduh (sheet settlement + rincian pecahan; CSV: sheet=settlement|pecahan).
 func GetSettlementStatusByDate(c *gin.Context) {
 	dateStr := c.Query("date")
 	if dateStr == "" {
@@ -90,15 +86,121 @@ func GetSettlementStatusByDate(c *gin.Context) {
 		return
 	}
 
+	if format := c.Query("format"); format != "" {
+		writeExport(c, format, "settlement-"+dateStr, func(w utils.TableWriter) error {
+			return settlementService.ExportSettlementStatus(dateStr, w)
+		}, func(err error) {
+			settlementError(c, err, "Gagal mengekspor status settlement")
+		})
+		return
+	}
+
 	resp, err := settlementService.GetSettlementStatusByDate(dateStr)
 	if err != nil {
-		if errors.Is(err, services.ErrDatabaseError) {
-			utils.ErrorResponseInternal(c, "Gagal mengambil status settlement")
-			return
-		}
-		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		settlementError(c, err, "Gagal mengambil status settlement")
 		return
 	}
 
 	utils.SuccessResponseOK(c, "Status settlement per kasir berhasil diambil", resp)
 }
+
+// GetSettlementDetail GET /settlement/:id — detail settlement + riwayat revisi. Kasir hanya bisa melihat miliknya sendiri.
+func GetSettlementDetail(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID settlement tidak valid", nil)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var ownerID *uuid.UUID
+	if role, _ := c.Get("role"); role != "admin" {
+		ownerID = &userID
+	}
+
+	settlement, err := settlementService.GetSettlementDetail(id, ownerID)
+	if err != nil {
+		settlementError(c, err, "Gagal mengambil data settlement")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Data settlement berhasil diambil", settlement)
+}
+
+// ResubmitSettlement PUT /settlement/:id — kasir mengajukan ulang settlement yang ditolak. Body sama dengan POST /settlement.
+func ResubmitSettlement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID settlement tidak valid", nil)
+		return
+	}
+
+	var req dto.CreateSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := settlementService.ResubmitSettlement(id, userID, req)
+	if err != nil {
+		settlementError(c, err, "Gagal mengajukan ulang settlement")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Settlement berhasil diajukan ulang", settlement)
+}
+
+// ReviewSettlement PATCH /settlement/:id/review — admin approve / reject. Body: { action, note }.
+func ReviewSettlement(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID settlement tidak valid", nil)
+		return
+	}
+
+	var req dto.ReviewSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := settlementService.ReviewSettlement(id, adminID, req)
+	if err != nil {
+		settlementError(c, err, "Gagal menyimpan review settlement")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Review settlement berhasil disimpan", settlement)
+}
+
+// GetSettlementDiscrepancies GET /settlement/discrepancies — admin only. Settlement dengan selisih yang belum selesai
+// serta settlement rejected (?date_from=&date_to=&page=&limit=&sort_by=&sort_dir=).
+func GetSettlementDiscrepancies(c *gin.Context) {
+	var filter dto.SettlementDiscrepancyFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.SettlementDiscrepancySortColumns, "date")
+
+	items, total, err := settlementService.GetUnresolvedDiscrepancies(filter, page)
+	if err != nil {
+		settlementError(c, err, "Gagal mengambil data selisih settlement")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil data selisih settlement", utils.NewPagedData(items, page, total))
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var shiftService services.ShiftService = services.NewShiftService()
+
+// OpenShift POST /shift/open — buka shift kasir dengan modal awal. Body: { opening_float, note }.
+func OpenShift(c *gin.Context) {
+	var req dto.OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := shiftService.OpenShift(userID, req)
+	if err != nil {
+		settlementError(c, err, "Gagal membuka shift")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Shift berhasil dibuka", shift)
+}
+
+// GetCurrentShift GET /shift/current — shift open user yang login beserta rekap tunai sampai saat ini
+func GetCurrentShift(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := shiftService.GetCurrentShift(userID)
+	if err != nil {
+		settlementError(c, err, "Gagal mengambil data shift")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Data shift berhasil diambil", shift)
+}
+
+// AddShiftCashMovement POST /shift/current/cash-movements — catat pay-in / pay-out. Body: { type, amount, reason }.
+func AddShiftCashMovement(c *gin.Context) {
+	var req dto.ShiftCashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ingredientValidationErrors(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	shift, err := shiftService.AddCashMovement(userID, req)
+	if err != nil {
+		settlementError(c, err, "Gagal mencatat kas masuk / keluar")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Kas masuk / keluar berhasil dicatat", shift)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var stockService services.StockService = services.NewStockService()
+
+// AdjustStock POST /menu/:id/stock — admin mencatat waste / restock / koreksi stok. Body: { type, quantity, note }.
+func AdjustStock(c *gin.Context) {
+	menuID := c.Param("id")
+
+	var input dto.StockAdjustmentDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errorsMap := make(map[string]string)
+
+		if ve, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range ve {
+				field := fe.Field()
+				switch fe.Tag() {
+				case "required":
+					errorsMap[field] = "Field wajib diisi"
+				case "oneof":
+					errorsMap[field] = "Pilihan tidak valid"
+				case "min":
+					errorsMap[field] = "Nilai minimal " + fe.Param()
+				case "max":
+					errorsMap[field] = "Maksimal " + fe.Param() + " karakter"
+				default:
+					errorsMap[field] = "Field tidak valid"
+				}
+			}
+			utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+			return
+		}
+
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	userIDVal, exists := c.Get("user_id")
+	if !exists || userIDVal == nil {
+		utils.ErrorResponseUnauthorized(c, "User tidak ditemukan")
+		return
+	}
+	userID, err := uuid.Parse(userIDVal.(string))
+	if err != nil {
+		utils.ErrorResponseUnauthorized(c, "User ID tidak valid")
+		return
+	}
+
+	movement, err := stockService.AdjustStock(menuID, input, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrStockNotTracked) || errors.Is(err, services.ErrInsufficientStock) || errors.Is(err, services.ErrInvalidStockAdjustment) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menyesuaikan stok")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Stok berhasil disesuaikan", movement)
+}
+
+// GetStockMovements GET /menu/:id/stock-movements — riwayat perubahan stok (admin)
+func GetStockMovements(c *gin.Context) {
+	var filter dto.StockMovementListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	page := utils.ParsePageQuery(c, services.StockMovementSortColumns, "created_at")
+
+	movements, total, err := stockService.GetStockMovements(c.Param("id"), filter, page)
+	if err != nil {
+		if errors.Is(err, services.ErrMenuNotFound) {
+			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrGetStockMovementFailed) {
+			utils.ErrorResponseInternal(c, "Gagal mengambil riwayat stok")
+			return
+		}
+		utils.ErrorResponseBadRequest(c, "Format tanggal tidak valid (YYYY-MM-DD)", nil)
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil riwayat stok", utils.NewPagedData(movements, page, total))
+}
+
+// GetLowStockMenus GET /menu/low-stock — menu dengan stok di bawah / sama dengan batas minimum (admin)
+func GetLowStockMenus(c *gin.Context) {
+	menus, err := stockService.GetLowStockMenus()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar menu stok menipis")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar menu stok menipis", menus)
+}
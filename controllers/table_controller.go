@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var tableService services.TableService = services.NewTableService()
+
+// tableValidationErrors menerjemahkan error validasi meja
+func tableValidationErrors(c *gin.Context, err error) {
+	errorsMap := make(map[string]string)
+
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		for _, fe := range ve {
+			field := fe.Field()
+			switch fe.Tag() {
+			case "required":
+				errorsMap[field] = "Field wajib diisi"
+			case "min":
+				errorsMap[field] = "Nilai minimal " + fe.Param()
+			case "max":
+				errorsMap[field] = "Maksimal " + fe.Param() + " karakter"
+			case "oneof":
+				errorsMap[field] = "Pilihan tidak valid"
+			default:
+				errorsMap[field] = "Field tidak valid"
+			}
+		}
+		utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+		return
+	}
+
+	utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+}
+
+func CreateTable(c *gin.Context) {
+	var input dto.CreateTableDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		tableValidationErrors(c, err)
+		return
+	}
+
+	table, err := tableService.CreateTable(input)
+	if err != nil {
+		if errors.Is(err, services.ErrTableNumberExists) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal membuat meja")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Meja berhasil dibuat", table)
+}
+
+func GetAllTables(c *gin.Context) {
+	tables, err := tableService.GetAllTables()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar meja")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar meja", tables)
+}
+
+func UpdateTable(c *gin.Context) {
+	var input dto.UpdateTableDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		tableValidationErrors(c, err)
+		return
+	}
+
+	table, err := tableService.UpdateTable(c.Param("id"), input)
+	if err != nil {
+		if errors.Is(err, services.ErrTableNotFound) {
+			utils.ErrorResponseNotFound(c, "Meja tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrTableNumberExists) || errors.Is(err, services.ErrTableOccupied) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengupdate meja")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Meja berhasil diupdate", table)
+}
+
+func DeleteTable(c *gin.Context) {
+	if err := tableService.DeleteTable(c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrTableNotFound) {
+			utils.ErrorResponseNotFound(c, "Meja tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrTableOccupied) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menghapus meja")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Meja berhasil dihapus", nil)
+}
+
+// SetTableStatus PATCH /table/:id/status — kasir menandai meja reserved / cleaning / free. Body: { status }.
+func SetTableStatus(c *gin.Context) {
+	var input dto.UpdateTableStatusDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		tableValidationErrors(c, err)
+		return
+	}
+
+	table, err := tableService.SetTableStatus(c.Param("id"), input.Status)
+	if err != nil {
+		if errors.Is(err, services.ErrTableNotFound) {
+			utils.ErrorResponseNotFound(c, "Meja tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrTableOccupied) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengubah status meja")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Status meja berhasil diubah", table)
+}
+
+// GetFloor GET /table/floor — status semua meja per area (kasir & admin)
+func GetFloor(c *gin.Context) {
+	floor, err := tableService.GetFloor()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil status meja")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil status meja", floor)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"errors"
+	"pos-go/dto"
+	"pos-go/services"
+	"pos-go/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+var taxService services.TaxService = services.NewTaxService()
+
+// taxValidationErrors menerjemahkan error validasi aturan pajak
+func taxValidationErrors(c *gin.Context, err error) {
+	errorsMap := make(map[string]string)
+
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		for _, fe := range ve {
+			field := fe.Field()
+			switch fe.Tag() {
+			case "required":
+				errorsMap[field] = "Field wajib diisi"
+			case "gt":
+				errorsMap[field] = "Nilai harus lebih dari " + fe.Param()
+			case "max":
+				errorsMap[field] = "Nilai maksimal " + fe.Param()
+			case "oneof":
+				errorsMap[field] = "Pilihan tidak valid"
+			case "uuid":
+				errorsMap[field] = "ID kategori tidak valid"
+			default:
+				errorsMap[field] = "Field tidak valid"
+			}
+		}
+		utils.ErrorResponseBadRequest(c, "Validasi gagal", errorsMap)
+		return
+	}
+
+	utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+}
+
+func CreateTaxRule(c *gin.Context) {
+	var input dto.CreateTaxRuleDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		taxValidationErrors(c, err)
+		return
+	}
+
+	rule, err := taxService.CreateTaxRule(input)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			utils.ErrorResponseBadRequest(c, "Kategori pengecualian tidak ditemukan", nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal membuat aturan pajak")
+		return
+	}
+
+	utils.SuccessResponseCreated(c, "Aturan pajak berhasil dibuat", rule)
+}
+
+func GetAllTaxRules(c *gin.Context) {
+	rules, err := taxService.GetAllTaxRules()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar aturan pajak")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar aturan pajak", rules)
+}
+
+func UpdateTaxRule(c *gin.Context) {
+	ruleID := c.Param("id")
+	var input dto.UpdateTaxRuleDTO
+	if err := c.ShouldBindJSON(&input); err != nil {
+		taxValidationErrors(c, err)
+		return
+	}
+
+	rule, err := taxService.UpdateTaxRule(ruleID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrTaxRuleNotFound) {
+			utils.ErrorResponseNotFound(c, "Aturan pajak tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			utils.ErrorResponseBadRequest(c, "Kategori pengecualian tidak ditemukan", nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengupdate aturan pajak")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Aturan pajak berhasil diupdate", rule)
+}
+
+func DeleteTaxRule(c *gin.Context) {
+	ruleID := c.Param("id")
+
+	if err := taxService.DeleteTaxRule(ruleID); err != nil {
+		if errors.Is(err, services.ErrTaxRuleNotFound) {
+			utils.ErrorResponseNotFound(c, "Aturan pajak tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal menghapus aturan pajak")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Aturan pajak berhasil dihapus", nil)
+}
+
+// GetActiveTaxRules - Public endpoint agar checkout bisa menampilkan estimasi pajak & service charge
+func GetActiveTaxRules(c *gin.Context) {
+	rules, err := taxService.GetActiveTaxRules()
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mengambil daftar aturan pajak aktif")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Berhasil mengambil daftar aturan pajak aktif", rules)
+}
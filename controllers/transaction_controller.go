@@ -2,10 +2,16 @@ package controllers
 
 import (
 	"errors"
-	"strings"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"pos-go/config"
 	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
 	"pos-go/services"
 	"pos-go/utils"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -50,14 +56,31 @@ func CreateTransaction(c *gin.Context) {
 			utils.ErrorResponseNotFound(c, "Menu tidak ditemukan atau tidak tersedia")
 			return
 		}
+		if errors.Is(err, services.ErrInsufficientStock) || errors.Is(err, services.ErrInvalidModifier) || errors.Is(err, services.ErrTableNotFound) ||
+			errors.Is(err, services.ErrOpenBillDineInOnly) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrTableNotAvailable) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
 		// Error dari Midtrans / token pembayaran: transaksi tidak disimpan ke DB
 		utils.ErrorResponseInternal(c, err.Error())
 		return
 	}
 
 	// Response dengan snap token untuk non-cash
+	response := transactionResponseWithSnap(transaction, snapToken, snapURL)
+
+	utils.SuccessResponseCreated(c, "Transaksi berhasil dibuat", response)
+}
+
+// transactionResponseWithSnap response transaksi beserta snap token (kosong untuk tunai / open bill yang belum ditutup)
+func transactionResponseWithSnap(transaction *transaction_model.Transaction, snapToken, snapURL string) dto.TransactionResponseWithSnap {
 	response := dto.TransactionResponseWithSnap{
 		ID:            transaction.ID,
+		OrderNumber:   transaction.OrderNumber,
 		CustomerName:  transaction.CustomerName,
 		CustomerPhone: transaction.CustomerPhone,
 		OrderType:     transaction.OrderType,
@@ -65,12 +88,15 @@ func CreateTransaction(c *gin.Context) {
 		PromoCode:     transaction.PromoCode,
 		Discount:      transaction.Discount,
 		Subtotal:      transaction.Subtotal,
+		ServiceCharge: transaction.ServiceCharge,
 		Tax:           transaction.Tax,
 		TotalAmount:   transaction.TotalAmount,
+		Taxes:         services.TransactionTaxResponses(transaction.Taxes),
 		PaymentMethod: transaction.PaymentMethod,
 		PaymentStatus: transaction.PaymentStatus,
 		OrderStatus:   transaction.OrderStatus,
 		Notes:         transaction.Notes,
+		IsOpenBill:    transaction.IsOpenBill,
 		SnapToken:     snapToken,
 		SnapURL:       snapURL,
 		CreatedAt:     transaction.CreatedAt.Format(time.RFC3339),
@@ -87,17 +113,22 @@ func CreateTransaction(c *gin.Context) {
 	var items []dto.TransactionItemResponse
 	for _, item := range transaction.Items {
 		items = append(items, dto.TransactionItemResponse{
-			ID:        item.ID,
-			MenuID:    item.MenuID,
-			MenuName:  item.MenuName,
-			MenuPrice: item.MenuPrice,
-			Quantity:  item.Quantity,
-			Subtotal:  item.Subtotal,
+			ID:            item.ID,
+			MenuID:        item.MenuID,
+			MenuName:      item.MenuName,
+			MenuPrice:     item.MenuPrice,
+			ModifierPrice: item.ModifierPrice,
+			Modifiers:     services.ItemModifierResponses(item),
+			Quantity:      item.Quantity,
+			Subtotal:      item.Subtotal,
+			Notes:         item.Notes,
+			Station:       item.Station,
+			Status:        item.Status,
+			Batch:         item.Batch,
 		})
 	}
 	response.Items = items
-
-	utils.SuccessResponseCreated(c, "Transaksi berhasil dibuat", response)
+	return response
 }
 
 // HandleMidtransNotification untuk webhook dari Midtrans
@@ -108,42 +139,65 @@ func HandleMidtransNotification(c *gin.Context) {
 		return
 	}
 
-	// Verify signature dari Midtrans (penting untuk security!)
-	// TODO: Implementasi signature verification
-
-	// Parse transaction ID
-	transactionID, err := uuid.Parse(notification.OrderID)
+	// Verify signature dari Midtrans + cocokkan gross_amount dengan total transaksi
+	order, err := transactionService.VerifyMidtransNotification(notification)
 	if err != nil {
-		utils.ErrorResponseBadRequest(c, "Invalid transaction ID", nil)
+		log.Printf("Notifikasi Midtrans ditolak (order_id=%s, status=%s, ip=%s): %v",
+			notification.OrderID, notification.TransactionStatus, c.ClientIP(), err)
+		if errors.Is(err, services.ErrInvalidSignature) {
+			utils.ErrorResponseUnauthorized(c, "Invalid signature")
+			return
+		}
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			utils.ErrorResponseNotFound(c, "Transaction not found")
+			return
+		}
+		if errors.Is(err, services.ErrGrossAmountMismatch) {
+			utils.ErrorResponseBadRequest(c, "Gross amount mismatch", nil)
+			return
+		}
+		utils.ErrorResponseInternal(c, "Failed to verify notification")
 		return
 	}
 
-	// Update status berdasarkan response Midtrans
+	// Payment split bill: hanya status payment yang berubah; transaksi paid jika semua tender menutup total
+	if order.PaymentID != nil {
+		handlePaymentNotification(c, notification, *order.PaymentID)
+		return
+	}
+
+	// Update status berdasarkan response Midtrans. String kosong = status tersebut tidak diubah,
+	// sehingga notifikasi yang dikirim ulang tidak menarik pesanan yang sudah diproses dapur kembali ke pending.
 	var paymentStatus, orderStatus string
 
 	switch notification.TransactionStatus {
 	case "capture", "settlement":
-		paymentStatus = "paid"
-		// Tetap pending (antrian), sampai ada flow dapur/selesai
-		orderStatus = "pending"
+		// order_status tetap (antrian), sampai ada flow dapur/selesai
+		paymentStatus = services.PaymentStatusPaid
 	case "pending":
-		paymentStatus = "pending"
-		orderStatus = "pending"
+		paymentStatus = services.PaymentStatusPending
 	case "expire":
 		// Transaction expired (lewat 24 jam)
-		paymentStatus = "expired"
-		orderStatus = "cancelled"
+		paymentStatus = services.PaymentStatusExpired
+		orderStatus = services.OrderStatusCancelled
 	case "deny", "cancel":
 		// Transaction cancelled by user or system
-		paymentStatus = "cancelled"
-		orderStatus = "cancelled"
+		paymentStatus = services.PaymentStatusCancelled
+		orderStatus = services.OrderStatusCancelled
 	default:
-		paymentStatus = "pending"
-		orderStatus = "pending"
+		log.Printf("Notifikasi Midtrans diabaikan (order_id=%s): status %q tidak dikenal", notification.OrderID, notification.TransactionStatus)
+		c.JSON(200, gin.H{"status": "ignored"})
+		return
 	}
 
-	_, err = transactionService.UpdateTransactionStatus(transactionID, paymentStatus, orderStatus)
+	_, err = transactionService.UpdateTransactionStatus(order.TransactionID, paymentStatus, orderStatus)
 	if err != nil {
+		// Transisi tidak sah (mis. pending setelah paid): jawab 200 agar Midtrans tidak mengirim ulang
+		if errors.Is(err, services.ErrInvalidTransition) {
+			log.Printf("Notifikasi Midtrans diabaikan (order_id=%s, status=%s): %v", notification.OrderID, notification.TransactionStatus, err)
+			c.JSON(200, gin.H{"status": "ignored"})
+			return
+		}
 		utils.ErrorResponseInternal(c, "Failed to update transaction")
 		return
 	}
@@ -160,6 +214,12 @@ func ConfirmCashPaid(c *gin.Context) {
 		return
 	}
 
+	var req dto.ConfirmCashPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponseBadRequest(c, "amount_tendered wajib diisi dan lebih dari 0", nil)
+		return
+	}
+
 	var closedByUserID *uuid.UUID
 	if uid, exists := c.Get("user_id"); exists && uid != nil {
 		if parsed, err := uuid.Parse(uid.(string)); err == nil {
@@ -167,7 +227,7 @@ func ConfirmCashPaid(c *gin.Context) {
 		}
 	}
 
-	tx, err := transactionService.ConfirmCashPaid(id, closedByUserID)
+	tx, err := transactionService.ConfirmCashPaid(id, closedByUserID, req.AmountTendered)
 	if err != nil {
 		utils.ErrorResponseBadRequest(c, err.Error(), nil)
 		return
@@ -176,14 +236,39 @@ func ConfirmCashPaid(c *gin.Context) {
 	utils.SuccessResponseOK(c, "Pembayaran tunai berhasil dikonfirmasi", tx)
 }
 
+// GetAllTransactions - list transaksi dengan paginasi (?page=&limit=&sort_by=&sort_dir=) dan filter (lihat dto.TransactionListFilter).
+// Dengan format=csv|xlsx seluruh hasil filter diunduh (tanpa paginasi) beserta rincian item (CSV: sheet=transaksi|item).
 func GetAllTransactions(c *gin.Context) {
-	transactions, err := transactionService.GetAllTransactions()
+	var filter dto.TransactionListFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ErrorResponseBadRequest(c, "Parameter filter tidak valid", nil)
+		return
+	}
+	if format := c.Query("format"); format != "" {
+		writeExport(c, format, "transaksi", func(w utils.TableWriter) error {
+			return transactionService.ExportTransactions(filter, w)
+		}, func(err error) {
+			if errors.Is(err, services.ErrDatabaseError) {
+				utils.ErrorResponseInternal(c, "Gagal mengekspor data transaksi")
+				return
+			}
+			utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
+		})
+		return
+	}
+	page := utils.ParsePageQuery(c, services.TransactionSortColumns, "created_at")
+
+	transactions, total, err := transactionService.GetAllTransactions(filter, page)
 	if err != nil {
-		utils.ErrorResponseInternal(c, "Gagal mengambil data transaksi")
+		if errors.Is(err, services.ErrDatabaseError) {
+			utils.ErrorResponseInternal(c, "Gagal mengambil data transaksi")
+			return
+		}
+		utils.ErrorResponseBadRequest(c, "Tanggal tidak valid. Gunakan format YYYY-MM-DD", nil)
 		return
 	}
 
-	utils.SuccessResponseOK(c, "Berhasil mengambil data transaksi", transactions)
+	utils.SuccessResponseOK(c, "Berhasil mengambil data transaksi", utils.NewPagedData(transactions, page, total))
 }
 
 func GetTransactionByID(c *gin.Context) {
@@ -244,10 +329,14 @@ func UpdateOrderStatus(c *gin.Context) {
 			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
 			return
 		}
-		if errors.Is(err, services.ErrInvalidStatus) || strings.Contains(err.Error(), "belum berstatus paid") {
+		if errors.Is(err, services.ErrInvalidStatus) || errors.Is(err, services.ErrInvalidTransition) || errors.Is(err, services.ErrOrderNotPaid) {
 			utils.ErrorResponseBadRequest(c, err.Error(), nil)
 			return
 		}
+		if errors.Is(err, services.ErrStatusConflict) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
 		utils.ErrorResponseInternal(c, "Gagal mengubah status pesanan")
 		return
 	}
@@ -255,6 +344,137 @@ func UpdateOrderStatus(c *gin.Context) {
 	utils.SuccessResponseOK(c, "Status pesanan berhasil diubah", tx)
 }
 
+// UpdateItemStatus - koki menandai satu item (per stasiun) mulai dimasak / siap. order_status mengikuti status semua item.
+func UpdateItemStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID item tidak valid", nil)
+		return
+	}
+
+	var req dto.UpdateItemStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	tx, err := transactionService.UpdateItemStatus(id, itemID, req.Status)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrItemNotFound) {
+			utils.ErrorResponseNotFound(c, "Item pesanan tidak ditemukan")
+			return
+		}
+		if errors.Is(err, services.ErrInvalidTransition) {
+			utils.ErrorResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrStatusConflict) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengubah status item")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Status item berhasil diubah", tx)
+}
+
+// openBillError memetakan error operasi open bill ke response
+func openBillError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+	case errors.Is(err, services.ErrItemNotFound):
+		utils.ErrorResponseNotFound(c, "Item pesanan tidak ditemukan")
+	case errors.Is(err, services.ErrMenuNotFound):
+		utils.ErrorResponseNotFound(c, "Menu tidak ditemukan atau tidak tersedia")
+	case errors.Is(err, services.ErrInsufficientStock), errors.Is(err, services.ErrInvalidModifier),
+		errors.Is(err, services.ErrItemAlreadyStarted), errors.Is(err, services.ErrLastOpenBillItem),
+		errors.Is(err, services.ErrInvalidTransition):
+		utils.ErrorResponseBadRequest(c, err.Error(), nil)
+	case errors.Is(err, services.ErrNotOpenBill), errors.Is(err, services.ErrStatusConflict):
+		utils.ErrorResponseConflict(c, err.Error())
+	default:
+		utils.ErrorResponseInternal(c, fallback)
+	}
+}
+
+// AddOpenBillItems - kasir menambah ronde item ke open bill (dikirim ke dapur sebagai batch baru)
+func AddOpenBillItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	var req dto.AddTransactionItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponseBadRequest(c, "Format data tidak valid", nil)
+		return
+	}
+
+	transaction, err := transactionService.AddOpenBillItems(id, req)
+	if err != nil {
+		openBillError(c, err, "Gagal menambah item pesanan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Item berhasil ditambahkan", transactionResponseWithSnap(transaction, "", ""))
+}
+
+// RemoveOpenBillItem - kasir menghapus item open bill yang belum dikerjakan dapur
+func RemoveOpenBillItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID item tidak valid", nil)
+		return
+	}
+
+	transaction, err := transactionService.RemoveOpenBillItem(id, itemID)
+	if err != nil {
+		openBillError(c, err, "Gagal menghapus item pesanan")
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Item berhasil dihapus", transactionResponseWithSnap(transaction, "", ""))
+}
+
+// CloseBill - kasir menutup open bill; non-cash mendapat snap token untuk total akhir
+func CloseBill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+
+	transaction, snapToken, snapURL, err := transactionService.CloseBill(id)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) || errors.Is(err, services.ErrNotOpenBill) {
+			openBillError(c, err, "")
+			return
+		}
+		// Error dari Midtrans / token pembayaran: bill tetap terbuka
+		utils.ErrorResponseInternal(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponseOK(c, "Bill berhasil ditutup", transactionResponseWithSnap(transaction, snapToken, snapURL))
+}
+
 // CancelOrder - kasir membatalkan pesanan (pending / cooking / ready -> cancelled)
 func CancelOrder(c *gin.Context) {
 	idParam := c.Param("id")
@@ -277,10 +497,14 @@ func CancelOrder(c *gin.Context) {
 			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
 			return
 		}
-		if errors.Is(err, services.ErrInvalidStatus) {
+		if errors.Is(err, services.ErrInvalidStatus) || errors.Is(err, services.ErrInvalidTransition) {
 			utils.ErrorResponseBadRequest(c, "Pesanan tidak dapat dibatalkan (status tidak sesuai atau sudah selesai)", nil)
 			return
 		}
+		if errors.Is(err, services.ErrStatusConflict) {
+			utils.ErrorResponseConflict(c, err.Error())
+			return
+		}
 		utils.ErrorResponseInternal(c, "Gagal membatalkan pesanan")
 		return
 	}
@@ -289,6 +513,8 @@ func CancelOrder(c *gin.Context) {
 }
 
 // GetTransactionReceipt returns receipt data for print (kasir/admin).
+// format=escpos mengunduh struk sebagai byte stream ESC/POS (paper=58|80, default dari env PRINTER_PAPER_WIDTH).
+// QR memakai link struk digital yang masih aktif; endpoint ini tidak membuat token baru.
 func GetTransactionReceipt(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -305,5 +531,206 @@ func GetTransactionReceipt(c *gin.Context) {
 		utils.ErrorResponseInternal(c, "Gagal mengambil data struk")
 		return
 	}
+	if c.Query("format") == "escpos" {
+		paper, ok := paperWidthQuery(c)
+		if !ok {
+			return
+		}
+		qr, ok := receiptQR(c, receipt.ID, false)
+		if !ok {
+			return
+		}
+		sendPrintData(c, services.RenderReceiptESCPOS(receipt, paper, qr), "struk-"+receipt.ID.String()[:8])
+		return
+	}
 	utils.SuccessResponseOK(c, "Data struk berhasil diambil", receipt)
 }
+
+// PrintTransactionReceipt POST /transaction/:id/receipt/print?paper=58|80 — cetak struk ke printer kasir (ESC/POS, raw TCP)
+func PrintTransactionReceipt(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	paper, ok := paperWidthQuery(c)
+	if !ok {
+		return
+	}
+	receipt, err := transactionService.GetTransactionReceipt(id)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal mengambil data struk")
+		return
+	}
+	qr, ok := receiptQR(c, receipt.ID, true)
+	if !ok {
+		return
+	}
+	if err := services.SendToPrinter(config.Printer.ReceiptPrinter, services.RenderReceiptESCPOS(receipt, paper, qr)); err != nil {
+		printerError(c, err)
+		return
+	}
+	utils.SuccessResponseOK(c, "Struk dikirim ke printer", nil)
+}
+
+// receiptQR isi QR struk cetak (link struk digital; create = token dibuat jika belum ada). Response error sudah ditulis
+// jika ok = false
+func receiptQR(c *gin.Context, transactionID uuid.UUID, create bool) (string, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return "", false
+	}
+	qr, err := transactionService.ReceiptQRData(transactionID, &userID, create)
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal membuat link struk digital")
+		return "", false
+	}
+	return qr, true
+}
+
+// CreateReceiptToken POST /transaction/:id/receipt-token — buat link struk digital (token publik) untuk dibagikan ke pelanggan
+func CreateReceiptToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	var req dto.CreateReceiptTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponseBadRequest(c, "expires_in_hours harus 1-8760", nil)
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var expiresIn *time.Duration
+	if req.ExpiresInHours != nil {
+		d := time.Duration(*req.ExpiresInHours) * time.Hour
+		expiresIn = &d
+	}
+
+	token, err := transactionService.CreateReceiptToken(id, &userID, expiresIn)
+	if err != nil {
+		if errors.Is(err, services.ErrTransactionNotFound) {
+			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+			return
+		}
+		utils.ErrorResponseInternal(c, "Gagal membuat link struk digital")
+		return
+	}
+	utils.SuccessResponseCreated(c, "Link struk digital berhasil dibuat", token)
+}
+
+// RevokeReceiptTokens DELETE /transaction/:id/receipt-token — cabut semua link struk digital transaksi
+func RevokeReceiptTokens(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return
+	}
+	revoked, err := transactionService.RevokeReceiptTokens(id)
+	if err != nil {
+		utils.ErrorResponseInternal(c, "Gagal mencabut link struk digital")
+		return
+	}
+	utils.SuccessResponseOK(c, "Link struk digital dicabut", gin.H{"revoked": revoked})
+}
+
+// GetKitchenTicket GET /transaction/:id/kitchen-ticket?station=&batch=&paper= — unduh tiket dapur (ESC/POS).
+// station kosong = semua stasiun, batch 0 / kosong = semua ronde.
+func GetKitchenTicket(c *gin.Context) {
+	tx, station, paper, ok := kitchenTicketRequest(c)
+	if !ok {
+		return
+	}
+	sendPrintData(c, services.RenderKitchenTicketESCPOS(tx, station, paper, time.Now()), "dapur-"+tx.ID.String()[:8])
+}
+
+// PrintKitchenTicket POST /transaction/:id/kitchen-ticket/print?station=&batch=&paper= — cetak tiket dapur ke printer dapur
+func PrintKitchenTicket(c *gin.Context) {
+	tx, station, paper, ok := kitchenTicketRequest(c)
+	if !ok {
+		return
+	}
+	if err := services.SendToPrinter(config.Printer.KitchenPrinter, services.RenderKitchenTicketESCPOS(tx, station, paper, time.Now())); err != nil {
+		printerError(c, err)
+		return
+	}
+	utils.SuccessResponseOK(c, "Tiket dapur dikirim ke printer", nil)
+}
+
+// kitchenTicketRequest membaca parameter tiket dapur dan mengambil transaksinya; response error sudah ditulis jika ok = false
+func kitchenTicketRequest(c *gin.Context) (*transaction_model.Transaction, string, int, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponseBadRequest(c, "ID transaksi tidak valid", nil)
+		return nil, "", 0, false
+	}
+	paper, ok := paperWidthQuery(c)
+	if !ok {
+		return nil, "", 0, false
+	}
+	batch := 0
+	if v := c.Query("batch"); v != "" {
+		if batch, err = strconv.Atoi(v); err != nil || batch < 0 {
+			utils.ErrorResponseBadRequest(c, "Parameter batch tidak valid", nil)
+			return nil, "", 0, false
+		}
+	}
+	station := ""
+	if v := c.Query("station"); v != "" {
+		station = services.NormalizeStation(v)
+	}
+
+	tx, err := transactionService.GetKitchenTicket(id, station, batch)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTransactionNotFound):
+			utils.ErrorResponseNotFound(c, "Transaksi tidak ditemukan")
+		case errors.Is(err, services.ErrNoKitchenItems):
+			utils.ErrorResponseNotFound(c, err.Error())
+		default:
+			utils.ErrorResponseInternal(c, "Gagal mengambil data tiket dapur")
+		}
+		return nil, "", 0, false
+	}
+	return tx, station, paper, true
+}
+
+// paperWidthQuery query paper (58 / 80 mm, opsional)
+func paperWidthQuery(c *gin.Context) (int, bool) {
+	v := c.Query("paper")
+	if v == "" {
+		return 0, true
+	}
+	paper, err := strconv.Atoi(v)
+	if err != nil || !utils.PaperSupported(paper) {
+		utils.ErrorResponseBadRequest(c, "Parameter paper harus 58 atau 80", nil)
+		return 0, false
+	}
+	return paper, true
+}
+
+// sendPrintData mengirim byte stream ESC/POS sebagai file unduhan (bisa diteruskan apa adanya ke printer)
+func sendPrintData(c *gin.Context, data []byte, filename string) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.bin"`, filename))
+	c.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+// printerError memetakan error kirim ke printer
+func printerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPrinterNotConfigured):
+		utils.ErrorResponseConflict(c, err.Error())
+	case errors.Is(err, services.ErrPrinterUnreachable):
+		log.Printf("Gagal mencetak: %v", err)
+		utils.ErrorResponse(c, http.StatusBadGateway, services.ErrPrinterUnreachable.Error(), nil)
+	default:
+		utils.ErrorResponseInternal(c, "Gagal mencetak")
+	}
+}
@@ -3,6 +3,9 @@ package database
 import (
 	"log"
 	"pos-go/config"
+	settlement_model "pos-go/models/settlement_model"
+	table_model "pos-go/models/table_model"
+	tax_model "pos-go/models/tax_model"
 	user_model "pos-go/models/user_model"
 
 	"golang.org/x/crypto/bcrypt"
@@ -38,3 +41,119 @@ func SeedAdmin() {
 
 	log.Println("Admin berhasil dibuat: adminresto@gmail.com / admin123")
 }
+
+// SeedTaxRules membuat aturan PPN 10% eksklusif jika belum ada aturan pajak sama sekali
+// (sama dengan perilaku lama yang hard-coded). Admin bisa mengubah / menonaktifkannya lewat /tax.
+func SeedTaxRules() {
+	var count int64
+	if err := config.DB.Model(&tax_model.TaxRule{}).Count(&count).Error; err != nil {
+		log.Fatal("Gagal cek aturan pajak:", err)
+	}
+	if count > 0 {
+		log.Println("Aturan pajak sudah ada, skip seeding")
+		return
+	}
+
+	rule := tax_model.TaxRule{
+		Name:        "PPN",
+		Type:        "tax",
+		Rate:        10,
+		IsInclusive: false,
+		IsActive:    true,
+	}
+	if err := config.DB.Create(&rule).Error; err != nil {
+		log.Fatal("Gagal create aturan pajak:", err)
+	}
+
+	log.Println("Aturan pajak default dibuat: PPN 10% (eksklusif)")
+}
+
+// BackfillPayments membuat satu payment paid untuk transaksi dibayar yang belum punya payment (transaksi sebelum
+// split bill / multi-tender), agar settlement dan laporan tunai cukup menjumlahkan tabel payments.
+func BackfillPayments() {
+	res := config.DB.Exec(`
+		INSERT INTO payments (id, transaction_id, method, amount, amount_tendered, status, external_ref, cashier_user_id, refunded_amount, paid_at, created_at, updated_at)
+		SELECT gen_random_uuid(), t.id, t.payment_method, t.total_amount,
+			CASE WHEN t.payment_method = 'cash' THEN t.total_amount ELSE 0 END, 'paid',
+			CASE WHEN t.payment_method = 'cash' THEN '' ELSE COALESCE(NULLIF(t.midtrans_order_id, ''), t.id::text) END,
+			CASE WHEN t.payment_method = 'cash' THEN t.closed_by_user_id END,
+			t.refunded_amount, t.updated_at, t.created_at, NOW()
+		FROM transactions t
+		WHERE t.deleted_at IS NULL
+			AND t.payment_status IN ('paid', 'partially_refunded', 'refunded')
+			AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id)`)
+	if res.Error != nil {
+		log.Fatal("Gagal backfill payment:", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("Backfill payment: %d transaksi lama", res.RowsAffected)
+	}
+
+	// Tender tunai sebelum amount_tendered dicatat dianggap uang pas (tanpa kembalian)
+	if err := config.DB.Exec(`UPDATE payments SET amount_tendered = amount
+		WHERE method = 'cash' AND status = 'paid' AND amount_tendered = 0`).Error; err != nil {
+		log.Fatal("Gagal backfill uang diterima:", err)
+	}
+}
+
+// DropDateSettlementIndex menghapus unique index lama (date, user_id): settlement sekarang satu per shift,
+// sehingga kasir bisa punya beberapa shift (dan settlement) di tanggal yang sama.
+func DropDateSettlementIndex() {
+	migrator := config.DB.Migrator()
+	if !migrator.HasIndex(&settlement_model.Settlement{}, "idx_settlement_date_user") {
+		return
+	}
+	if err := migrator.DropIndex(&settlement_model.Settlement{}, "idx_settlement_date_user"); err != nil {
+		log.Fatal("Gagal hapus index settlement lama:", err)
+	}
+	log.Println("Index settlement per tanggal dihapus (settlement per shift)")
+}
+
+// BackfillSettlementRevisions membuat revisi pertama untuk settlement yang dibuat sebelum ada alur review,
+// agar riwayat setiap settlement selalu dimulai dari revision 1.
+func BackfillSettlementRevisions() {
+	res := config.DB.Exec(`
+		INSERT INTO settlement_revisions (id, settlement_id, revision, expected_cash, actual_cash, discrepancy, denominations, note, submitted_by_user_id, status, created_at)
+		SELECT gen_random_uuid(), s.id, s.revision, s.expected_cash, s.actual_cash, s.discrepancy, '[]'::jsonb, s.note, s.user_id, s.status, s.created_at
+		FROM settlements s
+		WHERE s.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM settlement_revisions r WHERE r.settlement_id = s.id)`)
+	if res.Error != nil {
+		log.Fatal("Gagal backfill revisi settlement:", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("Backfill revisi settlement: %d settlement lama", res.RowsAffected)
+	}
+}
+
+// BackfillTables membuat data meja dari nomor meja yang pernah dipakai transaksi (sebelum ada manajemen meja),
+// agar pesanan dine-in ke meja lama tidak ditolak karena meja tidak ditemukan. Meja yang masih punya pesanan
+// aktif langsung ditandai occupied oleh pesanan terbarunya.
+// Hanya berjalan sekali, saat belum ada meja sama sekali; setelah itu meja dikelola lewat /tables
+// sehingga meja yang dihapus admin tidak dibuat ulang saat restart.
+func BackfillTables() {
+	var count int64
+	if err := config.DB.Model(&table_model.Table{}).Count(&count).Error; err != nil {
+		log.Fatal("Gagal cek meja:", err)
+	}
+	if count > 0 {
+		return
+	}
+
+	res := config.DB.Exec(`
+		INSERT INTO tables (id, number, area, capacity, status, current_transaction_id, occupied_at, created_at, updated_at)
+		SELECT gen_random_uuid(), n.table_number, '', 0,
+			CASE WHEN a.id IS NULL THEN 'free' ELSE 'occupied' END, a.id, a.created_at, NOW(), NOW()
+		FROM (SELECT DISTINCT table_number FROM transactions WHERE deleted_at IS NULL AND table_number > 0) n
+		LEFT JOIN LATERAL (
+			SELECT t.id, t.created_at FROM transactions t
+			WHERE t.deleted_at IS NULL AND t.table_number = n.table_number AND t.order_status NOT IN ('completed', 'cancelled')
+			ORDER BY t.created_at DESC LIMIT 1
+		) a ON true`)
+	if res.Error != nil {
+		log.Fatal("Gagal backfill meja:", res.Error)
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("Backfill meja: %d nomor meja dari transaksi lama", res.RowsAffected)
+	}
+}
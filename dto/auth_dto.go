@@ -17,3 +17,9 @@ type ChangePasswordDTO struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
+
+// UserListFilter filter query GET /user (admin)
+type UserListFilter struct {
+	Search string `form:"search"` // nama / email
+	Role   string `form:"role" binding:"omitempty,oneof=kasir koki"`
+}
@@ -1,9 +1,11 @@
 package dto
 
 type CreateCategoryDTO struct {
-	Name string `json:"name" binding:"required"`
+	Name    string `json:"name" binding:"required"`
+	Station string `json:"station" binding:"omitempty,max=50"` // stasiun dapur default (mis. grill, drinks, dessert)
 }
 
 type UpdateCategoryDTO struct {
-	Name string `json:"name" binding:"omitempty"`
+	Name    string  `json:"name" binding:"omitempty"`
+	Station *string `json:"station" binding:"omitempty,max=50"` // nil = tidak diubah, "" = kembali ke stasiun default
 }
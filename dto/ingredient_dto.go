@@ -0,0 +1,71 @@
+package dto
+
+import "pos-go/utils"
+
+// CreateIngredientDTO untuk create bahan baku
+type CreateIngredientDTO struct {
+	Name              string  `json:"name" binding:"required,max=255"`
+	Unit              string  `json:"unit" binding:"required,max=20"` // mis. gram, ml, pcs
+	LowStockThreshold float64 `json:"low_stock_threshold" binding:"min=0"`
+}
+
+// UpdateIngredientDTO untuk update bahan baku (partial update). Stok hanya berubah lewat pembelian / penyesuaian.
+type UpdateIngredientDTO struct {
+	Name              string   `json:"name" binding:"omitempty,max=255"`
+	Unit              string   `json:"unit" binding:"omitempty,max=20"`
+	LowStockThreshold *float64 `json:"low_stock_threshold" binding:"omitempty,min=0"`
+}
+
+// IngredientListFilter filter query GET /ingredient
+type IngredientListFilter struct {
+	Search string `form:"search"`
+}
+
+// IngredientAdjustmentDTO body POST /ingredient/:id/adjust.
+// waste: quantity = jumlah yang terbuang (> 0). correction: quantity = hasil hitung fisik (stok menjadi nilai ini).
+type IngredientAdjustmentDTO struct {
+	Type     string  `json:"type" binding:"required,oneof=waste correction"`
+	Quantity float64 `json:"quantity" binding:"min=0"`
+	Note     string  `json:"note" binding:"max=500"`
+}
+
+// IngredientMovementListFilter filter query GET /ingredient/:id/movements
+type IngredientMovementListFilter struct {
+	Type     string `form:"type" binding:"omitempty,oneof=usage purchase waste correction"`
+	DateFrom string `form:"date_from"` // YYYY-MM-DD
+	DateTo   string `form:"date_to"`   // YYYY-MM-DD (inklusif)
+}
+
+// RecipeLineInput satu bahan dalam resep menu
+type RecipeLineInput struct {
+	IngredientID string  `json:"ingredient_id" binding:"required,uuid"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"` // per porsi
+}
+
+// SetRecipeDTO body PUT /menu/:id/recipe (mengganti seluruh resep; lines kosong = hapus resep)
+type SetRecipeDTO struct {
+	Lines []RecipeLineInput `json:"lines" binding:"dive"`
+}
+
+// CreatePurchaseItemDTO satu bahan yang diterima
+type CreatePurchaseItemDTO struct {
+	IngredientID string      `json:"ingredient_id" binding:"required,uuid"`
+	Quantity     float64     `json:"quantity" binding:"required,gt=0"`
+	UnitCost     utils.Money `json:"unit_cost" binding:"min=0"`
+}
+
+// CreatePurchaseDTO body POST /purchase (penerimaan barang)
+type CreatePurchaseDTO struct {
+	Supplier      string                  `json:"supplier" binding:"required,max=255"`
+	InvoiceNumber string                  `json:"invoice_number" binding:"max=100"`
+	ReceivedAt    string                  `json:"received_at"` // RFC3339, default sekarang
+	Notes         string                  `json:"notes"`
+	Items         []CreatePurchaseItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+// PurchaseListFilter filter query GET /purchase
+type PurchaseListFilter struct {
+	Supplier string `form:"supplier"`
+	DateFrom string `form:"date_from"` // YYYY-MM-DD
+	DateTo   string `form:"date_to"`   // YYYY-MM-DD (inklusif)
+}
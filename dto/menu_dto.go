@@ -1,19 +1,56 @@
 package dto
 
+import "pos-go/utils"
+
 type CreateMenuDTO struct {
-	Name        string  `form:"name" binding:"required"`
-	Description string  `form:"description"`
-	Price       float64 `form:"price" binding:"required,min=0"`
-	Image       string  // Tidak di-bind, di-set manual
-	IsAvailable bool    // Tidak di-bind, di-set manual
-	CategoryID  string  `form:"category_id" binding:"required,uuid"`
+	Name              string      `form:"name" binding:"required"`
+	Description       string      `form:"description"`
+	Price             utils.Money `form:"price" binding:"required,min=0"`
+	Image             string      // Tidak di-bind, di-set manual
+	IsAvailable       bool        // Tidak di-bind, di-set manual
+	CategoryID        string      `form:"category_id" binding:"required,uuid"`
+	TrackStock        bool        // Tidak di-bind, di-set manual (seperti is_available)
+	Stock             int         `form:"stock" binding:"omitempty,min=0"` // stok awal (jika track_stock)
+	LowStockThreshold int         `form:"low_stock_threshold" binding:"omitempty,min=0"`
+	Station           string      `form:"station" binding:"omitempty,max=50"` // kosong = ikut stasiun kategori
 }
 
 type UpdateMenuDTO struct {
-	Name        string  `form:"name" binding:"omitempty"`
-	Description string  `form:"description"`
-	Price       float64 `form:"price" binding:"omitempty,min=0"`
-	Image       string  // Tidak di-bind, di-set manual (opsional saat update)
-	IsAvailable bool    // Tidak di-bind, di-set manual
-	CategoryID  string  `form:"category_id" binding:"omitempty,uuid"`
+	Name              string      `form:"name" binding:"omitempty"`
+	Description       string      `form:"description"`
+	Price             utils.Money `form:"price" binding:"omitempty,min=0"`
+	Image             string      // Tidak di-bind, di-set manual (opsional saat update)
+	IsAvailable       bool        // Tidak di-bind, di-set manual
+	CategoryID        string      `form:"category_id" binding:"omitempty,uuid"`
+	TrackStock        *bool       // Tidak di-bind, di-set manual; nil = tidak diubah
+	LowStockThreshold *int        `form:"low_stock_threshold" binding:"omitempty,min=0"`
+	Station           *string     `form:"station" binding:"omitempty,max=50"` // nil = tidak diubah, "" = ikut stasiun kategori
+}
+
+// MenuListFilter filter query GET /menu (admin)
+type MenuListFilter struct {
+	Search      string `form:"search"`
+	CategoryID  string `form:"category_id" binding:"omitempty,uuid"`
+	IsAvailable *bool  `form:"is_available"`
+}
+
+// ModifierOptionInput satu pilihan dalam grup modifier
+type ModifierOptionInput struct {
+	Name        string      `json:"name" binding:"required,max=100"`
+	PriceDelta  utils.Money `json:"price_delta"`  // tambahan harga per porsi (boleh 0 / negatif)
+	IsAvailable *bool       `json:"is_available"` // default true
+}
+
+// ModifierGroupInput satu grup modifier menu (mis. Ukuran, Level Pedas, Topping)
+type ModifierGroupInput struct {
+	Name       string                `json:"name" binding:"required,max=100"`
+	IsRequired bool                  `json:"is_required"`
+	MinSelect  int                   `json:"min_select" binding:"min=0"`
+	MaxSelect  int                   `json:"max_select" binding:"required,min=1"`
+	Options    []ModifierOptionInput `json:"options" binding:"required,min=1,dive"`
+}
+
+// SetModifierGroupsDTO body PUT /menu/:id/modifiers (mengganti seluruh grup; groups kosong = hapus semua modifier)
+type SetModifierGroupsDTO struct {
+	Groups []ModifierGroupInput `json:"groups" binding:"dive"`
 }
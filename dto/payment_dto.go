@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+)
+
+// CreatePaymentRequest body POST /transaction/:id/payments (satu tender dari split bill / multi-tender)
+type CreatePaymentRequest struct {
+	Method string      `json:"method" binding:"required,oneof=cash credit_card debit_card e_wallet"`
+	Amount utils.Money `json:"amount" binding:"required,gt=0"`
+	Label  string      `json:"label" binding:"max=100"` // mis. "Tamu 1"
+	// AmountTendered uang yang diserahkan pelanggan (hanya tunai, opsional). Kosong = uang pas.
+	AmountTendered utils.Money `json:"amount_tendered" binding:"omitempty,gt=0"`
+}
+
+// ConfirmCashPaidRequest body PATCH /transaction/:id/cash-paid
+type ConfirmCashPaidRequest struct {
+	AmountTendered utils.Money `json:"amount_tendered" binding:"required,gt=0"` // uang yang diserahkan pelanggan, minimal sisa tagihan
+}
+
+// PaymentResponse satu pembayaran transaksi. SnapToken / SnapURL hanya diisi saat payment non-cash baru dibuat.
+type PaymentResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	Method         string      `json:"method"`
+	Amount         utils.Money `json:"amount"`
+	Status         string      `json:"status"`
+	Label          string      `json:"label"`
+	ExternalRef    string      `json:"external_ref,omitempty"`
+	CashierUserID  *uuid.UUID  `json:"cashier_user_id"`
+	AmountTendered utils.Money `json:"amount_tendered"`
+	ChangeAmount   utils.Money `json:"change_amount"`
+	RefundedAmount utils.Money `json:"refunded_amount"`
+	PaidAt         *string     `json:"paid_at,omitempty"`
+	CreatedAt      string      `json:"created_at"`
+	SnapToken      string      `json:"snap_token,omitempty"`
+	SnapURL        string      `json:"snap_url,omitempty"`
+}
+
+// TransactionPaymentsResponse ringkasan pembayaran transaksi (GET /transaction/:id/payments)
+type TransactionPaymentsResponse struct {
+	TransactionID   uuid.UUID         `json:"transaction_id"`
+	TotalAmount     utils.Money       `json:"total_amount"`
+	PaidAmount      utils.Money       `json:"paid_amount"`      // payment berstatus paid
+	PendingAmount   utils.Money       `json:"pending_amount"`   // payment non-cash yang menunggu pembayaran
+	RemainingAmount utils.Money       `json:"remaining_amount"` // total - paid - pending
+	PaymentStatus   string            `json:"payment_status"`
+	Payments        []PaymentResponse `json:"payments"`
+}
+
+// SplitBillRequest body POST /transaction/:id/split (pratinjau pembagian tagihan, tidak menyimpan apa pun).
+// Split per nominal bebas langsung lewat POST /transaction/:id/payments.
+type SplitBillRequest struct {
+	Mode   string              `json:"mode" binding:"required,oneof=even item"`
+	Parts  int                 `json:"parts" binding:"omitempty,min=2,max=50"` // mode even: jumlah orang
+	Groups []SplitGroupRequest `json:"groups" binding:"omitempty,dive"`        // mode item: item per orang
+}
+
+// SplitGroupRequest item yang ditanggung satu orang (mode item)
+type SplitGroupRequest struct {
+	Label string             `json:"label" binding:"max=100"`
+	Items []SplitItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// SplitItemRequest item (dan qty) dalam satu grup split
+type SplitItemRequest struct {
+	TransactionItemID uuid.UUID `json:"transaction_item_id" binding:"required"`
+	Quantity          int       `json:"quantity" binding:"required,min=1"`
+}
+
+// SplitItemResponse porsi satu item dalam bagian split (sudah termasuk diskon, service charge, dan pajak)
+type SplitItemResponse struct {
+	TransactionItemID uuid.UUID   `json:"transaction_item_id"`
+	MenuName          string      `json:"menu_name"`
+	Quantity          int         `json:"quantity"`
+	Amount            utils.Money `json:"amount"`
+}
+
+// SplitPartResponse satu bagian tagihan (nominal untuk satu payment)
+type SplitPartResponse struct {
+	Label  string              `json:"label"`
+	Amount utils.Money         `json:"amount"`
+	Items  []SplitItemResponse `json:"items,omitempty"`
+}
+
+// SplitBillResponse hasil pratinjau split bill
+type SplitBillResponse struct {
+	Mode            string              `json:"mode"`
+	TotalAmount     utils.Money         `json:"total_amount"`
+	RemainingAmount utils.Money         `json:"remaining_amount"`
+	Parts           []SplitPartResponse `json:"parts"`
+}
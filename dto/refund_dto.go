@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/google/uuid"
+
+// CreateRefundRequest body POST /transaction/:id/refund (admin)
+type CreateRefundRequest struct {
+	Type   string                    `json:"type" binding:"required,oneof=full partial"`
+	Reason string                    `json:"reason" binding:"required"`
+	Items  []CreateRefundItemRequest `json:"items" binding:"omitempty,dive"` // wajib untuk partial
+}
+
+// CreateRefundItemRequest item yang dikembalikan pada refund partial
+type CreateRefundItemRequest struct {
+	TransactionItemID uuid.UUID `json:"transaction_item_id" binding:"required"`
+	Quantity          int       `json:"quantity" binding:"required,min=1"`
+}
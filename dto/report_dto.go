@@ -1,25 +1,45 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+)
 
 // ReportSummary agregasi laporan harian (transaksi completed & paid)
 type ReportSummary struct {
 	Date            string  `json:"date"`             // YYYY-MM-DD
 	TotalTransactions int   `json:"total_transactions"`
-	TotalSales       float64 `json:"total_sales"`       // total_amount sum
-	TotalCash        float64 `json:"total_cash"`        // sum where payment_method = cash
-	TotalNonCash     float64 `json:"total_non_cash"`    // sum where payment_method != cash
-	TotalDiscount    float64 `json:"total_discount"`
-	TotalTax         float64 `json:"total_tax"`
+	TotalSales       utils.Money `json:"total_sales"`       // total_amount sum
+	TotalCash        utils.Money `json:"total_cash"`        // sum where payment_method = cash (dikurangi refund tunai)
+	TotalNonCash     utils.Money `json:"total_non_cash"`    // sum where payment_method != cash (dikurangi refund non-tunai)
+	TotalDiscount    utils.Money `json:"total_discount"`
+	TotalServiceCharge utils.Money `json:"total_service_charge"`
+	TotalTax         utils.Money `json:"total_tax"`
+	Taxes            []ReportTaxLine `json:"taxes"`           // rincian per aturan pajak / service charge
+	TotalRefund      utils.Money `json:"total_refund"`      // refund yang diproses di tanggal ini
+	NetSales         utils.Money `json:"net_sales"`         // total_sales - total_refund
+}
+
+// ReportTaxLine total satu aturan pajak / service charge dalam laporan
+type ReportTaxLine struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	Rate          float64     `json:"rate"`
+	IsInclusive   bool        `json:"is_inclusive"`
+	TaxableAmount utils.Money `json:"taxable_amount"`
+	Amount        utils.Money `json:"amount"`
 }
 
 // ReportTransactionItem satu transaksi dalam list laporan (dengan nama kasir)
 type ReportTransactionItem struct {
 	ID               uuid.UUID `json:"id"`
+	OrderNumber      string    `json:"order_number"`
 	CustomerName     string    `json:"customer_name"`
 	OrderType        string    `json:"order_type"`
 	PaymentMethod    string    `json:"payment_method"`
-	TotalAmount      float64   `json:"total_amount"`
+	TotalAmount      utils.Money   `json:"total_amount"`
+	RefundedAmount   utils.Money   `json:"refunded_amount"`
 	ClosedByUserID   *uuid.UUID `json:"closed_by_user_id,omitempty"`
 	ClosedByUserName string    `json:"closed_by_user_name"` // dari join users, atau "-" jika null
 	CreatedAt        string    `json:"created_at"`
@@ -35,14 +55,14 @@ type ReportResponse struct {
 type ChartDailyItem struct {
 	Date               string  `json:"date"` // YYYY-MM-DD
 	TotalTransactions  int     `json:"total_transactions"`
-	TotalSales         float64 `json:"total_sales"`
+	TotalSales         utils.Money `json:"total_sales"`
 }
 
 // ChartMonthlyItem satu titik untuk grafik bulanan
 type ChartMonthlyItem struct {
 	Month              string  `json:"month"` // YYYY-MM
 	TotalTransactions  int     `json:"total_transactions"`
-	TotalSales         float64 `json:"total_sales"`
+	TotalSales         utils.Money `json:"total_sales"`
 }
 
 // ChartResponse response GET /report/charts (untuk dashboard admin)
@@ -50,3 +70,157 @@ type ChartResponse struct {
 	Daily   []ChartDailyItem   `json:"daily"`
 	Monthly []ChartMonthlyItem `json:"monthly"`
 }
+
+// IngredientUsageLine pemakaian satu bahan dalam periode laporan.
+// Theoretical = pemakaian menurut resep (penjualan yang dibayar). Actual = theoretical + waste - koreksi stock opname.
+// Variance = actual - theoretical (positif = bahan terpakai lebih banyak dari resep).
+type IngredientUsageLine struct {
+	IngredientID    uuid.UUID   `json:"ingredient_id"`
+	Name            string      `json:"name"`
+	Unit            string      `json:"unit"`
+	Theoretical     float64     `json:"theoretical"`
+	Waste           float64     `json:"waste"`
+	Correction      float64     `json:"correction"` // total perubahan dari stock opname (negatif = stok fisik kurang)
+	Purchased       float64     `json:"purchased"`
+	Actual          float64     `json:"actual"`
+	Variance        float64     `json:"variance"`
+	VariancePercent float64     `json:"variance_percent"` // terhadap theoretical; 0 jika theoretical 0
+	UnitCost        utils.Money `json:"unit_cost"`        // harga per unit dari pembelian terakhir
+	VarianceCost    utils.Money `json:"variance_cost"`
+}
+
+// IngredientUsageReport response GET /report/ingredient-usage (theoretical vs actual)
+type IngredientUsageReport struct {
+	DateFrom          string                `json:"date_from"` // YYYY-MM-DD
+	DateTo            string                `json:"date_to"`   // YYYY-MM-DD (inklusif)
+	Items             []IngredientUsageLine `json:"items"`     // variance_cost terbesar di atas
+	TotalVarianceCost utils.Money           `json:"total_variance_cost"`
+}
+
+// ReportRangeSummary agregasi laporan periode (transaksi completed & dibayar dalam rentang hari bisnis, inklusif)
+type ReportRangeSummary struct {
+	DateFrom           string          `json:"date_from"` // YYYY-MM-DD
+	DateTo             string          `json:"date_to"`   // YYYY-MM-DD (inklusif)
+	TotalTransactions  int             `json:"total_transactions"`
+	TotalSales         utils.Money     `json:"total_sales"`
+	TotalCash          utils.Money     `json:"total_cash"`     // tender tunai (dikurangi refund tunai)
+	TotalNonCash       utils.Money     `json:"total_non_cash"` // tender non-tunai (dikurangi refund non-tunai)
+	TotalDiscount      utils.Money     `json:"total_discount"`
+	TotalServiceCharge utils.Money     `json:"total_service_charge"`
+	TotalTax           utils.Money     `json:"total_tax"`
+	Taxes              []ReportTaxLine `json:"taxes"`
+	TotalRefund        utils.Money     `json:"total_refund"` // refund yang diproses dalam periode
+	NetSales           utils.Money     `json:"net_sales"`    // total_sales - total_refund
+	AverageTransaction utils.Money     `json:"average_transaction"`
+}
+
+// ReportDayLine penjualan satu hari bisnis dalam periode
+type ReportDayLine struct {
+	Date              string      `json:"date"` // YYYY-MM-DD
+	TotalTransactions int         `json:"total_transactions"`
+	TotalSales        utils.Money `json:"total_sales"`
+}
+
+// ReportMenuLine penjualan satu menu dalam periode. net_sales = gross_sales - discount (sebelum service charge & pajak).
+type ReportMenuLine struct {
+	MenuID           uuid.UUID   `json:"menu_id"`
+	MenuName         string      `json:"menu_name"`
+	CategoryName     string      `json:"category_name"`
+	Quantity         int         `json:"quantity"`
+	RefundedQuantity int         `json:"refunded_quantity"`
+	GrossSales       utils.Money `json:"gross_sales"`
+	Discount         utils.Money `json:"discount"`
+	NetSales         utils.Money `json:"net_sales"`
+}
+
+// ReportCategoryLine penjualan satu kategori menu dalam periode
+type ReportCategoryLine struct {
+	CategoryID   *uuid.UUID  `json:"category_id"`
+	CategoryName string      `json:"category_name"`
+	Quantity     int         `json:"quantity"`
+	GrossSales   utils.Money `json:"gross_sales"`
+	Discount     utils.Money `json:"discount"`
+	NetSales     utils.Money `json:"net_sales"`
+}
+
+// ReportHourLine penjualan per jam (0-23, waktu lokal toko)
+type ReportHourLine struct {
+	Hour              int         `json:"hour"`
+	TotalTransactions int         `json:"total_transactions"`
+	TotalSales        utils.Money `json:"total_sales"`
+}
+
+// ReportWeekdayLine penjualan per hari dalam minggu (1 = Senin ... 7 = Minggu, menurut hari bisnis)
+type ReportWeekdayLine struct {
+	Weekday           int         `json:"weekday"`
+	Name              string      `json:"name"`
+	TotalTransactions int         `json:"total_transactions"`
+	TotalSales        utils.Money `json:"total_sales"`
+}
+
+// ReportOrderTypeLine penjualan per jenis pesanan (dine_in, takeaway, ...)
+type ReportOrderTypeLine struct {
+	OrderType         string      `json:"order_type"`
+	TotalTransactions int         `json:"total_transactions"`
+	TotalSales        utils.Money `json:"total_sales"`
+}
+
+// ReportPaymentMethodLine nominal per metode bayar (per tender, sehingga split bill terbagi ke tiap metode)
+type ReportPaymentMethodLine struct {
+	PaymentMethod     string      `json:"payment_method"`
+	TotalTransactions int         `json:"total_transactions"`
+	Amount            utils.Money `json:"amount"`
+}
+
+// ReportPromoLine pemakaian satu kode promo dalam periode
+type ReportPromoLine struct {
+	PromoCode         string      `json:"promo_code"`
+	TotalTransactions int         `json:"total_transactions"`
+	TotalDiscount     utils.Money `json:"total_discount"`
+	TotalSales        utils.Money `json:"total_sales"`
+}
+
+// ReportRangeResponse response GET /report?from=&to= (laporan periode dengan rincian)
+type ReportRangeResponse struct {
+	Summary         ReportRangeSummary        `json:"summary"`
+	Daily           []ReportDayLine           `json:"daily"`
+	ByMenu          []ReportMenuLine          `json:"by_menu"` // qty terbanyak di atas
+	ByCategory      []ReportCategoryLine      `json:"by_category"`
+	ByHour          []ReportHourLine          `json:"by_hour"`    // selalu 24 baris
+	ByWeekday       []ReportWeekdayLine       `json:"by_weekday"` // selalu 7 baris
+	ByOrderType     []ReportOrderTypeLine     `json:"by_order_type"`
+	ByPaymentMethod []ReportPaymentMethodLine `json:"by_payment_method"`
+	ByPromo         []ReportPromoLine         `json:"by_promo"`
+}
+
+// CloseZReportRequest body POST /report/z/close (tutup hari, admin)
+type CloseZReportRequest struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD (hari bisnis)
+}
+
+// ZReportVoids pembatalan dan pengembalian dana pada hari tersebut
+type ZReportVoids struct {
+	CancelledOrders   int         `json:"cancelled_orders"` // pesanan yang dibatalkan
+	CancelledAmount   utils.Money `json:"cancelled_amount"`
+	VoidedItems       int         `json:"voided_items"` // qty item open bill yang dihapus sebelum dimasak
+	VoidedItemsAmount utils.Money `json:"voided_items_amount"`
+	Refunds           int         `json:"refunds"`
+	RefundAmount      utils.Money `json:"refund_amount"`
+}
+
+// ZReportResponse laporan tutup hari (Z-report). Selama belum ditutup status = draft dan number = null;
+// setelah ditutup isinya adalah snapshot saat penutupan dan tidak berubah lagi.
+type ZReportResponse struct {
+	Number           *int                      `json:"number"`
+	Date             string                    `json:"date"`   // YYYY-MM-DD
+	Status           string                    `json:"status"` // draft, closed
+	GeneratedAt      string                    `json:"generated_at"`
+	ClosedAt         *string                   `json:"closed_at"`
+	ClosedByUserID   *string                   `json:"closed_by_user_id"`
+	ClosedByUserName string                    `json:"closed_by_user_name"`
+	Summary          ReportSummary             `json:"summary"`
+	PaymentMethods   []ReportPaymentMethodLine `json:"payment_methods"`
+	Voids            ZReportVoids              `json:"voids"`
+	Settlements      []SettlementStatusItem    `json:"settlements"` // per shift kasir
+	TopItems         []ReportMenuLine          `json:"top_items"`   // 10 menu terlaris (qty)
+}
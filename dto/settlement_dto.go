@@ -1,38 +1,159 @@
 package dto
 
-// CreateSettlementRequest body untuk POST settlement (tutup kasir)
+import "pos-go/utils"
+
+// OpenShiftRequest body POST /shift/open (buka shift kasir)
+type OpenShiftRequest struct {
+	OpeningFloat utils.Money `json:"opening_float" binding:"min=0"` // modal uang kembalian di laci
+	Note         string      `json:"note" binding:"max=255"`
+}
+
+// ShiftCashMovementRequest body POST /shift/current/cash-movements (pay-in / pay-out di tengah shift)
+type ShiftCashMovementRequest struct {
+	Type   string      `json:"type" binding:"required,oneof=pay_in pay_out"`
+	Amount utils.Money `json:"amount" binding:"required,gt=0"`
+	Reason string      `json:"reason" binding:"required,max=255"`
+}
+
+// DenominationRequest jumlah lembar / keping per pecahan saat hitung uang
+type DenominationRequest struct {
+	Denomination utils.Money `json:"denomination" binding:"required,gt=0"` // mis. 100000, 500
+	Quantity     int         `json:"quantity" binding:"min=0"`
+}
+
+// CreateSettlementRequest body POST /settlement (tutup shift yang sedang open).
+// actual_cash boleh dikosongkan jika denominations diisi (dihitung dari rincian pecahan); jika keduanya diisi harus sama.
 type CreateSettlementRequest struct {
-	Date       string  `json:"date" binding:"required"`        // YYYY-MM-DD
-	ActualCash float64 `json:"actual_cash" binding:"required"`  // uang tunai yang diserahkan kasir
+	ActualCash    *utils.Money          `json:"actual_cash" binding:"omitempty,min=0"` // uang tunai yang dihitung kasir
+	Denominations []DenominationRequest `json:"denominations" binding:"omitempty,dive"`
+	Note          string                `json:"note" binding:"max=500"`
+}
+
+// ReviewSettlementRequest body PATCH /settlement/:id/review (admin). Catatan wajib saat reject.
+type ReviewSettlementRequest struct {
+	Action string `json:"action" binding:"required,oneof=approve reject"`
+	Note   string `json:"note" binding:"max=500"`
 }
 
-// SettlementResponse response settlement (untuk GET / POST)
+// SettlementDiscrepancyFilter filter query GET /settlement/discrepancies (semua opsional)
+type SettlementDiscrepancyFilter struct {
+	DateFrom string `form:"date_from"` // YYYY-MM-DD
+	DateTo   string `form:"date_to"`   // YYYY-MM-DD
+}
+
+// DenominationResponse rincian hitung uang per pecahan
+type DenominationResponse struct {
+	Denomination utils.Money `json:"denomination"`
+	Quantity     int         `json:"quantity"`
+	Subtotal     utils.Money `json:"subtotal"`
+}
+
+// SettlementResponse response settlement (tutup shift)
 type SettlementResponse struct {
-	ID           string  `json:"id"`
-	Date         string  `json:"date"`           // YYYY-MM-DD
-	UserID       string  `json:"user_id"`
-	ExpectedCash float64 `json:"expected_cash"`
-	ActualCash   float64 `json:"actual_cash"`
-	Discrepancy  float64 `json:"discrepancy"`
-	CreatedAt    string  `json:"created_at"`
+	ID               string                       `json:"id"`
+	ShiftID          *string                      `json:"shift_id"` // null untuk settlement lama (per tanggal)
+	Date             string                       `json:"date"`     // YYYY-MM-DD, tanggal shift dibuka
+	UserID           string                       `json:"user_id"`
+	OpeningFloat     utils.Money                  `json:"opening_float"`
+	ExpectedCash     utils.Money                  `json:"expected_cash"`
+	ActualCash       utils.Money                  `json:"actual_cash"`
+	Discrepancy      utils.Money                  `json:"discrepancy"`
+	TotalTendered    utils.Money                  `json:"total_tendered"` // uang tunai diterima dari pelanggan
+	TotalChange      utils.Money                  `json:"total_change"`   // kembalian yang diberikan
+	CashRefunds      utils.Money                  `json:"cash_refunds"`
+	PayIns           utils.Money                  `json:"pay_ins"`
+	PayOuts          utils.Money                  `json:"pay_outs"`
+	Denominations    []DenominationResponse       `json:"denominations"`
+	Note             string                       `json:"note"`
+	Status           string                       `json:"status"` // submitted, approved, rejected
+	Revision         int                          `json:"revision"`
+	ReviewedByUserID *string                      `json:"reviewed_by_user_id"`
+	ReviewedAt       *string                      `json:"reviewed_at"`
+	ReviewNote       string                       `json:"review_note"`
+	CreatedAt        string                       `json:"created_at"`
+	Revisions        []SettlementRevisionResponse `json:"revisions,omitempty"` // hanya pada detail settlement
+}
+
+// SettlementRevisionResponse satu pengajuan settlement beserta hasil review-nya
+type SettlementRevisionResponse struct {
+	Revision          int                    `json:"revision"`
+	ExpectedCash      utils.Money            `json:"expected_cash"`
+	ActualCash        utils.Money            `json:"actual_cash"`
+	Discrepancy       utils.Money            `json:"discrepancy"`
+	Denominations     []DenominationResponse `json:"denominations"`
+	Note              string                 `json:"note"`
+	SubmittedByUserID string                 `json:"submitted_by_user_id"`
+	Status            string                 `json:"status"`
+	ReviewedByUserID  *string                `json:"reviewed_by_user_id"`
+	ReviewedAt        *string                `json:"reviewed_at"`
+	ReviewNote        string                 `json:"review_note"`
+	CreatedAt         string                 `json:"created_at"`
+}
+
+// SettlementDiscrepancyItem satu settlement dengan selisih yang belum selesai (admin GET /settlement/discrepancies)
+type SettlementDiscrepancyItem struct {
+	UserName   string             `json:"user_name"`
+	Settlement SettlementResponse `json:"settlement"`
+}
+
+// ShiftCashSummary rekap uang tunai satu shift. expected_cash = opening_float + cash_sales - cash_refunds + pay_ins - pay_outs
+type ShiftCashSummary struct {
+	OpeningFloat  utils.Money `json:"opening_float"`
+	TotalTendered utils.Money `json:"total_tendered"` // uang tunai diterima dari pelanggan
+	TotalChange   utils.Money `json:"total_change"`   // kembalian yang diberikan
+	CashSales     utils.Money `json:"cash_sales"`     // total_tendered - total_change
+	CashRefunds   utils.Money `json:"cash_refunds"`
+	PayIns        utils.Money `json:"pay_ins"`
+	PayOuts       utils.Money `json:"pay_outs"`
+	ExpectedCash  utils.Money `json:"expected_cash"`
+}
+
+// ShiftCashMovementResponse satu pay-in / pay-out
+type ShiftCashMovementResponse struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Amount    utils.Money `json:"amount"`
+	Reason    string      `json:"reason"`
+	UserID    string      `json:"user_id"`
+	CreatedAt string      `json:"created_at"`
+}
+
+// ShiftResponse shift kasir beserta rekap tunai (sampai sekarang jika masih open) dan settlement jika sudah ditutup
+type ShiftResponse struct {
+	ID            string                      `json:"id"`
+	UserID        string                      `json:"user_id"`
+	UserName      string                      `json:"user_name"`
+	Status        string                      `json:"status"` // open, closed
+	OpenedAt      string                      `json:"opened_at"`
+	ClosedAt      *string                     `json:"closed_at"`
+	Note          string                      `json:"note"`
+	Summary       ShiftCashSummary            `json:"summary"`
+	CashMovements []ShiftCashMovementResponse `json:"cash_movements"`
+	Settlement    *SettlementResponse         `json:"settlement"` // null jika shift masih open
 }
 
-// GetSettlementResponse response GET /settlement?date= (expected_cash + settlement jika sudah ada)
+// GetSettlementResponse response GET /settlement?date= (shift kasir yang login yang dibuka di tanggal tersebut)
 type GetSettlementResponse struct {
-	ExpectedCash float64             `json:"expected_cash"` // uang tunai yang seharusnya (dari transaksi)
-	Settlement   *SettlementResponse `json:"settlement"`   // null jika belum tutup kasir
+	Date   string          `json:"date"`
+	Shifts []ShiftResponse `json:"shifts"`
 }
 
-// SettlementStatusItem satu baris status settlement per kasir (untuk admin GET /settlement/status-by-date)
+// SettlementStatusItem satu baris status settlement per shift (untuk admin GET /settlement/status-by-date)
 type SettlementStatusItem struct {
-	UserID       string   `json:"user_id"`
-	UserName     string   `json:"user_name"`
-	ExpectedCash float64  `json:"expected_cash"`
-	Settlement   *SettlementResponse `json:"settlement,omitempty"` // null jika belum tutup kasir
+	ShiftID       string              `json:"shift_id"` // kosong untuk settlement lama (per tanggal)
+	UserID        string              `json:"user_id"`
+	UserName      string              `json:"user_name"`
+	Status        string              `json:"status"` // open, closed
+	OpenedAt      string              `json:"opened_at"`
+	ClosedAt      *string             `json:"closed_at"`
+	ExpectedCash  utils.Money         `json:"expected_cash"`
+	TotalTendered utils.Money         `json:"total_tendered"`
+	TotalChange   utils.Money         `json:"total_change"`         // kembalian yang diberikan kasir ini
+	Settlement    *SettlementResponse `json:"settlement,omitempty"` // null jika shift belum ditutup
 }
 
 // GetSettlementStatusByDateResponse response GET /settlement/status-by-date (admin)
 type GetSettlementStatusByDateResponse struct {
-	Date    string                `json:"date"`
-	Items   []SettlementStatusItem `json:"items"`
+	Date  string                 `json:"date"`
+	Items []SettlementStatusItem `json:"items"`
 }
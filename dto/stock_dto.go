@@ -0,0 +1,17 @@
+package dto
+
+// StockAdjustmentDTO body POST /menu/:id/stock (admin).
+// waste / restock: quantity = jumlah yang berkurang / bertambah (minimal 1).
+// correction: quantity = hasil hitung fisik (stok menjadi nilai ini).
+type StockAdjustmentDTO struct {
+	Type     string `json:"type" binding:"required,oneof=waste restock correction"`
+	Quantity int    `json:"quantity" binding:"min=0"`
+	Note     string `json:"note" binding:"max=500"`
+}
+
+// StockMovementListFilter filter query GET /menu/:id/stock-movements
+type StockMovementListFilter struct {
+	Type     string `form:"type" binding:"omitempty,oneof=sale sale_void waste restock correction"`
+	DateFrom string `form:"date_from"` // YYYY-MM-DD
+	DateTo   string `form:"date_to"`   // YYYY-MM-DD (inklusif)
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateTableDTO untuk create meja
+type CreateTableDTO struct {
+	Number   int    `json:"number" binding:"required,min=1"`
+	Area     string `json:"area" binding:"max=50"`
+	Capacity int    `json:"capacity" binding:"min=0"`
+}
+
+// UpdateTableDTO untuk update meja (partial update)
+type UpdateTableDTO struct {
+	Number   int     `json:"number" binding:"omitempty,min=1"`
+	Area     *string `json:"area" binding:"omitempty,max=50"`
+	Capacity *int    `json:"capacity" binding:"omitempty,min=0"`
+}
+
+// UpdateTableStatusDTO body PATCH /table/:id/status (occupied hanya lewat pesanan)
+type UpdateTableStatusDTO struct {
+	Status string `json:"status" binding:"required,oneof=free reserved cleaning"`
+}
+
+// FloorTransactionResponse ringkasan pesanan yang sedang menempati meja
+type FloorTransactionResponse struct {
+	ID            uuid.UUID   `json:"id"`
+	CustomerName  string      `json:"customer_name"`
+	OrderStatus   string      `json:"order_status"`
+	PaymentStatus string      `json:"payment_status"`
+	TotalAmount   utils.Money `json:"total_amount"`
+}
+
+// FloorTableResponse satu meja pada denah
+type FloorTableResponse struct {
+	ID          uuid.UUID                 `json:"id"`
+	Number      int                       `json:"number"`
+	Capacity    int                       `json:"capacity"`
+	Status      string                    `json:"status"` // free, occupied, reserved, cleaning
+	OccupiedAt  *time.Time                `json:"occupied_at,omitempty"`
+	Transaction *FloorTransactionResponse `json:"transaction,omitempty"`
+}
+
+// FloorAreaResponse meja-meja dalam satu area
+type FloorAreaResponse struct {
+	Area   string               `json:"area"`
+	Tables []FloorTableResponse `json:"tables"`
+}
+
+// FloorResponse response GET /table/floor (status semua meja untuk kasir)
+type FloorResponse struct {
+	Summary map[string]int      `json:"summary"` // jumlah meja per status
+	Areas   []FloorAreaResponse `json:"areas"`
+}
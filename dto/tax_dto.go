@@ -0,0 +1,23 @@
+package dto
+
+// CreateTaxRuleDTO untuk create aturan pajak / service charge
+type CreateTaxRuleDTO struct {
+	Name              string   `json:"name" binding:"required,max=100"`
+	Type              string   `json:"type" binding:"required,oneof=tax service_charge"`
+	Rate              float64  `json:"rate" binding:"required,gt=0,max=100"` // persen
+	IsInclusive       bool     `json:"is_inclusive"`                         // hanya berlaku untuk type tax
+	OrderType         string   `json:"order_type" binding:"omitempty,oneof=dine_in take_away"`
+	IsActive          bool     `json:"is_active"`
+	ExemptCategoryIDs []string `json:"exempt_category_ids" binding:"omitempty,dive,uuid"`
+}
+
+// UpdateTaxRuleDTO untuk update aturan pajak (partial update, sama seperti promo)
+type UpdateTaxRuleDTO struct {
+	Name              string   `json:"name" binding:"omitempty,max=100"`
+	Type              string   `json:"type" binding:"omitempty,oneof=tax service_charge"`
+	Rate              float64  `json:"rate" binding:"omitempty,gt=0,max=100"`
+	IsInclusive       bool     `json:"is_inclusive"`
+	OrderType         string   `json:"order_type" binding:"omitempty,oneof=dine_in take_away"`
+	IsActive          bool     `json:"is_active"`
+	ExemptCategoryIDs []string `json:"exempt_category_ids" binding:"omitempty,dive,uuid"`
+}
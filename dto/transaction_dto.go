@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+)
 
 // CreateTransactionRequest represents the request to create a new transaction
 type CreateTransactionRequest struct {
@@ -11,13 +15,21 @@ type CreateTransactionRequest struct {
 	PaymentMethod string                         `json:"payment_method" binding:"required,oneof=cash credit_card debit_card e_wallet"`
 	Notes         string                         `json:"notes" binding:"omitempty"`
 	PromoCode     string                         `json:"promo_code" binding:"omitempty"`
+	OpenBill      bool                           `json:"open_bill"` // dine-in saja: item bisa ditambah per ronde, dibayar sekali saat bill ditutup
 	Items         []CreateTransactionItemRequest `json:"items" binding:"required,min=1,dive"`
 }
 
+// AddTransactionItemsRequest ronde item baru untuk open bill (POST /transaction/:id/items)
+type AddTransactionItemsRequest struct {
+	Items []CreateTransactionItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
 // CreateTransactionItemRequest represents an item in the transaction
 type CreateTransactionItemRequest struct {
-	MenuID   uuid.UUID `json:"menu_id" binding:"required"`
-	Quantity int       `json:"quantity" binding:"required,min=1"`
+	MenuID            uuid.UUID   `json:"menu_id" binding:"required"`
+	Quantity          int         `json:"quantity" binding:"required,min=1"`
+	ModifierOptionIDs []uuid.UUID `json:"modifier_option_ids"`     // pilihan modifier (ukuran, level pedas, topping); divalidasi terhadap grup di menu
+	Notes             string      `json:"notes" binding:"max=255"` // catatan item untuk dapur (mis. "tanpa bawang")
 }
 
 // TransactionResponse represents the response for a transaction
@@ -28,10 +40,12 @@ type TransactionResponse struct {
 	OrderType     string                    `json:"order_type"`
 	TableNumber   *int                      `json:"table_number"`
 	PromoCode     string                    `json:"promo_code"`
-	Discount      float64                   `json:"discount"`
-	Subtotal      float64                   `json:"subtotal"`      // Total sebelum pajak
-	Tax           float64                   `json:"tax"`           // Pajak PPN 10%
-	TotalAmount   float64                   `json:"total_amount"`  // Total setelah pajak
+	Discount      utils.Money               `json:"discount"`
+	Subtotal      utils.Money               `json:"subtotal"`       // Total setelah diskon, sebelum service charge & pajak
+	ServiceCharge utils.Money               `json:"service_charge"` // Service charge (mis. dine-in)
+	Tax           utils.Money               `json:"tax"`            // Total pajak sesuai aturan pajak aktif
+	TotalAmount   utils.Money               `json:"total_amount"`   // Total setelah pajak
+	Taxes         []TransactionTaxResponse  `json:"taxes"`
 	PaymentMethod string                    `json:"payment_method"`
 	PaymentStatus string                    `json:"payment_status"`
 	OrderStatus   string                    `json:"order_status"`
@@ -44,12 +58,35 @@ type TransactionResponse struct {
 
 // TransactionItemResponse represents an item in the transaction response
 type TransactionItemResponse struct {
-	ID        uuid.UUID `json:"id"`
-	MenuID    uuid.UUID `json:"menu_id"`
-	MenuName  string    `json:"menu_name"`
-	MenuPrice float64   `json:"menu_price"`
-	Quantity  int       `json:"quantity"`
-	Subtotal  float64   `json:"subtotal"`
+	ID            uuid.UUID              `json:"id"`
+	MenuID        uuid.UUID              `json:"menu_id"`
+	MenuName      string                 `json:"menu_name"`
+	MenuPrice     utils.Money            `json:"menu_price"`
+	ModifierPrice utils.Money            `json:"modifier_price"` // total price_delta modifier per porsi
+	Modifiers     []ItemModifierResponse `json:"modifiers"`
+	Quantity      int                    `json:"quantity"`
+	Subtotal      utils.Money            `json:"subtotal"`
+	Notes         string                 `json:"notes"`
+	Station       string                 `json:"station"`
+	Status        string                 `json:"status"` // queued, cooking, ready
+	Batch         int                    `json:"batch"`  // ronde pesanan (open bill)
+}
+
+// ItemModifierResponse satu pilihan modifier pada item (snapshot saat dipesan)
+type ItemModifierResponse struct {
+	GroupName  string      `json:"group_name"`
+	OptionName string      `json:"option_name"`
+	PriceDelta utils.Money `json:"price_delta"`
+}
+
+// TransactionTaxResponse rincian satu aturan pajak / service charge pada transaksi
+type TransactionTaxResponse struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"` // tax | service_charge
+	Rate          float64     `json:"rate"` // persen
+	IsInclusive   bool        `json:"is_inclusive"`
+	TaxableAmount utils.Money `json:"taxable_amount"`
+	Amount        utils.Money `json:"amount"`
 }
 
 // GetTransactionsResponse represents the response for getting all transactions
@@ -73,20 +110,24 @@ type CreateTransactionResponse struct {
 // TransactionResponseWithSnap untuk response yang include snap token (untuk non-cash payment)
 type TransactionResponseWithSnap struct {
 	ID            uuid.UUID                 `json:"id"`
+	OrderNumber   string                    `json:"order_number"` // nomor pesanan harian, mis. A-042
 	CustomerName  string                    `json:"customer_name"`
 	CustomerPhone string                    `json:"customer_phone"`
 	OrderType     string                    `json:"order_type"`
 	TableNumber   *int                      `json:"table_number"`
 	PromoCode     string                    `json:"promo_code"`
-	Discount      float64                   `json:"discount"`
-	Subtotal      float64                   `json:"subtotal"`             // Total sebelum pajak
-	Tax           float64                   `json:"tax"`                  // Pajak PPN 10%
-	TotalAmount   float64                   `json:"total_amount"`         // Total setelah pajak
+	Discount      utils.Money               `json:"discount"`
+	Subtotal      utils.Money               `json:"subtotal"`             // Total setelah diskon, sebelum service charge & pajak
+	ServiceCharge utils.Money               `json:"service_charge"`       // Service charge (mis. dine-in)
+	Tax           utils.Money               `json:"tax"`                  // Total pajak sesuai aturan pajak aktif
+	TotalAmount   utils.Money               `json:"total_amount"`         // Total setelah pajak
+	Taxes         []TransactionTaxResponse  `json:"taxes"`
 	PaymentMethod string                    `json:"payment_method"`
 	PaymentStatus string                    `json:"payment_status"`
 	OrderStatus   string                    `json:"order_status"`
 	Notes         string                    `json:"notes"`
 	ExpiredAt     *string                   `json:"expired_at,omitempty"` // Waktu kadaluarsa
+	IsOpenBill    bool                      `json:"is_open_bill"`
 	Items         []TransactionItemResponse `json:"items"`
 	SnapToken     string                    `json:"snap_token,omitempty"` // Untuk non-cash
 	SnapURL       string                    `json:"snap_url,omitempty"`   // Untuk non-cash
@@ -98,6 +139,7 @@ type TransactionResponseWithSnap struct {
 type MidtransNotification struct {
 	TransactionID     string `json:"transaction_id"`
 	OrderID           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
 	GrossAmount       string `json:"gross_amount"`
 	PaymentType       string `json:"payment_type"`
 	TransactionStatus string `json:"transaction_status"`
@@ -110,28 +152,100 @@ type UpdateOrderStatusRequest struct {
 	OrderStatus string `json:"order_status" binding:"required,oneof=pending cooking ready completed cancelled"`
 }
 
+// UpdateItemStatusRequest untuk update status satu item pesanan (koki per stasiun)
+type UpdateItemStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=cooking ready"`
+}
+
 // ReceiptItemResponse item untuk struk
 type ReceiptItemResponse struct {
-	MenuName  string  `json:"menu_name"`
-	Quantity  int     `json:"quantity"`
-	MenuPrice float64 `json:"menu_price"`
-	Subtotal  float64 `json:"subtotal"`
+	MenuName  string                 `json:"menu_name"`
+	Modifiers []ItemModifierResponse `json:"modifiers"`
+	Quantity  int                    `json:"quantity"`
+	MenuPrice utils.Money            `json:"menu_price"` // harga per porsi termasuk modifier
+	Subtotal  utils.Money            `json:"subtotal"`
 }
 
 // ReceiptResponse data struk untuk print (GET /transaction/:id/receipt)
 type ReceiptResponse struct {
 	ID                 uuid.UUID              `json:"id"`
+	OrderNumber        string                 `json:"order_number"` // nomor pesanan harian, kosong untuk transaksi lama
 	CreatedAt          string                 `json:"created_at"`
 	CustomerName       string                 `json:"customer_name"`
 	CustomerPhone      string                 `json:"customer_phone"`
 	OrderType          string                 `json:"order_type"`
 	TableNumber        *int                   `json:"table_number,omitempty"`
 	Items              []ReceiptItemResponse `json:"items"`
-	Subtotal           float64                `json:"subtotal"`
-	Discount           float64                `json:"discount"`
-	Tax                float64                `json:"tax"`
-	TotalAmount        float64                `json:"total_amount"`
+	Subtotal           utils.Money            `json:"subtotal"`
+	Discount           utils.Money            `json:"discount"`
+	ServiceCharge      utils.Money            `json:"service_charge"`
+	Tax                utils.Money            `json:"tax"`
+	Taxes              []TransactionTaxResponse `json:"taxes"` // rincian per aturan (sama dengan item_details Midtrans)
+	TotalAmount        utils.Money            `json:"total_amount"`
 	PaymentMethod      string                 `json:"payment_method"`
 	PaymentStatus      string                 `json:"payment_status"`
+	AmountTendered     utils.Money            `json:"amount_tendered"` // uang tunai yang diterima (0 jika non-tunai)
+	ChangeAmount       utils.Money            `json:"change_amount"`   // kembalian tunai
 	ClosedByUserName   string                 `json:"closed_by_user_name"`
 }
+
+// TransactionListFilter filter query GET /transaction (semua opsional)
+type TransactionListFilter struct {
+	PaymentStatus  string `form:"payment_status"`
+	OrderStatus    string `form:"order_status"`
+	PaymentMethod  string `form:"payment_method"`
+	OrderType      string `form:"order_type"`
+	DateFrom       string `form:"date_from"` // YYYY-MM-DD (inklusif)
+	DateTo         string `form:"date_to"`   // YYYY-MM-DD (inklusif)
+	ClosedByUserID string `form:"closed_by_user_id" binding:"omitempty,uuid"`
+	TableNumber    *int   `form:"table_number"`
+	Search         string `form:"search"` // nama / no. HP customer / nomor pesanan (mis. A-042)
+}
+
+// CreateReceiptTokenRequest body POST /transaction/:id/receipt-token (semua opsional)
+type CreateReceiptTokenRequest struct {
+	ExpiresInHours *int `json:"expires_in_hours" binding:"omitempty,min=1,max=8760"` // kosong = default env RECEIPT_TOKEN_TTL_DAYS
+}
+
+// ReceiptTokenResponse token struk digital beserta link publiknya
+type ReceiptTokenResponse struct {
+	Token     string  `json:"token"`
+	URL       string  `json:"url"` // kosong jika RECEIPT_PUBLIC_URL belum diatur
+	ExpiresAt *string `json:"expires_at"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// PublicReceiptResponse struk digital untuk pelanggan (GET /receipt/:token). Sengaja tanpa ID transaksi
+// dan nomor HP pelanggan karena link bisa dibagikan.
+type PublicReceiptResponse struct {
+	OrderNumber      string                   `json:"order_number"`
+	CreatedAt        string                   `json:"created_at"`
+	CustomerName     string                   `json:"customer_name"`
+	OrderType        string                   `json:"order_type"`
+	TableNumber      *int                     `json:"table_number,omitempty"`
+	Items            []ReceiptItemResponse    `json:"items"`
+	Subtotal         utils.Money              `json:"subtotal"`
+	Discount         utils.Money              `json:"discount"`
+	ServiceCharge    utils.Money              `json:"service_charge"`
+	Tax              utils.Money              `json:"tax"`
+	Taxes            []TransactionTaxResponse `json:"taxes"`
+	TotalAmount      utils.Money              `json:"total_amount"`
+	PaymentMethod    string                   `json:"payment_method"`
+	PaymentStatus    string                   `json:"payment_status"`
+	AmountTendered   utils.Money              `json:"amount_tendered"`
+	ChangeAmount     utils.Money              `json:"change_amount"`
+	ClosedByUserName string                   `json:"closed_by_user_name"`
+}
+
+// NowServingOrder satu pesanan yang siap diambil di layar antrian
+type NowServingOrder struct {
+	OrderNumber string `json:"order_number"`
+	OrderType   string `json:"order_type"`
+	ReadyAt     string `json:"ready_at"` // perkiraan waktu pesanan menjadi ready (updated_at)
+}
+
+// NowServingResponse response GET /queue/now-serving
+type NowServingResponse struct {
+	BusinessDate string            `json:"business_date"`
+	Ready        []NowServingOrder `json:"ready"`
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
 	"pos-go/config"
 	database "pos-go/database/migrations"
 	"pos-go/routes"
+	"pos-go/services"
 	"pos-go/utils"
+	"syscall"
+	"time"
+	_ "time/tzdata" // zona waktu toko tetap bisa dimuat walau image tidak punya tzdata
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -18,8 +26,19 @@ func main() {
 	// Inisialisasi Midtrans
 	config.InitMidtrans()
 
+	// Pengaturan toko (zona waktu & hari bisnis, ambang selisih settlement)
+	config.InitStore()
+
+	// Printer thermal struk & tiket dapur (ESC/POS)
+	config.InitPrinter()
+
 	// Migrasi seed database untuk admin awal
 	database.SeedAdmin()
+	database.SeedTaxRules()
+	database.BackfillPayments()
+	database.DropDateSettlementIndex()
+	database.BackfillSettlementRevisions()
+	database.BackfillTables()
 
 	// Set Gin mode (hilangkan debug mode warning) - HARUS SEBELUM gin.Default()
 	gin.SetMode(gin.ReleaseMode)
@@ -51,11 +70,53 @@ func main() {
 	routes.PromoRoutes(r)
 	routes.ReportRoutes(r)
 	routes.SettlementRoutes(r)
+	routes.ShiftRoutes(r)
+	routes.KitchenRoutes(r)
+	routes.TaxRoutes(r)
+	routes.IngredientRoutes(r)
+	routes.PurchaseRoutes(r)
+	routes.TableRoutes(r)
+	routes.ReceiptRoutes(r)
+	routes.QueueRoutes(r)
 
 	r.GET("/ping", func(c *gin.Context) {
 		utils.SuccessResponseOK(c, "API sukses berjalan", nil)
 	})
 
-	log.Println("Server berjalan di http://localhost:8080")
-	r.Run(":8080")
+	// Context yang dibatalkan saat SIGINT / SIGTERM (graceful shutdown)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Background worker: tandai transaksi non-cash yang lewat expired_at (jika webhook expire tidak datang)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		services.NewExpiryWorker(time.Minute).Run(ctx)
+	}()
+
+	srv := &http.Server{
+		Addr:    ":8080",
+		Handler: r,
+	}
+	// Tutup stream SSE dapur agar Shutdown tidak menunggu koneksi long-lived
+	srv.RegisterOnShutdown(services.OrderEvents.Close)
+
+	go func() {
+		log.Println("Server berjalan di http://localhost:8080")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server gagal berjalan:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Mematikan server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Shutdown server gagal:", err)
+	}
+	<-workerDone
+
+	log.Println("Server berhenti")
 }
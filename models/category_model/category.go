@@ -6,8 +6,9 @@ import (
 )
 
 type Category struct {
-	ID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Name string    `gorm:"unique;not null" json:"name"`
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Name    string    `gorm:"unique;not null" json:"name"`
+	Station string    `gorm:"type:varchar(50);not null;default:''" json:"station"` // stasiun dapur default untuk menu di kategori ini (mis. grill, drinks)
 	gorm.Model
 }
 
@@ -0,0 +1,73 @@
+package ingredient_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ingredient bahan baku dapur. Stok dalam satuan Unit (mis. gram, ml, pcs) dan boleh minus
+// (penjualan tidak diblokir; selisih terlihat di laporan pemakaian).
+type Ingredient struct {
+	ID                uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name              string         `gorm:"type:varchar(255);unique;not null" json:"name"`
+	Unit              string         `gorm:"type:varchar(20);not null" json:"unit"`
+	Stock             float64        `gorm:"type:decimal(15,3);not null;default:0" json:"stock"`
+	LowStockThreshold float64        `gorm:"type:decimal(15,3);not null;default:0" json:"low_stock_threshold"`
+	LastUnitCost      utils.Money    `gorm:"type:decimal(15,2);not null;default:0" json:"last_unit_cost"` // harga per unit dari pembelian terakhir
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate set UUID
+func (i *Ingredient) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// RecipeLine kebutuhan satu bahan untuk satu porsi menu (bill of materials)
+type RecipeLine struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	MenuID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_recipe_menu_ingredient" json:"menu_id"`
+	IngredientID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_recipe_menu_ingredient" json:"ingredient_id"`
+	Ingredient   Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+	Quantity     float64    `gorm:"type:decimal(15,3);not null" json:"quantity"` // per porsi, dalam satuan bahan
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (r *RecipeLine) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IngredientMovement catatan audit setiap perubahan stok bahan
+type IngredientMovement struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	IngredientID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"ingredient_id"`
+	IngredientName string     `gorm:"type:varchar(255);not null" json:"ingredient_name"` // snapshot
+	Type           string     `gorm:"type:varchar(20);not null;index" json:"type"`       // usage | purchase | waste | correction
+	Quantity       float64    `gorm:"type:decimal(15,3);not null" json:"quantity"`       // perubahan stok (negatif = berkurang)
+	StockAfter     float64    `gorm:"type:decimal(15,3);not null" json:"stock_after"`
+	TransactionID  *uuid.UUID `gorm:"type:uuid;index" json:"transaction_id,omitempty"` // untuk usage
+	PurchaseID     *uuid.UUID `gorm:"type:uuid;index" json:"purchase_id,omitempty"`    // untuk purchase
+	UserID         *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	Note           string     `gorm:"type:text" json:"note"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate set UUID
+func (m *IngredientMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
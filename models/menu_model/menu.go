@@ -2,20 +2,27 @@ package menu_model
 
 import (
 	category_model "pos-go/models/category_model"
+	"pos-go/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Menu struct {
-	ID          uuid.UUID               `gorm:"type:uuid;primaryKey" json:"id"`
-	Name        string                  `gorm:"not null" json:"name"`
-	Description string                  `json:"description"`
-	Price       float64                 `gorm:"not null" json:"price"`
-	Image       string                  `json:"image"` // URL atau path ke gambar
-	IsAvailable bool                    `json:"is_available"`
-	CategoryID  uuid.UUID               `gorm:"type:uuid;not null" json:"category_id"`
-	Category    category_model.Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	ID                uuid.UUID               `gorm:"type:uuid;primaryKey" json:"id"`
+	Name              string                  `gorm:"not null" json:"name"`
+	Description       string                  `json:"description"`
+	Price             utils.Money             `gorm:"type:decimal(15,2);not null" json:"price"`
+	Image             string                  `json:"image"` // URL atau path ke gambar
+	IsAvailable       bool                    `json:"is_available"`
+	TrackStock        bool                    `gorm:"not null;default:false" json:"track_stock"` // stok hanya dihitung jika true; berubah lewat transaksi & penyesuaian admin (stock_movements)
+	Stock             int                     `gorm:"not null;default:0" json:"stock"`
+	LowStockThreshold int                     `gorm:"not null;default:0" json:"low_stock_threshold"`
+	SoldOut           bool                    `gorm:"not null;default:false" json:"sold_out"`              // true jika is_available dimatikan otomatis karena stok habis
+	Station           string                  `gorm:"type:varchar(50);not null;default:''" json:"station"` // kosong = ikut stasiun kategori
+	CategoryID        uuid.UUID               `gorm:"type:uuid;not null" json:"category_id"`
+	Category          category_model.Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	ModifierGroups    []ModifierGroup         `gorm:"foreignKey:MenuID" json:"modifier_groups,omitempty"`
 	gorm.Model
 }
 
@@ -0,0 +1,52 @@
+package menu_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModifierGroup kelompok pilihan pada menu (mis. Ukuran, Level Pedas, Topping).
+// Jumlah pilihan yang boleh dipilih dibatasi MinSelect..MaxSelect; IsRequired memaksa minimal 1 pilihan.
+type ModifierGroup struct {
+	ID         uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	MenuID     uuid.UUID        `gorm:"type:uuid;not null;index" json:"menu_id"`
+	Name       string           `gorm:"type:varchar(100);not null" json:"name"`
+	IsRequired bool             `gorm:"not null;default:false" json:"is_required"`
+	MinSelect  int              `gorm:"not null;default:0" json:"min_select"`
+	MaxSelect  int              `gorm:"not null;default:1" json:"max_select"`
+	SortOrder  int              `gorm:"not null;default:0" json:"sort_order"`
+	Options    []ModifierOption `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"options"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (g *ModifierGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// ModifierOption satu pilihan dalam ModifierGroup. PriceDelta ditambahkan ke harga menu per porsi (boleh 0 / negatif).
+type ModifierOption struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	GroupID     uuid.UUID   `gorm:"type:uuid;not null;index" json:"group_id"`
+	Name        string      `gorm:"type:varchar(100);not null" json:"name"`
+	PriceDelta  utils.Money `gorm:"type:decimal(15,2);not null;default:0" json:"price_delta"`
+	IsAvailable bool        `gorm:"not null" json:"is_available"`
+	SortOrder   int         `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (o *ModifierOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package promo_model
 
 import (
+	"pos-go/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +13,16 @@ type Promo struct {
 	Code        string         `gorm:"type:varchar(50);unique;not null" json:"code"`
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
-	Type        string         `gorm:"type:varchar(20);not null" json:"type"` // "percentage" atau "fixed"
-	Value       float64        `gorm:"type:decimal(15,2);not null" json:"value"`
-	MinPurchase float64        `gorm:"type:decimal(15,2);default:0" json:"min_purchase"`
-	MaxDiscount float64        `gorm:"type:decimal(15,2);default:0" json:"max_discount"` // 0 = unlimited
+	Type        string         `gorm:"type:varchar(20);not null" json:"type"`    // "percentage" atau "fixed"
+	Value       float64        `gorm:"type:decimal(15,2);not null" json:"value"` // persen (percentage) atau nominal rupiah (fixed), lihat promoDiscount
+	MinPurchase utils.Money    `gorm:"type:decimal(15,2);default:0" json:"min_purchase"`
+	MaxDiscount utils.Money    `gorm:"type:decimal(15,2);default:0" json:"max_discount"` // 0 = unlimited
 	UsageLimit  int            `gorm:"type:int;default:0" json:"usage_limit"`            // 0 = unlimited
 	UsageCount  int            `gorm:"type:int;default:0" json:"usage_count"`
 	StartDate   time.Time      `gorm:"type:timestamp;not null" json:"start_date"`
 	EndDate     time.Time      `gorm:"type:timestamp;not null" json:"end_date"`
 	IsActive    bool           `gorm:"type:boolean;default:true" json:"is_active"`
+	Exhausted   bool           `gorm:"type:boolean;default:false" json:"exhausted"` // dinonaktifkan otomatis karena usage_limit tercapai
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
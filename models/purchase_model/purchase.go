@@ -0,0 +1,52 @@
+package purchase_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Purchase penerimaan barang (pembelian bahan baku dari supplier)
+type Purchase struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Supplier        string         `gorm:"type:varchar(255);not null" json:"supplier"`
+	InvoiceNumber   string         `gorm:"type:varchar(100)" json:"invoice_number"`
+	ReceivedAt      time.Time      `gorm:"not null;index" json:"received_at"`
+	TotalCost       utils.Money    `gorm:"type:decimal(15,2);not null;default:0" json:"total_cost"`
+	Notes           string         `gorm:"type:text" json:"notes"`
+	CreatedByUserID uuid.UUID      `gorm:"type:uuid;not null" json:"created_by_user_id"`
+	Items           []PurchaseItem `gorm:"foreignKey:PurchaseID;constraint:OnDelete:CASCADE" json:"items"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (p *Purchase) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PurchaseItem satu bahan yang diterima (snapshot nama + satuan)
+type PurchaseItem struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	PurchaseID     uuid.UUID   `gorm:"type:uuid;not null;index" json:"purchase_id"`
+	IngredientID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"ingredient_id"`
+	IngredientName string      `gorm:"type:varchar(255);not null" json:"ingredient_name"`
+	Unit           string      `gorm:"type:varchar(20);not null" json:"unit"`
+	Quantity       float64     `gorm:"type:decimal(15,3);not null" json:"quantity"`
+	UnitCost       utils.Money `gorm:"type:decimal(15,2);not null" json:"unit_cost"`
+	Subtotal       utils.Money `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// BeforeCreate set UUID
+func (i *PurchaseItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package refund_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Refund pengembalian dana atas transaksi yang sudah dibayar (full atau partial per item)
+type Refund struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Type             string         `gorm:"type:varchar(20);not null" json:"type"` // full | partial
+	Amount           utils.Money    `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Reason           string         `gorm:"type:text;not null" json:"reason"`
+	PaymentMethod    string         `gorm:"type:varchar(50);not null" json:"payment_method"` // snapshot metode bayar (tender yang direfund)
+	PaymentID        *uuid.UUID     `gorm:"type:uuid" json:"payment_id"`                     // payment yang direfund (nil untuk transaksi lama)
+	Status           string         `gorm:"type:varchar(20);not null" json:"status"`         // pending | succeeded | failed
+	ExternalRef      string         `gorm:"type:varchar(100)" json:"external_ref"`           // refund_key Midtrans (non-cash)
+	ApprovedByUserID uuid.UUID      `gorm:"type:uuid;not null" json:"approved_by_user_id"`   // admin yang menyetujui
+	Items            []RefundItem   `gorm:"foreignKey:RefundID;constraint:OnDelete:CASCADE" json:"items"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate set UUID
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RefundItem baris item yang dikembalikan (snapshot nama menu + qty + nominal)
+type RefundItem struct {
+	ID                uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	RefundID          uuid.UUID   `gorm:"type:uuid;not null;index" json:"refund_id"`
+	TransactionItemID uuid.UUID   `gorm:"type:uuid;not null" json:"transaction_item_id"`
+	MenuName          string      `gorm:"type:varchar(255);not null" json:"menu_name"`
+	Quantity          int         `gorm:"type:int;not null" json:"quantity"`
+	Amount            utils.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+// BeforeCreate set UUID
+func (ri *RefundItem) BeforeCreate(tx *gorm.DB) error {
+	if ri.ID == uuid.Nil {
+		ri.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package report_model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ZReport laporan tutup hari (Z-report) yang sudah ditutup. Nomor berurutan tanpa celah per toko dan isi laporan
+// disimpan sebagai snapshot JSON saat ditutup, sehingga cetak ulang selalu identik walau data transaksi berubah.
+// Baris tidak pernah diubah atau dihapus setelah dibuat.
+type ZReport struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Number         int       `gorm:"type:int;not null;uniqueIndex" json:"number"`
+	BusinessDate   time.Time `gorm:"type:date;not null;uniqueIndex" json:"business_date"`
+	ClosedByUserID uuid.UUID `gorm:"type:uuid;not null" json:"closed_by_user_id"`
+	ClosedAt       time.Time `gorm:"not null" json:"closed_at"`
+	Snapshot       string    `gorm:"type:jsonb;not null" json:"-"` // dto.ZReportResponse saat ditutup
+}
+
+// BeforeCreate set UUID
+func (z *ZReport) BeforeCreate(tx *gorm.DB) error {
+	if z.ID == uuid.Nil {
+		z.ID = uuid.New()
+	}
+	return nil
+}
@@ -1,23 +1,39 @@
 package settlement_model
 
 import (
+	"pos-go/utils"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// Settlement rekonsiliasi uang tunai saat kasir tutup kasir (per tanggal, per user)
+// Settlement rekonsiliasi uang tunai saat kasir tutup shift (satu per shift). Baris lama (sebelum shift) tidak punya shift_id.
+// Status: submitted (menunggu review admin) -> approved / rejected; settlement rejected bisa diajukan ulang kasir (revision naik).
 type Settlement struct {
-	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	Date          time.Time `gorm:"type:date;not null;uniqueIndex:idx_settlement_date_user" json:"date"` // tanggal tutup kasir
-	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_settlement_date_user" json:"user_id"`
-	ExpectedCash  float64   `gorm:"type:decimal(15,2);not null;default:0" json:"expected_cash"`  // dari sum transaksi tunai paid hari itu
-	ActualCash    float64   `gorm:"type:decimal(15,2);not null;default:0" json:"actual_cash"`    // input kasir
-	Discrepancy   float64   `gorm:"type:decimal(15,2);default:0" json:"discrepancy"`             // actual - expected
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uuid.UUID                `gorm:"type:uuid;primaryKey" json:"id"`
+	ShiftID          *uuid.UUID               `gorm:"type:uuid;uniqueIndex" json:"shift_id"`
+	Date             time.Time                `gorm:"type:date;not null;index" json:"date"` // tanggal shift dibuka
+	UserID           uuid.UUID                `gorm:"type:uuid;not null;index" json:"user_id"`
+	OpeningFloat     utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"opening_float"`
+	ExpectedCash     utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"expected_cash"`  // modal + tunai diterima - kembalian - refund tunai + pay-in - pay-out
+	ActualCash       utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"actual_cash"`    // input kasir
+	Discrepancy      utils.Money              `gorm:"type:decimal(15,2);default:0" json:"discrepancy"`             // actual - expected
+	TotalTendered    utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"total_tendered"` // uang tunai diterima dari pelanggan
+	TotalChange      utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"total_change"`   // kembalian yang diberikan
+	CashRefunds      utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"cash_refunds"`
+	PayIns           utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"pay_ins"`
+	PayOuts          utils.Money              `gorm:"type:decimal(15,2);not null;default:0" json:"pay_outs"`
+	Note             string                   `gorm:"type:text" json:"note"`
+	Status           string                   `gorm:"type:varchar(20);not null;default:'submitted';index" json:"status"` // submitted, approved, rejected
+	Revision         int                      `gorm:"type:int;not null;default:1" json:"revision"`
+	ReviewedByUserID *uuid.UUID               `gorm:"type:uuid" json:"reviewed_by_user_id"` // admin yang approve / reject
+	ReviewedAt       *time.Time               `json:"reviewed_at"`
+	ReviewNote       string                   `gorm:"type:text" json:"review_note"`
+	Denominations    []SettlementDenomination `gorm:"foreignKey:SettlementID;constraint:OnDelete:CASCADE" json:"denominations,omitempty"`
+	CreatedAt        time.Time                `json:"created_at"`
+	UpdatedAt        time.Time                `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt           `gorm:"index" json:"-"`
 }
 
 // BeforeCreate set UUID
@@ -27,3 +43,37 @@ func (s *Settlement) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// SettlementRevision riwayat setiap pengajuan settlement (pengajuan pertama dan setiap ajukan ulang setelah ditolak)
+// beserta hasil review admin-nya. Tidak pernah diubah kecuali kolom review saat admin memutuskan.
+type SettlementRevision struct {
+	ID                uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	SettlementID      uuid.UUID              `gorm:"type:uuid;not null;uniqueIndex:idx_settlement_revision" json:"settlement_id"`
+	Revision          int                    `gorm:"type:int;not null;uniqueIndex:idx_settlement_revision" json:"revision"`
+	ExpectedCash      utils.Money            `gorm:"type:decimal(15,2);not null;default:0" json:"expected_cash"`
+	ActualCash        utils.Money            `gorm:"type:decimal(15,2);not null;default:0" json:"actual_cash"`
+	Discrepancy       utils.Money            `gorm:"type:decimal(15,2);not null;default:0" json:"discrepancy"`
+	Denominations     []DenominationSnapshot `gorm:"type:jsonb;serializer:json" json:"denominations"`
+	Note              string                 `gorm:"type:text" json:"note"`
+	SubmittedByUserID uuid.UUID              `gorm:"type:uuid;not null" json:"submitted_by_user_id"`
+	Status            string                 `gorm:"type:varchar(20);not null;default:'submitted'" json:"status"` // submitted, approved, rejected
+	ReviewedByUserID  *uuid.UUID             `gorm:"type:uuid" json:"reviewed_by_user_id"`
+	ReviewedAt        *time.Time             `json:"reviewed_at"`
+	ReviewNote        string                 `gorm:"type:text" json:"review_note"`
+	CreatedAt         time.Time              `json:"created_at"`
+}
+
+// DenominationSnapshot rincian pecahan yang disimpan pada revisi settlement
+type DenominationSnapshot struct {
+	Denomination utils.Money `json:"denomination"`
+	Quantity     int         `json:"quantity"`
+	Subtotal     utils.Money `json:"subtotal"`
+}
+
+// BeforeCreate set UUID
+func (r *SettlementRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package settlement_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shift sesi kerja kasir: dibuka dengan modal awal (opening float) dan ditutup lewat settlement (hitung uang fisik).
+// Satu kasir hanya boleh punya satu shift open; shift malam boleh melewati tengah malam.
+type Shift struct {
+	ID            uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID        uuid.UUID           `gorm:"type:uuid;not null;index;uniqueIndex:idx_shift_user_open,where:status = 'open'" json:"user_id"`
+	Status        string              `gorm:"type:varchar(20);not null;default:'open';index" json:"status"` // open, closed
+	OpeningFloat  utils.Money         `gorm:"type:decimal(15,2);not null;default:0" json:"opening_float"`   // modal uang kembalian di laci saat buka
+	OpenedAt      time.Time           `gorm:"not null;index" json:"opened_at"`
+	ClosedAt      *time.Time          `json:"closed_at"`
+	Note          string              `gorm:"type:text" json:"note"`
+	CashMovements []ShiftCashMovement `gorm:"foreignKey:ShiftID;constraint:OnDelete:CASCADE" json:"cash_movements,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (s *Shift) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// ShiftCashMovement kas masuk / keluar di tengah shift di luar penjualan (mis. tambah modal, bayar galon, setor ke brankas)
+type ShiftCashMovement struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	ShiftID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"shift_id"`
+	Type      string      `gorm:"type:varchar(20);not null" json:"type"` // pay_in, pay_out
+	Amount    utils.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Reason    string      `gorm:"type:text;not null" json:"reason"`
+	UserID    uuid.UUID   `gorm:"type:uuid;not null" json:"user_id"` // yang mencatat
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// BeforeCreate set UUID
+func (m *ShiftCashMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// SettlementDenomination rincian hitung uang fisik per pecahan saat tutup shift
+type SettlementDenomination struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	SettlementID uuid.UUID   `gorm:"type:uuid;not null;index" json:"settlement_id"`
+	Denomination utils.Money `gorm:"type:decimal(15,2);not null" json:"denomination"` // mis. 100000, 500
+	Quantity     int         `gorm:"type:int;not null" json:"quantity"`
+	Subtotal     utils.Money `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+}
+
+// BeforeCreate set UUID
+func (d *SettlementDenomination) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
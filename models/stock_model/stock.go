@@ -0,0 +1,30 @@
+package stock_model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockMovement catatan audit setiap perubahan stok menu (penjualan, batal, waste, restock, koreksi)
+type StockMovement struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	MenuID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"menu_id"`
+	MenuName      string     `gorm:"type:varchar(255);not null" json:"menu_name"` // snapshot nama menu
+	Type          string     `gorm:"type:varchar(20);not null;index" json:"type"` // sale | sale_void | waste | restock | correction
+	Quantity      int        `gorm:"type:int;not null" json:"quantity"`           // perubahan stok (negatif = berkurang)
+	StockAfter    int        `gorm:"type:int;not null" json:"stock_after"`
+	TransactionID *uuid.UUID `gorm:"type:uuid;index" json:"transaction_id,omitempty"` // untuk sale / sale_void
+	UserID        *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`              // admin yang melakukan penyesuaian
+	Note          string     `gorm:"type:text" json:"note"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// BeforeCreate set UUID
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
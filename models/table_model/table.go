@@ -0,0 +1,29 @@
+package table_model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Table meja dine-in. Status: free, occupied (ada pesanan aktif), reserved, cleaning.
+type Table struct {
+	ID                   uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Number               int        `gorm:"type:int;not null;uniqueIndex" json:"number"`
+	Area                 string     `gorm:"type:varchar(50);not null;default:''" json:"area"` // mis. Indoor, Outdoor, Lantai 2
+	Capacity             int        `gorm:"type:int;not null;default:0" json:"capacity"`
+	Status               string     `gorm:"type:varchar(20);not null;default:'free'" json:"status"`
+	CurrentTransactionID *uuid.UUID `gorm:"type:uuid;index" json:"current_transaction_id"` // pesanan yang sedang menempati meja
+	OccupiedAt           *time.Time `json:"occupied_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate set UUID
+func (t *Table) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package tax_model
+
+import (
+	"time"
+
+	category_model "pos-go/models/category_model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxRule aturan pajak / service charge yang dikelola admin
+type TaxRule struct {
+	ID               uuid.UUID                 `gorm:"type:uuid;primaryKey" json:"id"`
+	Name             string                    `gorm:"type:varchar(100);not null" json:"name"`              // mis. "PPN", "Service Charge"
+	Type             string                    `gorm:"type:varchar(20);not null;default:'tax'" json:"type"` // tax | service_charge
+	Rate             float64                   `gorm:"type:decimal(7,4);not null" json:"rate"`              // persen, mis. 10 = 10%
+	IsInclusive      bool                      `gorm:"type:boolean;default:false" json:"is_inclusive"`      // true = harga menu sudah termasuk pajak ini
+	OrderType        string                    `gorm:"type:varchar(20);default:''" json:"order_type"`       // kosong = semua, dine_in, take_away
+	IsActive         bool                      `gorm:"type:boolean;default:true" json:"is_active"`
+	ExemptCategories []category_model.Category `gorm:"many2many:tax_rule_exempt_categories" json:"exempt_categories"` // kategori yang dibebaskan
+	CreatedAt        time.Time                 `json:"created_at"`
+	UpdatedAt        time.Time                 `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt            `gorm:"index" json:"-"`
+}
+
+// BeforeCreate set UUID
+func (t *TaxRule) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package transaction_model
+
+import "time"
+
+// OrderCounter penghitung nomor pesanan per hari bisnis. last_number dinaikkan secara atomik lewat
+// INSERT ... ON CONFLICT DO UPDATE ... RETURNING sehingga pesanan bersamaan tidak pernah mendapat nomor yang sama.
+type OrderCounter struct {
+	BusinessDate time.Time `gorm:"type:date;primaryKey" json:"business_date"`
+	LastNumber   int       `gorm:"type:int;not null;default:0" json:"last_number"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
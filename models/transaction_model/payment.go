@@ -0,0 +1,36 @@
+package transaction_model
+
+import (
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment satu pembayaran (tender) atas transaksi. Transaksi bisa dibayar beberapa kali (split bill / multi-tender);
+// payment_status transaksi menjadi paid saat jumlah payment yang paid menutup TotalAmount.
+type Payment struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID  uuid.UUID   `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Method         string      `gorm:"type:varchar(50);not null" json:"method"` // cash, credit_card, debit_card, e_wallet
+	Amount         utils.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Status         string      `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`    // pending, paid, failed, expired, cancelled
+	Label          string      `gorm:"type:varchar(100)" json:"label"`                               // mis. "Tamu 1" (split bill)
+	ExternalRef    string      `gorm:"type:varchar(64);index" json:"external_ref"`                   // order_id Midtrans (non-cash)
+	CashierUserID  *uuid.UUID  `gorm:"type:uuid" json:"cashier_user_id"`                             // kasir yang menerima / mencatat pembayaran
+	AmountTendered utils.Money `gorm:"type:decimal(15,2);not null;default:0" json:"amount_tendered"` // uang yang diserahkan pelanggan (tunai)
+	ChangeAmount   utils.Money `gorm:"type:decimal(15,2);not null;default:0" json:"change_amount"`   // kembalian = amount_tendered - amount
+	RefundedAmount utils.Money `gorm:"type:decimal(15,2);not null;default:0" json:"refunded_amount"`
+	PaidAt         *time.Time  `gorm:"type:timestamp" json:"paid_at"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package transaction_model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReceiptToken token acak untuk struk digital publik (GET /receipt/:token). Token tidak bisa ditebak dan tidak
+// mengandung ID transaksi; bisa dicabut (revoked_at) dan opsional kedaluwarsa (expires_at).
+type ReceiptToken struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Token           string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token"`
+	ExpiresAt       *time.Time `json:"expires_at"` // null = tidak kedaluwarsa
+	RevokedAt       *time.Time `json:"revoked_at"`
+	CreatedByUserID *uuid.UUID `gorm:"type:uuid" json:"created_by_user_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (rt *ReceiptToken) BeforeCreate(tx *gorm.DB) error {
+	if rt.ID == uuid.Nil {
+		rt.ID = uuid.New()
+	}
+	return nil
+}
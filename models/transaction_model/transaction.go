@@ -1,6 +1,7 @@
 package transaction_model
 
 import (
+	"pos-go/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,26 +10,35 @@ import (
 
 // Transaction represents an order/transaction
 type Transaction struct {
-	ID            uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
-	CustomerName  string            `gorm:"type:varchar(255);not null" json:"customer_name"`
-	CustomerPhone string            `gorm:"type:varchar(20);not null" json:"customer_phone"`
-	OrderType     string            `gorm:"type:varchar(20);not null;default:'take_away'" json:"order_type"` // dine_in | take_away
-	TableNumber   *int              `gorm:"type:int" json:"table_number"`
-	PromoCode     string            `gorm:"type:varchar(50)" json:"promo_code"`
-	Discount      float64           `gorm:"type:decimal(15,2);default:0" json:"discount"`
-	Subtotal      float64           `gorm:"type:decimal(15,2);not null;default:0" json:"subtotal"`             // Total sebelum pajak
-	Tax           float64           `gorm:"type:decimal(15,2);not null;default:0" json:"tax"`                  // Pajak (PPN 10%)
-	TotalAmount   float64           `gorm:"type:decimal(15,2);not null;default:0" json:"total_amount"`         // Total setelah pajak
-	PaymentMethod string            `gorm:"type:varchar(50);not null" json:"payment_method"`                   // cash, credit_card, debit_card, e_wallet
-	PaymentStatus string            `gorm:"type:varchar(50);not null;default:'pending'" json:"payment_status"` // pending, paid, cancelled, expired
-	OrderStatus     string            `gorm:"type:varchar(50);not null;default:'pending'" json:"order_status"`   // pending, processing, completed, cancelled
-	ClosedByUserID  *uuid.UUID        `gorm:"type:uuid" json:"closed_by_user_id"`                                // kasir yang memproses (konfirmasi tunai / tandai selesai)
-	Notes           string            `gorm:"type:text" json:"notes"`
-	ExpiredAt       *time.Time        `gorm:"type:timestamp" json:"expired_at"` // Waktu kadaluarsa untuk non-cash payment
-	Items           []TransactionItem `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"items"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt     time.Time         `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt    `gorm:"index" json:"-"`
+	ID                  uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderNumber         string            `gorm:"type:varchar(16);not null;default:'';uniqueIndex:idx_transactions_order_number" json:"order_number"` // nomor antrian per hari bisnis, mis. A-042
+	BusinessDate        *time.Time        `gorm:"type:date;uniqueIndex:idx_transactions_order_number" json:"-"`                                       // hari bisnis saat nomor pesanan dibuat
+	CustomerName        string            `gorm:"type:varchar(255);not null" json:"customer_name"`
+	CustomerPhone       string            `gorm:"type:varchar(20);not null" json:"customer_phone"`
+	OrderType           string            `gorm:"type:varchar(20);not null;default:'take_away'" json:"order_type"` // dine_in | take_away
+	TableNumber         *int              `gorm:"type:int" json:"table_number"`
+	PromoCode           string            `gorm:"type:varchar(50)" json:"promo_code"`
+	Discount            utils.Money       `gorm:"type:decimal(15,2);default:0" json:"discount"`
+	Subtotal            utils.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"subtotal"`             // Total sebelum pajak
+	ServiceCharge       utils.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"service_charge"`       // Service charge (dine in)
+	Tax                 utils.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"tax"`                  // Total pajak (inklusif + eksklusif)
+	TotalAmount         utils.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"total_amount"`         // Total setelah pajak
+	RefundedAmount      utils.Money       `gorm:"type:decimal(15,2);not null;default:0" json:"refunded_amount"`      // Total refund yang sudah diproses
+	PaymentMethod       string            `gorm:"type:varchar(50);not null" json:"payment_method"`                   // cash, credit_card, debit_card, e_wallet, split (multi-tender)
+	PaymentStatus       string            `gorm:"type:varchar(50);not null;default:'pending'" json:"payment_status"` // pending, paid, cancelled, expired, partially_refunded, refunded
+	OrderStatus         string            `gorm:"type:varchar(50);not null;default:'pending'" json:"order_status"`   // pending, processing, completed, cancelled
+	ClosedByUserID      *uuid.UUID        `gorm:"type:uuid" json:"closed_by_user_id"`                                // kasir yang memproses (konfirmasi tunai / tandai selesai)
+	Notes               string            `gorm:"type:text" json:"notes"`
+	ExpiredAt           *time.Time        `gorm:"type:timestamp" json:"expired_at"`                    // Waktu kadaluarsa untuk non-cash payment
+	IsOpenBill          bool              `gorm:"not null;default:false" json:"is_open_bill"`          // open bill dine-in: item masih bisa ditambah, dibayar sekali di akhir
+	MidtransOrderID     string            `gorm:"type:varchar(64)" json:"midtrans_order_id,omitempty"` // order_id Snap jika berbeda dari id (open bill yang ditutup)
+	IngredientsDeducted bool              `gorm:"not null;default:false" json:"-"`                     // stok bahan sudah dipotong (saat dibayar), mencegah potong ganda
+	Items               []TransactionItem `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"items"`
+	Taxes               []TransactionTax  `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"taxes"`
+	Payments            []Payment         `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"payments,omitempty"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt    `gorm:"index" json:"-"`
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
@@ -41,16 +51,26 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 
 // TransactionItem represents items in a transaction
 type TransactionItem struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	TransactionID uuid.UUID      `gorm:"type:uuid;not null" json:"transaction_id"`
-	MenuID        uuid.UUID      `gorm:"type:uuid;not null" json:"menu_id"`
-	MenuName      string         `gorm:"type:varchar(255);not null" json:"menu_name"`
-	MenuPrice     float64        `gorm:"type:decimal(15,2);not null" json:"menu_price"`
-	Quantity      int            `gorm:"type:int;not null" json:"quantity"`
-	Subtotal      float64        `gorm:"type:decimal(15,2);not null" json:"subtotal"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uuid.UUID                 `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID    uuid.UUID                 `gorm:"type:uuid;not null" json:"transaction_id"`
+	MenuID           uuid.UUID                 `gorm:"type:uuid;not null" json:"menu_id"`
+	MenuName         string                    `gorm:"type:varchar(255);not null" json:"menu_name"`
+	MenuPrice        utils.Money               `gorm:"type:decimal(15,2);not null" json:"menu_price"`
+	ModifierPrice    utils.Money               `gorm:"type:decimal(15,2);not null;default:0" json:"modifier_price"` // total price_delta modifier per porsi
+	Quantity         int                       `gorm:"type:int;not null" json:"quantity"`
+	Subtotal         utils.Money               `gorm:"type:decimal(15,2);not null" json:"subtotal"`
+	Discount         utils.Money               `gorm:"type:decimal(15,2);not null;default:0" json:"discount"`       // porsi diskon promo untuk baris ini
+	ServiceCharge    utils.Money               `gorm:"type:decimal(15,2);not null;default:0" json:"service_charge"` // service charge baris ini
+	TaxAmount        utils.Money               `gorm:"type:decimal(15,2);not null;default:0" json:"tax_amount"`     // total pajak baris ini
+	RefundedQuantity int                       `gorm:"type:int;not null;default:0" json:"refunded_quantity"`
+	Batch            int                       `gorm:"type:int;not null;default:1" json:"batch"`                   // ronde pesanan (open bill): 1 = pesanan awal
+	Notes            string                    `gorm:"type:varchar(255)" json:"notes"`                             // catatan per item untuk dapur (mis. "tanpa bawang")
+	Station          string                    `gorm:"type:varchar(50);not null;default:'kitchen'" json:"station"` // stasiun dapur (snapshot saat dipesan)
+	Status           string                    `gorm:"type:varchar(20);not null;default:'queued'" json:"status"`   // queued, cooking, ready
+	Modifiers        []TransactionItemModifier `gorm:"foreignKey:TransactionItemID;constraint:OnDelete:CASCADE" json:"modifiers"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	UpdatedAt        time.Time                 `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt            `gorm:"index" json:"-"`
 }
 
 // BeforeCreate will set a UUID rather than numeric ID
@@ -60,3 +80,46 @@ func (ti *TransactionItem) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// TransactionItemModifier snapshot pilihan modifier pada item transaksi (nama & harga saat dipesan)
+type TransactionItemModifier struct {
+	ID                uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionItemID uuid.UUID   `gorm:"type:uuid;not null;index" json:"transaction_item_id"`
+	ModifierGroupID   uuid.UUID   `gorm:"type:uuid;not null" json:"modifier_group_id"`
+	GroupName         string      `gorm:"type:varchar(100);not null" json:"group_name"`
+	ModifierOptionID  uuid.UUID   `gorm:"type:uuid;not null" json:"modifier_option_id"`
+	OptionName        string      `gorm:"type:varchar(100);not null" json:"option_name"`
+	PriceDelta        utils.Money `gorm:"type:decimal(15,2);not null;default:0" json:"price_delta"`
+	SortOrder         int         `gorm:"not null;default:0" json:"-"` // urutan tampil (sesuai urutan grup di menu)
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *TransactionItemModifier) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// TransactionTax rincian per aturan pajak / service charge pada transaksi (snapshot saat transaksi dibuat)
+type TransactionTax struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	TransactionID uuid.UUID   `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	TaxRuleID     *uuid.UUID  `gorm:"type:uuid" json:"tax_rule_id"`
+	Name          string      `gorm:"type:varchar(100);not null" json:"name"`
+	Type          string      `gorm:"type:varchar(20);not null" json:"type"` // tax | service_charge
+	Rate          float64     `gorm:"type:decimal(7,4);not null" json:"rate"`
+	IsInclusive   bool        `json:"is_inclusive"`
+	TaxableAmount utils.Money `gorm:"type:decimal(15,2);not null" json:"taxable_amount"`
+	Amount        utils.Money `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (tt *TransactionTax) BeforeCreate(tx *gorm.DB) error {
+	if tt.ID == uuid.Nil {
+		tt.ID = uuid.New()
+	}
+	return nil
+}
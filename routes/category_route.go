@@ -16,5 +16,6 @@ func CategoryRoutes(r *gin.Engine) {
 		// Admin only endpoints
 		category.POST("", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.CreateCategory)
 		category.GET("", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetAllCategories)
+		category.PUT("/:id", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.UpdateCategory)
 	}
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func IngredientRoutes(r *gin.Engine) {
+	ingredient := r.Group("/ingredient")
+	ingredient.Use(middleware.AuthMiddleware())
+	{
+		// Admin only - CRUD bahan baku
+		ingredient.POST("", middleware.RequireRole("admin"), controllers.CreateIngredient)
+		ingredient.GET("", middleware.RequireRole("admin"), controllers.GetAllIngredients)
+		ingredient.PUT("/:id", middleware.RequireRole("admin"), controllers.UpdateIngredient)
+		ingredient.DELETE("/:id", middleware.RequireRole("admin"), controllers.DeleteIngredient)
+
+		// Admin only - stok bahan (stok menipis, waste / stock opname, riwayat)
+		ingredient.GET("/low-stock", middleware.RequireRole("admin"), controllers.GetLowStockIngredients)
+		ingredient.POST("/:id/adjust", middleware.RequireRole("admin"), controllers.AdjustIngredient)
+		ingredient.GET("/:id/movements", middleware.RequireRole("admin"), controllers.GetIngredientMovements)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func KitchenRoutes(r *gin.Engine) {
+	kitchen := r.Group("/kitchen")
+	kitchen.Use(middleware.AuthMiddleware())
+	{
+		// GET /kitchen/stream — SSE pesanan untuk layar dapur (koki) & kasir. Filter per role.
+		kitchen.GET("/stream", middleware.RequireRole("koki", "kasir", "admin"), controllers.KitchenStream)
+	}
+}
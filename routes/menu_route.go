@@ -24,5 +24,18 @@ func MenuRoutes(r *gin.Engine) {
 
 		// Admin only - delete menu
 		menu.DELETE("/:id", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.DeleteMenu)
+
+		// Admin only - stok menu (penyesuaian, riwayat, stok menipis)
+		menu.GET("/low-stock", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetLowStockMenus)
+		menu.POST("/:id/stock", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.AdjustStock)
+		menu.GET("/:id/stock-movements", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetStockMovements)
+
+		// Admin only - grup modifier menu (ukuran, level pedas, topping)
+		menu.GET("/:id/modifiers", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetModifierGroups)
+		menu.PUT("/:id/modifiers", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.SetModifierGroups)
+
+		// Admin only - resep menu (bahan baku per porsi)
+		menu.GET("/:id/recipe", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetRecipe)
+		menu.PUT("/:id/recipe", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.SetRecipe)
 	}
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PurchaseRoutes(r *gin.Engine) {
+	purchase := r.Group("/purchase")
+	purchase.Use(middleware.AuthMiddleware())
+	{
+		// Admin only - penerimaan barang (pembelian bahan baku)
+		purchase.POST("", middleware.RequireRole("admin"), controllers.CreatePurchase)
+		purchase.GET("", middleware.RequireRole("admin"), controllers.GetAllPurchases)
+		purchase.GET("/:id", middleware.RequireRole("admin"), controllers.GetPurchaseByID)
+	}
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"pos-go/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func QueueRoutes(r *gin.Engine) {
+	queue := r.Group("/queue")
+	{
+		// Public - GET /queue/now-serving — nomor pesanan yang siap diambil (layar antrian pelanggan)
+		queue.GET("/now-serving", controllers.GetNowServing)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"pos-go/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReceiptRoutes(r *gin.Engine) {
+	receipt := r.Group("/receipt")
+	{
+		// Public - GET /receipt/:token — struk digital untuk pelanggan (HTML, atau JSON dengan ?format=json).
+		// Hanya lewat token acak; endpoint /transaction/:id tetap butuh login.
+		receipt.GET("/:token", controllers.GetPublicReceipt)
+	}
+}
@@ -12,9 +12,20 @@ func ReportRoutes(r *gin.Engine) {
 	report.Use(middleware.AuthMiddleware())
 	{
 		// GET /report?date=2026-01-30 — laporan harian (summary + list transaksi). Admin & Kasir.
+		// GET /report?from=2026-01-01&to=2026-01-31 — laporan periode dengan rincian per menu, kategori, jam, dll. Admin & Kasir.
+		// Tambah &format=csv|xlsx untuk unduh list transaksi + rincian item.
 		report.GET("", middleware.RequireRole("admin", "kasir"), controllers.GetReportByDate)
 
 		// GET /report/charts?days=7&months=6 — data grafik harian & bulanan. Admin.
 		report.GET("/charts", middleware.RequireRole("admin"), controllers.GetReportCharts)
+
+		// GET /report/z?date=2026-01-30 — Z-report (PDF, &format=json untuk data). Admin.
+		report.GET("/z", middleware.RequireRole("admin"), controllers.GetZReport)
+
+		// POST /report/z/close — tutup hari, Z-report diberi nomor & dikunci. Body: {"date": "2026-01-30"}. Admin.
+		report.POST("/z/close", middleware.RequireRole("admin"), controllers.CloseZReport)
+
+		// GET /report/ingredient-usage?date_from=2026-01-01&date_to=2026-01-31 — pemakaian bahan teoritis vs aktual. Admin.
+		report.GET("/ingredient-usage", middleware.RequireRole("admin"), controllers.GetIngredientUsageReport)
 	}
 }
@@ -11,11 +11,19 @@ func SettlementRoutes(r *gin.Engine) {
 	settlement := r.Group("/settlement")
 	settlement.Use(middleware.AuthMiddleware())
 	{
-		// GET /settlement/status-by-date?date= — admin only. Status settlement per kasir.
+		// GET /settlement/status-by-date?date= — admin only. Status settlement per shift (&format=csv|xlsx untuk unduh).
 		settlement.GET("/status-by-date", middleware.RequireRole("admin"), controllers.GetSettlementStatusByDate)
-		// GET /settlement?date=YYYY-MM-DD — expected_cash + settlement (jika sudah ada). Kasir & Admin.
+		// GET /settlement?date=YYYY-MM-DD — shift user yang login di tanggal tersebut (rekap tunai + settlement). Kasir & Admin.
 		settlement.GET("", middleware.RequireRole("admin", "kasir"), controllers.GetSettlement)
-		// POST /settlement — simpan settlement (tutup kasir). Kasir & Admin.
+		// GET /settlement/discrepancies — admin only. Selisih settlement yang belum di-approve (lintas tanggal).
+		settlement.GET("/discrepancies", middleware.RequireRole("admin"), controllers.GetSettlementDiscrepancies)
+		// GET /settlement/:id — detail + riwayat revisi. Kasir (miliknya sendiri) & Admin.
+		settlement.GET("/:id", middleware.RequireRole("admin", "kasir"), controllers.GetSettlementDetail)
+		// PUT /settlement/:id — ajukan ulang settlement yang ditolak. Kasir & Admin (pemilik settlement).
+		settlement.PUT("/:id", middleware.RequireRole("admin", "kasir"), controllers.ResubmitSettlement)
+		// PATCH /settlement/:id/review — approve / reject settlement. Admin only.
+		settlement.PATCH("/:id/review", middleware.RequireRole("admin"), controllers.ReviewSettlement)
+		// POST /settlement — tutup shift yang sedang open (hitung uang + rincian pecahan). Kasir & Admin.
 		settlement.POST("", middleware.RequireRole("admin", "kasir"), controllers.CreateSettlement)
 	}
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ShiftRoutes(r *gin.Engine) {
+	shift := r.Group("/shift")
+	shift.Use(middleware.AuthMiddleware())
+	{
+		// POST /shift/open — buka shift dengan modal awal. Tutup shift lewat POST /settlement.
+		shift.POST("/open", middleware.RequireRole("admin", "kasir"), controllers.OpenShift)
+		// GET /shift/current — shift yang sedang open + rekap tunai sampai saat ini
+		shift.GET("/current", middleware.RequireRole("admin", "kasir"), controllers.GetCurrentShift)
+		// POST /shift/current/cash-movements — pay-in / pay-out di tengah shift
+		shift.POST("/current/cash-movements", middleware.RequireRole("admin", "kasir"), controllers.AddShiftCashMovement)
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TableRoutes(r *gin.Engine) {
+	table := r.Group("/table")
+	table.Use(middleware.AuthMiddleware())
+	{
+		// Kasir & Admin - denah meja (status semua meja + pesanan yang menempati)
+		table.GET("/floor", middleware.RequireRole("kasir", "admin"), controllers.GetFloor)
+
+		// Kasir & Admin - ubah status meja manual (free / reserved / cleaning)
+		table.PATCH("/:id/status", middleware.RequireRole("kasir", "admin"), controllers.SetTableStatus)
+
+		// Admin only - CRUD meja
+		table.POST("", middleware.RequireRole("admin"), controllers.CreateTable)
+		table.GET("", middleware.RequireRole("admin"), controllers.GetAllTables)
+		table.PUT("/:id", middleware.RequireRole("admin"), controllers.UpdateTable)
+		table.DELETE("/:id", middleware.RequireRole("admin"), controllers.DeleteTable)
+	}
+}
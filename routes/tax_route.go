@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"pos-go/controllers"
+	"pos-go/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TaxRoutes(r *gin.Engine) {
+	tax := r.Group("/tax")
+	{
+		// Public - aturan pajak aktif untuk estimasi di checkout
+		tax.GET("/active", controllers.GetActiveTaxRules)
+
+		// Admin only - CRUD aturan pajak / service charge
+		tax.POST("", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.CreateTaxRule)
+		tax.GET("", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.GetAllTaxRules)
+		tax.PUT("/:id", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.UpdateTaxRule)
+		tax.DELETE("/:id", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.DeleteTaxRule)
+	}
+}
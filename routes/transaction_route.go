@@ -16,21 +16,50 @@ func TransactionRoutes(r *gin.Engine) {
 		// Public - webhook dari Midtrans (PENTING!)
 		transaction.POST("/notification", controllers.HandleMidtransNotification)
 
-		// Admin & Kasir - lihat semua transaksi
+		// Admin & Kasir - lihat semua transaksi (?format=csv|xlsx untuk unduh)
 		transaction.GET("", middleware.AuthMiddleware(), controllers.GetAllTransactions)
 
 		// Kasir & Admin - data struk untuk print (harus sebelum /:id agar path .../receipt tidak tertangkap sebagai :id)
 		transaction.GET("/:id/receipt", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.GetTransactionReceipt)
+		transaction.POST("/:id/receipt/print", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.PrintTransactionReceipt)
+
+		// Kasir & Admin - link struk digital (token publik untuk GET /receipt/:token) dan pencabutannya
+		transaction.POST("/:id/receipt-token", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.CreateReceiptToken)
+		transaction.DELETE("/:id/receipt-token", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.RevokeReceiptTokens)
+
+		// Kasir, Koki & Admin - tiket dapur ESC/POS (unduh / cetak ke printer dapur)
+		transaction.GET("/:id/kitchen-ticket", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "koki", "admin"), controllers.GetKitchenTicket)
+		transaction.POST("/:id/kitchen-ticket/print", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "koki", "admin"), controllers.PrintKitchenTicket)
 
 		// Admin & Kasir - lihat detail transaksi
 		transaction.GET("/:id", middleware.AuthMiddleware(), controllers.GetTransactionByID)
 
-		// Kasir (atau Admin) - konfirmasi pembayaran tunai
+		// Kasir (atau Admin) - konfirmasi pembayaran tunai (sisa tagihan)
 		transaction.PATCH("/:id/cash-paid", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.ConfirmCashPaid)
 
 		// Kasir, Koki, atau Admin - update order_status dengan aturan per role
 		transaction.PATCH("/:id/order-status", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "koki", "admin"), controllers.UpdateOrderStatus)
 
+		// Koki atau Admin - update status satu item (per stasiun dapur); order_status mengikuti status semua item
+		transaction.PATCH("/:id/items/:itemId/status", middleware.AuthMiddleware(), middleware.RequireRole("koki", "admin"), controllers.UpdateItemStatus)
+
+		// Kasir atau Admin - open bill: tambah ronde item, hapus item yang belum dimasak, tutup bill untuk dibayar
+		transaction.POST("/:id/items", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.AddOpenBillItems)
+		transaction.DELETE("/:id/items/:itemId", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.RemoveOpenBillItem)
+		transaction.POST("/:id/close-bill", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.CloseBill)
+
+		// Kasir atau Admin - split bill / multi-tender: pratinjau pembagian, catat tender, lihat & batalkan tender pending
+		transaction.POST("/:id/split", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.SplitBill)
+		transaction.POST("/:id/payments", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.CreatePayment)
+		transaction.GET("/:id/payments", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.GetPayments)
+		transaction.DELETE("/:id/payments/:paymentId", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.CancelPayment)
+
+		// Admin - refund full / partial transaksi yang sudah dibayar
+		transaction.POST("/:id/refund", middleware.AuthMiddleware(), middleware.RequireRole("admin"), controllers.CreateRefund)
+
+		// Kasir & Admin - riwayat refund transaksi
+		transaction.GET("/:id/refunds", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.GetRefunds)
+
 		// Kasir atau Admin - batalkan pesanan (pending/cooking/ready -> cancelled)
 		transaction.PATCH("/:id/cancel", middleware.AuthMiddleware(), middleware.RequireRole("kasir", "admin"), controllers.CancelOrder)
 	}
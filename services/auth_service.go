@@ -6,6 +6,7 @@ import (
 	"pos-go/dto"
 	user_model "pos-go/models/user_model"
 	"pos-go/utils"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -28,7 +29,7 @@ type AuthService interface {
 	Login(input dto.LoginDTO) (user_model.User, string, error)
 	ChangePassword(userID string, input dto.ChangePasswordDTO) error
 	GetCurrentUser(userID string) (user_model.User, error)
-	GetAllUsers() ([]user_model.User, error)
+	GetAllUsers(filter dto.UserListFilter, page utils.PageQuery) ([]user_model.User, int64, error)
 	DeleteUser(userID string) error
 }
 
@@ -143,15 +144,31 @@ func (s *authService) GetCurrentUser(userID string) (user_model.User, error) {
 	return user, nil
 }
 
-// GetAllUsers mengambil semua user non-admin (kasir & koki) untuk admin
-func (s *authService) GetAllUsers() ([]user_model.User, error) {
+// UserSortColumns kolom yang boleh dipakai untuk sort_by pada GET /user
+var UserSortColumns = map[string]string{
+	"created_at": "created_at",
+	"name":       "name",
+	"email":      "email",
+}
+
+// GetAllUsers mengambil user non-admin (kasir & koki) untuk admin (dengan filter + paginasi)
+func (s *authService) GetAllUsers(filter dto.UserListFilter, page utils.PageQuery) ([]user_model.User, int64, error) {
 	var users []user_model.User
-	if err := config.DB.
-		Where("role IN ?", []string{"kasir", "koki"}).
-		Find(&users).Error; err != nil {
-		return nil, ErrGetUsersFailed
+	q := config.DB.Model(&user_model.User{}).
+		Where("role IN ?", []string{"kasir", "koki"})
+	if filter.Role != "" {
+		q = q.Where("role = ?", filter.Role)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := utils.ContainsPattern(search)
+		q = q.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", like, like)
+	}
+
+	total, err := utils.Paginate(q, page, &users)
+	if err != nil {
+		return nil, 0, ErrGetUsersFailed
 	}
-	return users, nil
+	return users, total, nil
 }
 
 // DeleteUser menghapus user berdasarkan ID
@@ -6,6 +6,7 @@ import (
 	"pos-go/dto"
 	category_model "pos-go/models/category_model"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -13,11 +14,13 @@ import (
 var (
 	ErrCategoryNameExists   = errors.New("Nama category sudah digunakan")
 	ErrCreateCategoryFailed = errors.New("Gagal membuat category")
+	ErrUpdateCategoryFailed = errors.New("Gagal mengupdate category")
 )
 
 type CategoryService interface {
 	CreateCategory(input dto.CreateCategoryDTO) (category_model.Category, error)
 	GetAllCategories() ([]category_model.Category, error)
+	UpdateCategory(categoryID string, input dto.UpdateCategoryDTO) (category_model.Category, error)
 }
 
 type categoryService struct{}
@@ -39,7 +42,8 @@ func (s *categoryService) CreateCategory(input dto.CreateCategoryDTO) (category_
 
 	// Buat category baru
 	category := category_model.Category{
-		Name: input.Name,
+		Name:    input.Name,
+		Station: NormalizeStation(input.Station),
 	}
 
 	// Simpan ke database
@@ -59,3 +63,38 @@ func (s *categoryService) GetAllCategories() ([]category_model.Category, error)
 
 	return categories, nil
 }
+
+// UpdateCategory mengupdate nama / stasiun dapur category (partial update)
+func (s *categoryService) UpdateCategory(categoryID string, input dto.UpdateCategoryDTO) (category_model.Category, error) {
+	id, err := uuid.Parse(categoryID)
+	if err != nil {
+		return category_model.Category{}, ErrCategoryNotFound
+	}
+
+	var category category_model.Category
+	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return category_model.Category{}, ErrCategoryNotFound
+		}
+		return category_model.Category{}, ErrUpdateCategoryFailed
+	}
+
+	if input.Name != "" {
+		var existingCategory category_model.Category
+		if err := config.DB.Where("name = ? AND id != ?", input.Name, id).First(&existingCategory).Error; err == nil {
+			return category_model.Category{}, ErrCategoryNameExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return category_model.Category{}, ErrUpdateCategoryFailed
+		}
+		category.Name = input.Name
+	}
+	if input.Station != nil {
+		category.Station = NormalizeStation(*input.Station)
+	}
+
+	if err := config.DB.Save(&category).Error; err != nil {
+		return category_model.Category{}, ErrUpdateCategoryFailed
+	}
+
+	return category, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	transaction_model "pos-go/models/transaction_model"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis event pesanan
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventItemStatusChanged  = "order.item_status_changed" // status item berubah tanpa mengubah order_status
+	EventOrderItemsAdded    = "order.items_added"         // ronde baru open bill; items hanya berisi item ronde tersebut
+	EventOrderItemRemoved   = "order.item_removed"        // item open bill dihapus sebelum dimasak; items berisi item yang dihapus
+)
+
+// OrderEvent payload event pesanan untuk layar dapur / kasir
+type OrderEvent struct {
+	Type                string           `json:"type"`
+	TransactionID       uuid.UUID        `json:"transaction_id"`
+	OrderNumber         string           `json:"order_number"`
+	CustomerName        string           `json:"customer_name"`
+	OrderType           string           `json:"order_type"`
+	TableNumber         *int             `json:"table_number,omitempty"`
+	PaymentStatus       string           `json:"payment_status"`
+	OrderStatus         string           `json:"order_status"`
+	PreviousOrderStatus string           `json:"previous_order_status,omitempty"`
+	Notes               string           `json:"notes,omitempty"`
+	Items               []OrderEventItem `json:"items,omitempty"`
+	OccurredAt          time.Time        `json:"occurred_at"`
+}
+
+// OrderEventItem item pesanan dalam event
+type OrderEventItem struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	MenuName  string    `json:"menu_name"`
+	Quantity  int       `json:"quantity"`
+	Modifiers []string  `json:"modifiers,omitempty"` // mis. ["Large", "Extra Keju", "Kurang Pedas"]
+	Notes     string    `json:"notes,omitempty"`
+	Station   string    `json:"station"`
+	Status    string    `json:"status"`
+	Batch     int       `json:"batch"`
+}
+
+// EventHub pub/sub event pesanan. Implementasi saat ini in-process (memoryHub);
+// bisa diganti implementasi lain (mis. Postgres LISTEN/NOTIFY) tanpa mengubah pemanggil.
+type EventHub interface {
+	Publish(event OrderEvent)
+	// Subscribe mengembalikan channel event dan fungsi untuk berhenti berlangganan.
+	Subscribe() (<-chan OrderEvent, func())
+	// Close menutup semua channel subscriber (dipanggil saat server shutdown).
+	Close()
+}
+
+// OrderEvents hub default yang dipakai TransactionService dan stream dapur
+var OrderEvents EventHub = NewMemoryHub(32)
+
+type memoryHub struct {
+	mu     sync.RWMutex
+	subs   map[chan OrderEvent]struct{}
+	buffer int
+	closed bool
+}
+
+// NewMemoryHub membuat hub in-process; buffer = kapasitas antrian per subscriber.
+func NewMemoryHub(buffer int) EventHub {
+	return &memoryHub{subs: make(map[chan OrderEvent]struct{}), buffer: buffer}
+}
+
+// Publish mengirim event ke semua subscriber tanpa blocking; subscriber yang antriannya penuh dilewati.
+func (h *memoryHub) Publish(event OrderEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *memoryHub) Subscribe() (<-chan OrderEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan OrderEvent, h.buffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := h.subs[ch]; ok {
+				delete(h.subs, ch)
+				close(ch)
+			}
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (h *memoryHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// kitchenRoleStatuses order_status yang relevan untuk tiap role (admin melihat semua)
+var kitchenRoleStatuses = map[string][]string{
+	"koki":  {OrderStatusPending, OrderStatusCooking},
+	"kasir": {OrderStatusReady},
+}
+
+// KitchenStatusesForRole order_status yang ditampilkan untuk role tersebut; nil berarti semua.
+func KitchenStatusesForRole(role string) []string {
+	return kitchenRoleStatuses[role]
+}
+
+// EventVisibleToRole true jika event perlu dikirim ke role tersebut: status baru atau status lama
+// termasuk yang dipantau role (status lama perlu agar layar bisa menghapus pesanan yang sudah pindah).
+func EventVisibleToRole(event OrderEvent, role string) bool {
+	statuses, ok := kitchenRoleStatuses[role]
+	if !ok {
+		return role == "admin"
+	}
+	for _, st := range statuses {
+		if event.OrderStatus == st || event.PreviousOrderStatus == st {
+			return true
+		}
+	}
+	return false
+}
+
+// EventForStation menyaring item event untuk satu stasiun; false jika event tidak punya item di stasiun tersebut.
+// station kosong berarti semua stasiun.
+func EventForStation(event OrderEvent, station string) (OrderEvent, bool) {
+	if station == "" {
+		return event, true
+	}
+	items := make([]OrderEventItem, 0, len(event.Items))
+	for _, it := range event.Items {
+		if it.Station == station {
+			items = append(items, it)
+		}
+	}
+	if len(items) == 0 {
+		return event, false
+	}
+	event.Items = items
+	return event, true
+}
+
+// publishTransactionEvent membangun OrderEvent dari transaksi lalu mengirimnya ke hub.
+func publishTransactionEvent(eventType string, t *transaction_model.Transaction, previousOrderStatus string) {
+	items := make([]OrderEventItem, 0, len(t.Items))
+	for _, it := range t.Items {
+		items = append(items, OrderEventItem{
+			ItemID:    it.ID,
+			MenuName:  it.MenuName,
+			Quantity:  it.Quantity,
+			Modifiers: ItemModifierLabels(it),
+			Notes:     it.Notes,
+			Station:   it.Station,
+			Status:    it.Status,
+			Batch:     it.Batch,
+		})
+	}
+	OrderEvents.Publish(OrderEvent{
+		Type:                eventType,
+		TransactionID:       t.ID,
+		OrderNumber:         t.OrderNumber,
+		CustomerName:        t.CustomerName,
+		OrderType:           t.OrderType,
+		TableNumber:         t.TableNumber,
+		PaymentStatus:       t.PaymentStatus,
+		OrderStatus:         t.OrderStatus,
+		PreviousOrderStatus: previousOrderStatus,
+		Notes:               t.Notes,
+		Items:               items,
+		OccurredAt:          time.Now(),
+	})
+}
+
+// publishStatusChange mengirim event status_changed jika payment_status / order_status benar-benar berubah.
+func publishStatusChange(before, after *transaction_model.Transaction) {
+	if before.PaymentStatus == after.PaymentStatus && before.OrderStatus == after.OrderStatus {
+		return
+	}
+	publishTransactionEvent(EventOrderStatusChanged, after, before.OrderStatus)
+}
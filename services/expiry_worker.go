@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"pos-go/config"
+	transaction_model "pos-go/models/transaction_model"
+	"time"
+)
+
+// ExpiryWorker menyapu transaksi non-cash yang masih pending tetapi sudah lewat expired_at,
+// untuk berjaga-jaga jika webhook "expire" dari Midtrans tidak pernah datang.
+type ExpiryWorker struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewExpiryWorker(interval time.Duration) ExpiryWorker {
+	return ExpiryWorker{Interval: interval, BatchSize: 100}
+}
+
+// Run menjalankan sweep berkala sampai ctx dibatalkan (dipanggil dari main sebagai goroutine).
+func (w ExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Expiry worker berjalan (interval %s)", w.Interval)
+	for {
+		if n, err := w.SweepExpired(ctx, time.Now()); err != nil {
+			log.Printf("Expiry worker gagal: %v", err)
+		} else if n > 0 {
+			log.Printf("Expiry worker: %d transaksi ditandai expired", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Expiry worker berhenti")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepExpired menandai transaksi pending dengan expired_at <= now menjadi expired/cancelled
+// (lewat tabel transisi, sekaligus mengembalikan kuota promo). Mengembalikan jumlah transaksi yang diubah.
+func (w ExpiryWorker) SweepExpired(ctx context.Context, now time.Time) (int, error) {
+	var transactions []transaction_model.Transaction
+	if err := config.DB.WithContext(ctx).
+		Where("payment_status = ? AND expired_at IS NOT NULL AND expired_at <= ?", PaymentStatusPending, now).
+		Order("expired_at ASC").
+		Limit(w.BatchSize).
+		Find(&transactions).Error; err != nil {
+		return 0, ErrDatabaseError
+	}
+
+	expired := 0
+	for i := range transactions {
+		if ctx.Err() != nil {
+			break
+		}
+		previous := transactions[i]
+		err := applyStatusTransition(config.DB.WithContext(ctx), &transactions[i], PaymentStatusExpired, OrderStatusCancelled, nil)
+		if err != nil {
+			// Sudah diubah request lain (mis. webhook paid masuk bersamaan): lewati
+			if errors.Is(err, ErrStatusConflict) || errors.Is(err, ErrInvalidTransition) {
+				continue
+			}
+			return expired, err
+		}
+		transactions[i].PaymentStatus = PaymentStatusExpired
+		transactions[i].OrderStatus = OrderStatusCancelled
+		publishStatusChange(&previous, &transactions[i])
+		expired++
+	}
+	return expired, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"pos-go/config"
+	transaction_model "pos-go/models/transaction_model"
+	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Nama sheet ekspor (juga nilai query sheet= untuk CSV)
+const (
+	ExportSheetTransactions  = "transaksi"
+	ExportSheetItems         = "item"
+	ExportSheetSettlements   = "settlement"
+	ExportSheetDenominations = "pecahan"
+)
+
+// transactionExportHeader kolom sheet transaksi, sama dengan dto.ReportTransactionItem
+var transactionExportHeader = []string{
+	"id", "order_number", "customer_name", "order_type", "payment_method", "total_amount", "refunded_amount",
+	"closed_by_user_id", "closed_by_user_name", "created_at",
+}
+
+// transactionItemExportHeader kolom sheet rincian item
+var transactionItemExportHeader = []string{
+	"transaction_id", "order_number", "transaction_created_at", "item_id", "menu_id", "menu_name", "modifiers", "menu_price", "modifier_price",
+	"quantity", "refunded_quantity", "subtotal", "discount", "service_charge", "tax_amount", "batch", "station", "notes",
+}
+
+// transactionItemExportRow satu baris item untuk ekspor (modifier digabung jadi satu teks)
+type transactionItemExportRow struct {
+	ID                   uuid.UUID
+	TransactionID        uuid.UUID
+	OrderNumber          string
+	TransactionCreatedAt time.Time
+	MenuID               uuid.UUID
+	MenuName             string
+	ModifierNames        string
+	MenuPrice            utils.Money
+	ModifierPrice        utils.Money
+	Quantity             int
+	RefundedQuantity     int
+	Subtotal             utils.Money
+	Discount             utils.Money
+	ServiceCharge        utils.Money
+	TaxAmount            utils.Money
+	Batch                int
+	Station              string
+	Notes                string
+}
+
+// exportTransactionSheets menulis sheet transaksi lalu sheet rincian item untuk transaksi hasil query.
+// query dipanggil ulang untuk setiap sheet (builder gorm tidak boleh dipakai dua kali). Baris dibaca dengan cursor
+// (Rows) sehingga memori tidak bergantung jumlah transaksi. withStatus menambah kolom payment_status & order_status.
+func exportTransactionSheets(w utils.TableWriter, query func() *gorm.DB, withStatus bool) error {
+	if w.Accepts(ExportSheetTransactions) {
+		header := transactionExportHeader
+		if withStatus {
+			header = append(append([]string{}, header...), "payment_status", "order_status")
+		}
+		if err := w.NewSheet(ExportSheetTransactions, header); err != nil {
+			return err
+		}
+		rows, err := query().Order("created_at ASC").Rows()
+		if err != nil {
+			return ErrDatabaseError
+		}
+		defer rows.Close()
+
+		userNames := make(map[uuid.UUID]string)
+		for rows.Next() {
+			var t transaction_model.Transaction
+			if err := config.DB.ScanRows(rows, &t); err != nil {
+				return ErrDatabaseError
+			}
+			values := []interface{}{
+				t.ID.String(), t.OrderNumber, t.CustomerName, t.OrderType, t.PaymentMethod, t.TotalAmount, t.RefundedAmount,
+				nil, exportUserName(userNames, t.ClosedByUserID), config.StoreCalendar.Format(t.CreatedAt),
+			}
+			if t.ClosedByUserID != nil {
+				values[7] = t.ClosedByUserID.String()
+			}
+			if withStatus {
+				values = append(values, t.PaymentStatus, t.OrderStatus)
+			}
+			if err := w.WriteRow(values...); err != nil {
+				return err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return ErrDatabaseError
+		}
+	}
+
+	if !w.Accepts(ExportSheetItems) {
+		return nil
+	}
+	if err := w.NewSheet(ExportSheetItems, transactionItemExportHeader); err != nil {
+		return err
+	}
+	rows, err := config.DB.Model(&transaction_model.TransactionItem{}).
+		Select(`transaction_items.*, transactions.order_number, transactions.created_at AS transaction_created_at,
+			COALESCE((SELECT string_agg(m.group_name || ': ' || m.option_name, ', ' ORDER BY m.sort_order)
+				FROM transaction_item_modifiers AS m WHERE m.transaction_item_id = transaction_items.id), '') AS modifier_names`).
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transaction_items.transaction_id IN (?)", query().Select("id")).
+		Order("transactions.created_at ASC, transaction_items.batch ASC, transaction_items.created_at ASC").
+		Rows()
+	if err != nil {
+		return ErrDatabaseError
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it transactionItemExportRow
+		if err := config.DB.ScanRows(rows, &it); err != nil {
+			return ErrDatabaseError
+		}
+		if err := w.WriteRow(
+			it.TransactionID.String(), it.OrderNumber, config.StoreCalendar.Format(it.TransactionCreatedAt), it.ID.String(), it.MenuID.String(),
+			it.MenuName, it.ModifierNames, it.MenuPrice, it.ModifierPrice, it.Quantity, it.RefundedQuantity,
+			it.Subtotal, it.Discount, it.ServiceCharge, it.TaxAmount, it.Batch, it.Station, it.Notes,
+		); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+// exportUserName nama user dengan cache (jumlah kasir kecil, sehingga map tetap kecil walau transaksi banyak)
+func exportUserName(cache map[uuid.UUID]string, id *uuid.UUID) string {
+	if id == nil {
+		return "-"
+	}
+	if name, ok := cache[*id]; ok {
+		return name
+	}
+	name := "-"
+	var u user_model.User
+	if err := config.DB.Select("name").First(&u, "id = ?", *id).Error; err == nil {
+		name = u.Name
+	}
+	cache[*id] = name
+	return name
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	ingredient_model "pos-go/models/ingredient_model"
+	menu_model "pos-go/models/menu_model"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Jenis ingredient movement
+const (
+	IngredientMovementUsage      = "usage" // pemakaian teoritis dari resep saat transaksi dibayar
+	IngredientMovementPurchase   = "purchase"
+	IngredientMovementWaste      = "waste"
+	IngredientMovementCorrection = "correction" // hasil stock opname
+)
+
+// Sentinel errors
+var (
+	ErrIngredientNotFound          = errors.New("Bahan tidak ditemukan")
+	ErrIngredientNameExists        = errors.New("Nama bahan sudah digunakan")
+	ErrIngredientInUse             = errors.New("Bahan masih dipakai di resep menu")
+	ErrInvalidIngredientAdjustment = errors.New("Jumlah penyesuaian bahan tidak valid")
+	ErrDuplicateRecipeIngredient   = errors.New("Bahan yang sama muncul lebih dari sekali di resep")
+	ErrCreateIngredientFailed      = errors.New("Gagal membuat bahan")
+	ErrUpdateIngredientFailed      = errors.New("Gagal mengupdate bahan")
+	ErrDeleteIngredientFailed      = errors.New("Gagal menghapus bahan")
+	ErrGetIngredientsFailed        = errors.New("Gagal mengambil daftar bahan")
+	ErrSetRecipeFailed             = errors.New("Gagal menyimpan resep")
+)
+
+type IngredientService interface {
+	CreateIngredient(input dto.CreateIngredientDTO) (ingredient_model.Ingredient, error)
+	GetAllIngredients(filter dto.IngredientListFilter, page utils.PageQuery) ([]ingredient_model.Ingredient, int64, error)
+	GetLowStockIngredients() ([]ingredient_model.Ingredient, error)
+	UpdateIngredient(ingredientID string, input dto.UpdateIngredientDTO) (ingredient_model.Ingredient, error)
+	DeleteIngredient(ingredientID string) error
+	AdjustIngredient(ingredientID string, input dto.IngredientAdjustmentDTO, userID uuid.UUID) (ingredient_model.IngredientMovement, error)
+	GetIngredientMovements(ingredientID string, filter dto.IngredientMovementListFilter, page utils.PageQuery) ([]ingredient_model.IngredientMovement, int64, error)
+	GetRecipe(menuID string) ([]ingredient_model.RecipeLine, error)
+	SetRecipe(menuID string, input dto.SetRecipeDTO) ([]ingredient_model.RecipeLine, error)
+}
+
+type ingredientService struct{}
+
+func NewIngredientService() IngredientService {
+	return &ingredientService{}
+}
+
+// IngredientSortColumns kolom yang boleh dipakai untuk sort_by pada GET /ingredient
+var IngredientSortColumns = map[string]string{
+	"name":       "name",
+	"stock":      "stock",
+	"created_at": "created_at",
+}
+
+// IngredientMovementSortColumns kolom yang boleh dipakai untuk sort_by pada GET /ingredient/:id/movements
+var IngredientMovementSortColumns = map[string]string{
+	"created_at": "created_at",
+	"quantity":   "quantity",
+}
+
+// applyIngredientChange mengubah stok bahan secara atomik lalu mencatat movement. Stok boleh minus.
+func applyIngredientChange(tx *gorm.DB, ingredientID uuid.UUID, delta float64, movement ingredient_model.IngredientMovement) (ingredient_model.IngredientMovement, error) {
+	var ingredient ingredient_model.Ingredient
+	res := tx.Model(&ingredient).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}, {Name: "stock"}}}).
+		Where("id = ?", ingredientID).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if res.Error != nil {
+		return ingredient_model.IngredientMovement{}, ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		return ingredient_model.IngredientMovement{}, ErrIngredientNotFound
+	}
+
+	movement.IngredientID = ingredientID
+	movement.IngredientName = ingredient.Name
+	movement.Quantity = delta
+	movement.StockAfter = ingredient.Stock
+	if err := tx.Create(&movement).Error; err != nil {
+		return ingredient_model.IngredientMovement{}, ErrDatabaseError
+	}
+	return movement, nil
+}
+
+// deductIngredientsForTransaction memotong stok bahan sesuai resep x qty item transaksi.
+// Dipanggil sekali saat pembayaran berhasil (flag ingredients_deducted mencegah potong ganda).
+// Bahan dari menu yang resepnya berubah setelah transaksi dibuat memakai resep saat dibayar.
+func deductIngredientsForTransaction(tx *gorm.DB, transactionID uuid.UUID) error {
+	res := tx.Model(&transaction_model.Transaction{}).
+		Where("id = ? AND ingredients_deducted = ?", transactionID, false).
+		Update("ingredients_deducted", true)
+	if res.Error != nil {
+		return ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+
+	var usages []struct {
+		IngredientID uuid.UUID
+		Quantity     float64
+	}
+	if err := tx.Table("transaction_items").
+		Select("recipe_lines.ingredient_id, SUM(recipe_lines.quantity * transaction_items.quantity) AS quantity").
+		Joins("JOIN recipe_lines ON recipe_lines.menu_id = transaction_items.menu_id").
+		Where("transaction_items.transaction_id = ? AND transaction_items.deleted_at IS NULL", transactionID).
+		Group("recipe_lines.ingredient_id").
+		Scan(&usages).Error; err != nil {
+		return ErrDatabaseError
+	}
+
+	for _, u := range usages {
+		_, err := applyIngredientChange(tx, u.IngredientID, -u.Quantity, ingredient_model.IngredientMovement{
+			Type:          IngredientMovementUsage,
+			TransactionID: &transactionID,
+		})
+		// Bahan yang sudah dihapus dilewati
+		if err != nil && !errors.Is(err, ErrIngredientNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// findIngredient mengambil bahan berdasarkan ID (string)
+func findIngredient(db *gorm.DB, ingredientID string) (ingredient_model.Ingredient, error) {
+	id, err := uuid.Parse(ingredientID)
+	if err != nil {
+		return ingredient_model.Ingredient{}, ErrIngredientNotFound
+	}
+	var ingredient ingredient_model.Ingredient
+	if err := db.First(&ingredient, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ingredient_model.Ingredient{}, ErrIngredientNotFound
+		}
+		return ingredient_model.Ingredient{}, ErrDatabaseError
+	}
+	return ingredient, nil
+}
+
+// CreateIngredient membuat bahan baru (stok awal 0, diisi lewat pembelian / koreksi)
+func (s *ingredientService) CreateIngredient(input dto.CreateIngredientDTO) (ingredient_model.Ingredient, error) {
+	var existing ingredient_model.Ingredient
+	if err := config.DB.Where("LOWER(name) = LOWER(?)", input.Name).First(&existing).Error; err == nil {
+		return ingredient_model.Ingredient{}, ErrIngredientNameExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return ingredient_model.Ingredient{}, ErrCreateIngredientFailed
+	}
+
+	ingredient := ingredient_model.Ingredient{
+		Name:              input.Name,
+		Unit:              input.Unit,
+		LowStockThreshold: input.LowStockThreshold,
+	}
+	if err := config.DB.Create(&ingredient).Error; err != nil {
+		return ingredient_model.Ingredient{}, ErrCreateIngredientFailed
+	}
+	return ingredient, nil
+}
+
+// GetAllIngredients daftar bahan (search nama + paginasi)
+func (s *ingredientService) GetAllIngredients(filter dto.IngredientListFilter, page utils.PageQuery) ([]ingredient_model.Ingredient, int64, error) {
+	q := config.DB.Model(&ingredient_model.Ingredient{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		q = q.Where("LOWER(name) LIKE ?", utils.ContainsPattern(search))
+	}
+
+	var ingredients []ingredient_model.Ingredient
+	total, err := utils.Paginate(q, page, &ingredients)
+	if err != nil {
+		return nil, 0, ErrGetIngredientsFailed
+	}
+	return ingredients, total, nil
+}
+
+// GetLowStockIngredients bahan dengan stok <= low_stock_threshold
+func (s *ingredientService) GetLowStockIngredients() ([]ingredient_model.Ingredient, error) {
+	var ingredients []ingredient_model.Ingredient
+	if err := config.DB.Where("stock <= low_stock_threshold").Order("stock ASC, name ASC").Find(&ingredients).Error; err != nil {
+		return nil, ErrGetIngredientsFailed
+	}
+	return ingredients, nil
+}
+
+// UpdateIngredient mengupdate nama / satuan / batas stok (partial update)
+func (s *ingredientService) UpdateIngredient(ingredientID string, input dto.UpdateIngredientDTO) (ingredient_model.Ingredient, error) {
+	ingredient, err := findIngredient(config.DB, ingredientID)
+	if err != nil {
+		if errors.Is(err, ErrIngredientNotFound) {
+			return ingredient_model.Ingredient{}, err
+		}
+		return ingredient_model.Ingredient{}, ErrUpdateIngredientFailed
+	}
+
+	if input.Name != "" {
+		var existing ingredient_model.Ingredient
+		if err := config.DB.Where("LOWER(name) = LOWER(?) AND id != ?", input.Name, ingredient.ID).First(&existing).Error; err == nil {
+			return ingredient_model.Ingredient{}, ErrIngredientNameExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return ingredient_model.Ingredient{}, ErrUpdateIngredientFailed
+		}
+		ingredient.Name = input.Name
+	}
+	if input.Unit != "" {
+		ingredient.Unit = input.Unit
+	}
+	if input.LowStockThreshold != nil {
+		ingredient.LowStockThreshold = *input.LowStockThreshold
+	}
+
+	// Stok tidak ikut disimpan agar tidak menimpa perubahan atomik yang terjadi bersamaan
+	if err := config.DB.Model(&ingredient).Select("name", "unit", "low_stock_threshold").Updates(&ingredient).Error; err != nil {
+		return ingredient_model.Ingredient{}, ErrUpdateIngredientFailed
+	}
+	return ingredient, nil
+}
+
+// DeleteIngredient menghapus bahan (soft delete). Ditolak jika masih dipakai di resep.
+func (s *ingredientService) DeleteIngredient(ingredientID string) error {
+	ingredient, err := findIngredient(config.DB, ingredientID)
+	if err != nil {
+		if errors.Is(err, ErrIngredientNotFound) {
+			return err
+		}
+		return ErrDeleteIngredientFailed
+	}
+
+	var used int64
+	if err := config.DB.Model(&ingredient_model.RecipeLine{}).Where("ingredient_id = ?", ingredient.ID).Count(&used).Error; err != nil {
+		return ErrDeleteIngredientFailed
+	}
+	if used > 0 {
+		return ErrIngredientInUse
+	}
+
+	if err := config.DB.Delete(&ingredient).Error; err != nil {
+		return ErrDeleteIngredientFailed
+	}
+	return nil
+}
+
+// AdjustIngredient mencatat waste atau koreksi hasil stock opname
+func (s *ingredientService) AdjustIngredient(ingredientID string, input dto.IngredientAdjustmentDTO, userID uuid.UUID) (ingredient_model.IngredientMovement, error) {
+	var movement ingredient_model.IngredientMovement
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		ingredient, err := findIngredient(tx.Clauses(clause.Locking{Strength: "UPDATE"}), ingredientID)
+		if err != nil {
+			return err
+		}
+
+		var delta float64
+		switch input.Type {
+		case IngredientMovementWaste:
+			if input.Quantity <= 0 {
+				return ErrInvalidIngredientAdjustment
+			}
+			delta = -input.Quantity
+		case IngredientMovementCorrection:
+			delta = input.Quantity - ingredient.Stock
+		}
+
+		movement, err = applyIngredientChange(tx, ingredient.ID, delta, ingredient_model.IngredientMovement{
+			Type:   input.Type,
+			UserID: &userID,
+			Note:   input.Note,
+		})
+		return err
+	})
+	if err != nil {
+		return ingredient_model.IngredientMovement{}, err
+	}
+	return movement, nil
+}
+
+// GetIngredientMovements riwayat perubahan stok satu bahan (filter + paginasi)
+func (s *ingredientService) GetIngredientMovements(ingredientID string, filter dto.IngredientMovementListFilter, page utils.PageQuery) ([]ingredient_model.IngredientMovement, int64, error) {
+	id, err := uuid.Parse(ingredientID)
+	if err != nil {
+		return nil, 0, ErrIngredientNotFound
+	}
+
+	q := config.DB.Model(&ingredient_model.IngredientMovement{}).Where("ingredient_id = ?", id)
+	if filter.Type != "" {
+		q = q.Where("type = ?", filter.Type)
+	}
+	if filter.DateFrom != "" {
+		from, _, err := config.StoreCalendar.ParseDay(filter.DateFrom)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("created_at >= ?", from)
+	}
+	if filter.DateTo != "" {
+		_, to, err := config.StoreCalendar.ParseDay(filter.DateTo)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("created_at < ?", to)
+	}
+
+	var movements []ingredient_model.IngredientMovement
+	total, err := utils.Paginate(q, page, &movements)
+	if err != nil {
+		return nil, 0, ErrGetIngredientsFailed
+	}
+	return movements, total, nil
+}
+
+// GetRecipe resep (daftar bahan per porsi) untuk satu menu
+func (s *ingredientService) GetRecipe(menuID string) ([]ingredient_model.RecipeLine, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return nil, ErrMenuNotFound
+	}
+	var count int64
+	if err := config.DB.Model(&menu_model.Menu{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	if count == 0 {
+		return nil, ErrMenuNotFound
+	}
+
+	var lines []ingredient_model.RecipeLine
+	if err := config.DB.Preload("Ingredient").Where("menu_id = ?", id).Order("created_at ASC").Find(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
+
+// SetRecipe mengganti seluruh resep menu
+func (s *ingredientService) SetRecipe(menuID string, input dto.SetRecipeDTO) ([]ingredient_model.RecipeLine, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return nil, ErrMenuNotFound
+	}
+	var menu menu_model.Menu
+	if err := config.DB.Select("id").First(&menu, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, ErrSetRecipeFailed
+	}
+
+	lines := make([]ingredient_model.RecipeLine, 0, len(input.Lines))
+	seen := make(map[uuid.UUID]bool)
+	for _, in := range input.Lines {
+		ingredient, err := findIngredient(config.DB, in.IngredientID)
+		if err != nil {
+			return nil, err
+		}
+		if seen[ingredient.ID] {
+			return nil, ErrDuplicateRecipeIngredient
+		}
+		seen[ingredient.ID] = true
+		lines = append(lines, ingredient_model.RecipeLine{MenuID: id, IngredientID: ingredient.ID, Quantity: in.Quantity})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_id = ?", id).Delete(&ingredient_model.RecipeLine{}).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		return nil, ErrSetRecipeFailed
+	}
+
+	return s.GetRecipe(menuID)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	menu_model "pos-go/models/menu_model"
+	transaction_model "pos-go/models/transaction_model"
+	"strings"
+)
+
+// Status item pesanan (per baris, dikerjakan per stasiun dapur)
+const (
+	ItemStatusQueued  = "queued"
+	ItemStatusCooking = "cooking"
+	ItemStatusReady   = "ready"
+)
+
+// DefaultStation stasiun untuk menu yang tidak punya stasiun (menu maupun kategori)
+const DefaultStation = "kitchen"
+
+var ErrItemNotFound = errors.New("Item pesanan tidak ditemukan")
+
+// itemTransitions tabel transisi status item yang sah (from -> daftar to). queued -> ready untuk item cepat (mis. minuman).
+var itemTransitions = map[string][]string{
+	ItemStatusQueued:  {ItemStatusCooking, ItemStatusReady},
+	ItemStatusCooking: {ItemStatusReady},
+}
+
+// NormalizeStation menyeragamkan nama stasiun (huruf kecil, tanpa spasi di ujung)
+func NormalizeStation(station string) string {
+	return strings.ToLower(strings.TrimSpace(station))
+}
+
+// resolveStation stasiun item: stasiun menu, jika kosong stasiun kategori, jika kosong DefaultStation.
+// Category harus sudah di-preload.
+func resolveStation(menu menu_model.Menu) string {
+	if menu.Station != "" {
+		return menu.Station
+	}
+	if menu.Category.Station != "" {
+		return menu.Category.Station
+	}
+	return DefaultStation
+}
+
+// deriveOrderStatus order_status menurut status item: semua ready -> ready, ada yang mulai dikerjakan -> cooking, selain itu pending.
+func deriveOrderStatus(items []transaction_model.TransactionItem) string {
+	if len(items) == 0 {
+		return OrderStatusPending
+	}
+	allReady, started := true, false
+	for _, it := range items {
+		if it.Status != ItemStatusReady {
+			allReady = false
+		}
+		if it.Status != ItemStatusQueued {
+			started = true
+		}
+	}
+	switch {
+	case allReady:
+		return OrderStatusReady
+	case started:
+		return OrderStatusCooking
+	default:
+		return OrderStatusPending
+	}
+}
+
+// derivedOrderSteps langkah order_status (sesuai tabel transisi) untuk mencapai status turunan dari item.
+// Status hanya bergerak maju; pesanan pending yang semua itemnya langsung ready melewati cooking.
+func derivedOrderSteps(current, derived string) []string {
+	if current == derived {
+		return nil
+	}
+	if current == OrderStatusPending && derived == OrderStatusReady {
+		return []string{OrderStatusCooking, OrderStatusReady}
+	}
+	if current == OrderStatusCooking && derived == OrderStatusPending {
+		return nil
+	}
+	if allowedTransition(orderTransitions, current, derived) {
+		return []string{derived}
+	}
+	return nil
+}
@@ -6,6 +6,9 @@ import (
 	"pos-go/dto"
 	category_model "pos-go/models/category_model"
 	menu_model "pos-go/models/menu_model"
+	stock_model "pos-go/models/stock_model"
+	"pos-go/utils"
+	"strings"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -26,7 +29,7 @@ type MenuService interface {
 	CreateMenu(input dto.CreateMenuDTO) (menu_model.Menu, error)
 	UpdateMenu(menuID string, input dto.UpdateMenuDTO) (menu_model.Menu, error)
 	DeleteMenu(menuID string) error
-	GetAllMenus() ([]menu_model.Menu, error) //admin
+	GetAllMenus(filter dto.MenuListFilter, page utils.PageQuery) ([]menu_model.Menu, int64, error) //admin
 	GetPublicMenus() ([]menu_model.Menu, error)
 }
 
@@ -69,10 +72,34 @@ func (s *menuService) CreateMenu(input dto.CreateMenuDTO) (menu_model.Menu, erro
 		Image:       input.Image,
 		IsAvailable: input.IsAvailable,
 		CategoryID:  categoryID,
+		Station:     NormalizeStation(input.Station),
+
+		TrackStock:        input.TrackStock,
+		LowStockThreshold: input.LowStockThreshold,
+	}
+	if input.TrackStock {
+		menu.Stock = input.Stock
+		applySoldOut(&menu)
 	}
 
-	// Simpan ke database
-	if err := config.DB.Create(&menu).Error; err != nil {
+	// Simpan ke database (stok awal ikut dicatat sebagai restock)
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&menu).Error; err != nil {
+			return err
+		}
+		if menu.TrackStock && menu.Stock > 0 {
+			return tx.Create(&stock_model.StockMovement{
+				MenuID:     menu.ID,
+				MenuName:   menu.Name,
+				Type:       StockMovementRestock,
+				Quantity:   menu.Stock,
+				StockAfter: menu.Stock,
+				Note:       "Stok awal",
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
 		return menu_model.Menu{}, ErrCreateMenuFailed
 	}
 
@@ -84,16 +111,36 @@ func (s *menuService) CreateMenu(input dto.CreateMenuDTO) (menu_model.Menu, erro
 	return menu, nil
 }
 
-// Get semua menu untuk admin dashboard
-func (s *menuService) GetAllMenus() ([]menu_model.Menu, error) {
+// MenuSortColumns kolom yang boleh dipakai untuk sort_by pada GET /menu
+var MenuSortColumns = map[string]string{
+	"created_at": "created_at",
+	"name":       "name",
+	"price":      "price",
+	"stock":      "stock",
+}
+
+// Get semua menu untuk admin dashboard (dengan filter + paginasi)
+func (s *menuService) GetAllMenus(filter dto.MenuListFilter, page utils.PageQuery) ([]menu_model.Menu, int64, error) {
 	var menus []menu_model.Menu
 
+	q := config.DB.Model(&menu_model.Menu{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		q = q.Where("LOWER(name) LIKE ?", utils.ContainsPattern(search))
+	}
+	if filter.CategoryID != "" {
+		q = q.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.IsAvailable != nil {
+		q = q.Where("is_available = ?", *filter.IsAvailable)
+	}
+
 	// Preload Category untuk mendapatkan informasi kategori
-	if err := config.DB.Preload("Category").Find(&menus).Error; err != nil {
-		return nil, ErrGetMenusFailed
+	total, err := utils.Paginate(q, page, &menus, "Category")
+	if err != nil {
+		return nil, 0, ErrGetMenusFailed
 	}
 
-	return menus, nil
+	return menus, total, nil
 }
 
 // Get menu yang tersedia untuk customer (is_available = true)
@@ -102,7 +149,8 @@ func (s *menuService) GetPublicMenus() ([]menu_model.Menu, error) {
 
 	// Filter hanya menu yang is_available = true
 	// Preload Category untuk mendapatkan informasi kategori
-	if err := config.DB.Preload("Category").Where("is_available = ?", true).Find(&menus).Error; err != nil {
+	// Preload grup modifier (hanya opsi yang tersedia) agar customer bisa memilih ukuran / topping
+	if err := preloadModifierGroups(config.DB, true).Preload("Category").Where("is_available = ?", true).Find(&menus).Error; err != nil {
 		return nil, ErrGetMenusFailed
 	}
 
@@ -166,16 +214,38 @@ func (s *menuService) UpdateMenu(menuID string, input dto.UpdateMenuDTO) (menu_m
 		menu.CategoryID = categoryID
 	}
 
-	// Update IsAvailable (selalu update dari form)
-	menu.IsAvailable = input.IsAvailable
+	// Update Station jika dikirim (string kosong = ikut stasiun kategori)
+	if input.Station != nil {
+		menu.Station = NormalizeStation(*input.Station)
+	}
+
+	// Pengaturan stok (jumlah stok hanya berubah lewat penyesuaian stok agar tercatat)
+	if input.TrackStock != nil {
+		menu.TrackStock = *input.TrackStock
+	}
+	if input.LowStockThreshold != nil {
+		menu.LowStockThreshold = *input.LowStockThreshold
+	}
 
 	// Update image hanya jika ada (input.Image tidak kosong)
 	if input.Image != "" {
 		menu.Image = input.Image
 	}
 
-	// Simpan perubahan ke database
-	if err := config.DB.Save(&menu).Error; err != nil {
+	// Simpan hanya kolom dari form. stock & sold_out tidak ditulis ulang dari hasil baca di atas agar penjualan yang
+	// terjadi di antaranya tidak tertimpa; is_available (selalu dari form) dan sold_out dihitung dari stok saat ini di SQL.
+	if err := config.DB.Model(&menu).Updates(map[string]interface{}{
+		"name":                menu.Name,
+		"description":         menu.Description,
+		"price":               menu.Price,
+		"category_id":         menu.CategoryID,
+		"station":             menu.Station,
+		"track_stock":         menu.TrackStock,
+		"low_stock_threshold": menu.LowStockThreshold,
+		"image":               menu.Image,
+		"is_available":        gorm.Expr("CASE WHEN ? AND stock <= 0 THEN false ELSE ? END", menu.TrackStock, input.IsAvailable),
+		"sold_out":            gorm.Expr("CASE WHEN ? AND stock <= 0 THEN ? ELSE false END", menu.TrackStock, input.IsAvailable),
+	}).Error; err != nil {
 		return menu_model.Menu{}, ErrUpdateMenuFailed
 	}
 
@@ -187,6 +257,14 @@ func (s *menuService) UpdateMenu(menuID string, input dto.UpdateMenuDTO) (menu_m
 	return menu, nil
 }
 
+// applySoldOut menonaktifkan menu yang melacak stok tetapi stoknya habis
+func applySoldOut(menu *menu_model.Menu) {
+	if menu.TrackStock && menu.Stock <= 0 && menu.IsAvailable {
+		menu.IsAvailable = false
+		menu.SoldOut = true
+	}
+}
+
 // DeleteMenu menghapus menu berdasarkan ID
 func (s *menuService) DeleteMenu(menuID string) error {
 	// Parse menu ID
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sentinel errors untuk webhook Midtrans
+var (
+	ErrInvalidSignature    = errors.New("Signature notifikasi tidak valid")
+	ErrGrossAmountMismatch = errors.New("Gross amount tidak sesuai dengan total transaksi")
+)
+
+// MidtransSignature menghitung signature_key Midtrans: SHA512(order_id + status_code + gross_amount + server_key) dalam hex.
+func MidtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyMidtransSignature membandingkan signature_key dari notifikasi dengan hasil hitung ulang (constant-time).
+// Server key kosong selalu ditolak agar webhook tidak terbuka saat konfigurasi belum diisi.
+func VerifyMidtransSignature(notification dto.MidtransNotification, serverKey string) bool {
+	if serverKey == "" || notification.SignatureKey == "" {
+		return false
+	}
+	expected := MidtransSignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, serverKey)
+	given := strings.ToLower(strings.TrimSpace(notification.SignatureKey))
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
+}
+
+// MidtransOrder target notifikasi yang sudah diverifikasi: tagihan penuh transaksi, atau satu payment (split bill)
+type MidtransOrder struct {
+	TransactionID uuid.UUID
+	PaymentID     *uuid.UUID // nil = tagihan penuh
+}
+
+// transactionIDFromOrderID mengambil ID transaksi dari order_id Midtrans: "<uuid>" atau "<uuid>-<suffix>"
+// (open bill yang ditutup / payment split bill)
+func transactionIDFromOrderID(orderID string) (uuid.UUID, error) {
+	const uuidLen = 36
+	if len(orderID) > uuidLen && orderID[uuidLen] == '-' {
+		orderID = orderID[:uuidLen]
+	}
+	return uuid.Parse(orderID)
+}
+
+// VerifyMidtransNotification memastikan notifikasi berasal dari Midtrans (signature) dan gross_amount
+// sama dengan nominal yang ditagihkan: nominal payment untuk order_id payment split bill, selain itu TotalAmount transaksi.
+func (s TransactionService) VerifyMidtransNotification(notification dto.MidtransNotification) (MidtransOrder, error) {
+	if !VerifyMidtransSignature(notification, config.MidtransServerKey) {
+		return MidtransOrder{}, ErrInvalidSignature
+	}
+
+	transactionID, err := transactionIDFromOrderID(notification.OrderID)
+	if err != nil {
+		return MidtransOrder{}, ErrTransactionNotFound
+	}
+
+	// Midtrans mengirim gross_amount sebagai string desimal ("15000.00"); dibaca exact tanpa float
+	grossAmount, err := utils.ParseMoney(notification.GrossAmount)
+	if err != nil {
+		return MidtransOrder{}, ErrGrossAmountMismatch
+	}
+
+	var payment transaction_model.Payment
+	err = config.DB.Select("id", "transaction_id", "amount").
+		Where("transaction_id = ? AND external_ref = ?", transactionID, notification.OrderID).
+		First(&payment).Error
+	if err == nil {
+		if grossAmount != payment.Amount {
+			return MidtransOrder{}, ErrGrossAmountMismatch
+		}
+		return MidtransOrder{TransactionID: transactionID, PaymentID: &payment.ID}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return MidtransOrder{}, ErrDatabaseError
+	}
+
+	var transaction transaction_model.Transaction
+	if err := config.DB.Select("id", "total_amount", "midtrans_order_id").First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return MidtransOrder{}, ErrTransactionNotFound
+		}
+		return MidtransOrder{}, ErrDatabaseError
+	}
+	// order_id harus sama dengan token yang berlaku (lihat midtransOrderID)
+	if notification.OrderID != midtransOrderID(transaction) {
+		return MidtransOrder{}, ErrTransactionNotFound
+	}
+	// Tagihan yang sudah dibayar per tender (split bill) tidak lagi menerima pembayaran penuh
+	var tenders int64
+	if err := config.DB.Model(&transaction_model.Payment{}).
+		Where("transaction_id = ? AND external_ref <> ?", transactionID, notification.OrderID).
+		Count(&tenders).Error; err != nil {
+		return MidtransOrder{}, ErrDatabaseError
+	}
+	if tenders > 0 {
+		return MidtransOrder{}, ErrTransactionNotFound
+	}
+
+	if grossAmount != transaction.TotalAmount {
+		return MidtransOrder{}, ErrGrossAmountMismatch
+	}
+
+	return MidtransOrder{TransactionID: transactionID}, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const testServerKey = "SB-Mid-server-TEST"
+
+// signedNotification notifikasi settlement yang ditandatangani dengan testServerKey
+func signedNotification(orderID, grossAmount string) dto.MidtransNotification {
+	return dto.MidtransNotification{
+		OrderID:           orderID,
+		StatusCode:        "200",
+		GrossAmount:       grossAmount,
+		TransactionStatus: "settlement",
+		SignatureKey:      MidtransSignature(orderID, "200", grossAmount, testServerKey),
+	}
+}
+
+// tamperSignature mengubah satu karakter signature
+func tamperSignature(sig string) string {
+	last := "0"
+	if strings.HasSuffix(sig, "0") {
+		last = "1"
+	}
+	return sig[:len(sig)-1] + last
+}
+
+func TestMidtransSignature(t *testing.T) {
+	// SHA512("ORDER-101" + "200" + "10000.00" + server key)
+	want := "9b162d8c2cf742e86aa619397ca943f2ff7fd1ec715e680f6fe1d613551c4b07d9de71ee8f24e75c29a71dc43ce6864d161e93087ccbbaf1c7c75e94b1895412"
+	if got := MidtransSignature("ORDER-101", "200", "10000.00", testServerKey); got != want {
+		t.Fatalf("MidtransSignature = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyMidtransSignature(t *testing.T) {
+	valid := signedNotification("ORDER-101", "10000.00")
+
+	tests := []struct {
+		name      string
+		mutate    func(n *dto.MidtransNotification)
+		serverKey string
+		want      bool
+	}{
+		{"valid", func(n *dto.MidtransNotification) {}, testServerKey, true},
+		{"signature huruf besar", func(n *dto.MidtransNotification) { n.SignatureKey = strings.ToUpper(n.SignatureKey) }, testServerKey, true},
+		{"signature diubah", func(n *dto.MidtransNotification) { n.SignatureKey = tamperSignature(n.SignatureKey) }, testServerKey, false},
+		{"gross_amount diubah", func(n *dto.MidtransNotification) { n.GrossAmount = "1.00" }, testServerKey, false},
+		{"status_code diubah", func(n *dto.MidtransNotification) { n.StatusCode = "201" }, testServerKey, false},
+		{"order_id diubah", func(n *dto.MidtransNotification) { n.OrderID = "ORDER-102" }, testServerKey, false},
+		{"signature kosong", func(n *dto.MidtransNotification) { n.SignatureKey = "" }, testServerKey, false},
+		{"server key lain", func(n *dto.MidtransNotification) {}, "SB-Mid-server-OTHER", false},
+		{"server key kosong", func(n *dto.MidtransNotification) {}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := valid
+			tt.mutate(&n)
+			if got := VerifyMidtransSignature(n, tt.serverKey); got != tt.want {
+				t.Errorf("VerifyMidtransSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyMidtransNotification(t *testing.T) {
+	previousKey := config.MidtransServerKey
+	config.MidtransServerKey = testServerKey
+	t.Cleanup(func() { config.MidtransServerKey = previousKey })
+
+	transactionID := uuid.New()
+	paymentID := uuid.New()
+	paymentOrderID := transactionID.String() + "-P1"
+	split := false // fake db tidak membaca argumen query: true = query payment menemukan payment split bill
+	useFakeDB(t, func(query string) fakeResult {
+		switch {
+		case strings.Contains(query, `count(*)`):
+			return fakeResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}
+		case strings.Contains(query, `FROM "payments"`):
+			if split {
+				return fakeResult{
+					columns: []string{"id", "transaction_id", "amount"},
+					rows:    [][]driver.Value{{paymentID.String(), transactionID.String(), "10000.00"}},
+				}
+			}
+			return fakeResult{columns: []string{"id", "transaction_id", "amount"}}
+		case strings.Contains(query, `FROM "transactions"`):
+			return fakeResult{
+				columns: []string{"id", "total_amount", "midtrans_order_id"},
+				rows:    [][]driver.Value{{transactionID.String(), "30000.00", ""}},
+			}
+		}
+		t.Fatalf("query tidak terduga: %s", query)
+		return fakeResult{}
+	})
+
+	tests := []struct {
+		name         string
+		notification dto.MidtransNotification
+		split        bool
+		wantErr      error
+		wantPayment  bool
+	}{
+		{name: "valid", notification: signedNotification(transactionID.String(), "30000.00")},
+		{name: "gross_amount tanpa desimal", notification: signedNotification(transactionID.String(), "30000")},
+		{
+			name: "signature diubah",
+			notification: func() dto.MidtransNotification {
+				n := signedNotification(transactionID.String(), "30000.00")
+				n.SignatureKey = tamperSignature(n.SignatureKey)
+				return n
+			}(),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "gross_amount diubah tanpa signature baru",
+			notification: func() dto.MidtransNotification {
+				n := signedNotification(transactionID.String(), "30000.00")
+				n.GrossAmount = "1000.00"
+				return n
+			}(),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:         "gross_amount bertanda tangan tapi tidak sama dengan total",
+			notification: signedNotification(transactionID.String(), "1000.00"),
+			wantErr:      ErrGrossAmountMismatch,
+		},
+		{
+			name:         "gross_amount bukan angka",
+			notification: signedNotification(transactionID.String(), "tiga puluh ribu"),
+			wantErr:      ErrGrossAmountMismatch,
+		},
+		{
+			name:         "order_id bukan transaksi",
+			notification: signedNotification("ORDER-101", "30000.00"),
+			wantErr:      ErrTransactionNotFound,
+		},
+		{name: "payment split bill", notification: signedNotification(paymentOrderID, "10000.00"), split: true, wantPayment: true},
+		{
+			name:         "payment split bill dengan gross_amount total transaksi",
+			notification: signedNotification(paymentOrderID, "30000.00"),
+			split:        true,
+			wantErr:      ErrGrossAmountMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split = tt.split
+			order, err := NewTransactionService().VerifyMidtransNotification(tt.notification)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if order.TransactionID != transactionID {
+				t.Errorf("TransactionID = %s, want %s", order.TransactionID, transactionID)
+			}
+			if tt.wantPayment != (order.PaymentID != nil) || (order.PaymentID != nil && *order.PaymentID != paymentID) {
+				t.Errorf("PaymentID = %v, want payment %v", order.PaymentID, tt.wantPayment)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	menu_model "pos-go/models/menu_model"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sentinel errors
+var (
+	ErrInvalidModifier      = errors.New("Pilihan modifier tidak valid")
+	ErrInvalidModifierGroup = errors.New("Konfigurasi grup modifier tidak valid")
+	ErrSetModifierFailed    = errors.New("Gagal menyimpan modifier menu")
+	ErrGetModifierFailed    = errors.New("Gagal mengambil modifier menu")
+)
+
+type ModifierService interface {
+	GetModifierGroups(menuID string) ([]menu_model.ModifierGroup, error)
+	SetModifierGroups(menuID string, input dto.SetModifierGroupsDTO) ([]menu_model.ModifierGroup, error)
+}
+
+type modifierService struct{}
+
+func NewModifierService() ModifierService {
+	return &modifierService{}
+}
+
+// preloadModifierGroups preload grup modifier + opsi dengan urutan tampil. onlyAvailable menyaring opsi yang nonaktif (menu publik).
+func preloadModifierGroups(db *gorm.DB, onlyAvailable bool) *gorm.DB {
+	return db.
+		Preload("ModifierGroups", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC, created_at ASC")
+		}).
+		Preload("ModifierGroups.Options", func(db *gorm.DB) *gorm.DB {
+			if onlyAvailable {
+				db = db.Where("is_available = ?", true)
+			}
+			return db.Order("sort_order ASC, created_at ASC")
+		})
+}
+
+// resolveModifiers memvalidasi pilihan modifier untuk satu item terhadap grup di menu (sudah di-preload)
+// lalu mengembalikan snapshot pilihan (urut sesuai grup) dan total price_delta per porsi.
+func resolveModifiers(menu menu_model.Menu, optionIDs []uuid.UUID) ([]transaction_model.TransactionItemModifier, utils.Money, error) {
+	type choice struct {
+		groupIdx int
+		group    menu_model.ModifierGroup
+		option   menu_model.ModifierOption
+		order    int
+	}
+	options := make(map[uuid.UUID]choice)
+	for gi, g := range menu.ModifierGroups {
+		for oi, o := range g.Options {
+			options[o.ID] = choice{groupIdx: gi, group: g, option: o, order: gi*1000 + oi}
+		}
+	}
+
+	selected := make([]choice, 0, len(optionIDs))
+	counts := make(map[int]int)
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range optionIDs {
+		c, ok := options[id]
+		if !ok || !c.option.IsAvailable {
+			return nil, 0, fmt.Errorf("%w: pilihan tidak tersedia untuk %s", ErrInvalidModifier, menu.Name)
+		}
+		if seen[id] {
+			return nil, 0, fmt.Errorf("%w: %s dipilih lebih dari sekali", ErrInvalidModifier, c.option.Name)
+		}
+		seen[id] = true
+		counts[c.groupIdx]++
+		selected = append(selected, c)
+	}
+
+	for gi, g := range menu.ModifierGroups {
+		minSelect := g.MinSelect
+		if g.IsRequired && minSelect < 1 {
+			minSelect = 1
+		}
+		if counts[gi] < minSelect {
+			return nil, 0, fmt.Errorf("%w: %s - %s wajib dipilih minimal %d", ErrInvalidModifier, menu.Name, g.Name, minSelect)
+		}
+		if g.MaxSelect > 0 && counts[gi] > g.MaxSelect {
+			return nil, 0, fmt.Errorf("%w: %s - %s maksimal %d pilihan", ErrInvalidModifier, menu.Name, g.Name, g.MaxSelect)
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool { return selected[i].order < selected[j].order })
+	modifiers := make([]transaction_model.TransactionItemModifier, 0, len(selected))
+	var delta utils.Money
+	for i, c := range selected {
+		modifiers = append(modifiers, transaction_model.TransactionItemModifier{
+			ModifierGroupID:  c.group.ID,
+			GroupName:        c.group.Name,
+			ModifierOptionID: c.option.ID,
+			OptionName:       c.option.Name,
+			PriceDelta:       c.option.PriceDelta,
+			SortOrder:        i,
+		})
+		delta += c.option.PriceDelta
+	}
+	if menu.Price+delta < 0 {
+		return nil, 0, fmt.Errorf("%w: harga %s menjadi negatif", ErrInvalidModifier, menu.Name)
+	}
+	return modifiers, delta, nil
+}
+
+// sortedItemModifiers modifier item sesuai urutan saat dipesan (hasil preload tidak dijamin urut)
+func sortedItemModifiers(item transaction_model.TransactionItem) []transaction_model.TransactionItemModifier {
+	mods := append([]transaction_model.TransactionItemModifier(nil), item.Modifiers...)
+	sort.SliceStable(mods, func(i, j int) bool { return mods[i].SortOrder < mods[j].SortOrder })
+	return mods
+}
+
+// ItemModifierResponses mengubah snapshot modifier item ke bentuk response (detail transaksi & struk)
+func ItemModifierResponses(item transaction_model.TransactionItem) []dto.ItemModifierResponse {
+	res := make([]dto.ItemModifierResponse, 0, len(item.Modifiers))
+	for _, m := range sortedItemModifiers(item) {
+		res = append(res, dto.ItemModifierResponse{
+			GroupName:  m.GroupName,
+			OptionName: m.OptionName,
+			PriceDelta: m.PriceDelta,
+		})
+	}
+	return res
+}
+
+// ItemModifierLabels nama pilihan modifier item (mis. ["Large", "Extra Keju"]) untuk layar dapur & item_details Midtrans
+func ItemModifierLabels(item transaction_model.TransactionItem) []string {
+	labels := make([]string, 0, len(item.Modifiers))
+	for _, m := range sortedItemModifiers(item) {
+		labels = append(labels, m.OptionName)
+	}
+	return labels
+}
+
+// itemDisplayName nama item beserta modifier, mis. "Nasi Goreng (Large, Extra Keju)"
+func itemDisplayName(item transaction_model.TransactionItem) string {
+	labels := ItemModifierLabels(item)
+	if len(labels) == 0 {
+		return item.MenuName
+	}
+	return item.MenuName + " (" + strings.Join(labels, ", ") + ")"
+}
+
+// GetModifierGroups grup modifier (beserta semua opsi) untuk satu menu
+func (s *modifierService) GetModifierGroups(menuID string) ([]menu_model.ModifierGroup, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return nil, ErrMenuNotFound
+	}
+	var menu menu_model.Menu
+	if err := preloadModifierGroups(config.DB, false).First(&menu, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, ErrGetModifierFailed
+	}
+	if menu.ModifierGroups == nil {
+		return []menu_model.ModifierGroup{}, nil
+	}
+	return menu.ModifierGroups, nil
+}
+
+// SetModifierGroups mengganti seluruh grup modifier menu. Item transaksi lama tidak terpengaruh (sudah di-snapshot).
+func (s *modifierService) SetModifierGroups(menuID string, input dto.SetModifierGroupsDTO) ([]menu_model.ModifierGroup, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return nil, ErrMenuNotFound
+	}
+	var menu menu_model.Menu
+	if err := config.DB.Select("id").First(&menu, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, ErrSetModifierFailed
+	}
+
+	groups := make([]menu_model.ModifierGroup, 0, len(input.Groups))
+	for gi, in := range input.Groups {
+		minSelect := in.MinSelect
+		if in.IsRequired && minSelect < 1 {
+			minSelect = 1
+		}
+		if minSelect > in.MaxSelect || in.MaxSelect > len(in.Options) {
+			return nil, fmt.Errorf("%w: %s (min %d, max %d, %d pilihan)", ErrInvalidModifierGroup, in.Name, minSelect, in.MaxSelect, len(in.Options))
+		}
+		group := menu_model.ModifierGroup{
+			MenuID:     id,
+			Name:       in.Name,
+			IsRequired: in.IsRequired,
+			MinSelect:  minSelect,
+			MaxSelect:  in.MaxSelect,
+			SortOrder:  gi,
+		}
+		for oi, o := range in.Options {
+			available := true
+			if o.IsAvailable != nil {
+				available = *o.IsAvailable
+			}
+			group.Options = append(group.Options, menu_model.ModifierOption{
+				Name:        o.Name,
+				PriceDelta:  o.PriceDelta,
+				IsAvailable: available,
+				SortOrder:   oi,
+			})
+		}
+		groups = append(groups, group)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var groupIDs []uuid.UUID
+		if err := tx.Model(&menu_model.ModifierGroup{}).Where("menu_id = ?", id).Pluck("id", &groupIDs).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) > 0 {
+			if err := tx.Where("group_id IN ?", groupIDs).Delete(&menu_model.ModifierOption{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", groupIDs).Delete(&menu_model.ModifierGroup{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(groups) == 0 {
+			return nil
+		}
+		return tx.Create(&groups).Error
+	})
+	if err != nil {
+		return nil, ErrSetModifierFailed
+	}
+
+	return s.GetModifierGroups(menuID)
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	menu_model "pos-go/models/menu_model"
+	promo_model "pos-go/models/promo_model"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sentinel errors open bill
+var (
+	ErrOpenBillDineInOnly = errors.New("Open bill hanya untuk pesanan makan di tempat")
+	ErrNotOpenBill        = errors.New("Transaksi bukan open bill yang masih berjalan")
+	ErrItemAlreadyStarted = errors.New("Item sudah mulai dikerjakan dapur, tidak dapat dihapus")
+	ErrLastOpenBillItem   = errors.New("Item terakhir tidak dapat dihapus, batalkan pesanan jika perlu")
+)
+
+// lockOpenBill mengambil transaksi dengan row lock (FOR UPDATE) dan memastikan masih open bill yang belum dibayar.
+// Semua perubahan item open bill melewati lock ini sehingga perhitungan ulang total tidak saling timpa.
+func lockOpenBill(tx *gorm.DB, id uuid.UUID) (transaction_model.Transaction, error) {
+	var transaction transaction_model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return transaction_model.Transaction{}, ErrTransactionNotFound
+		}
+		return transaction_model.Transaction{}, ErrDatabaseError
+	}
+	if !transaction.IsOpenBill || transaction.PaymentStatus != PaymentStatusPending || transaction.OrderStatus == OrderStatusCancelled {
+		return transaction_model.Transaction{}, ErrNotOpenBill
+	}
+	return transaction, nil
+}
+
+// reopenOrder mengembalikan open bill yang sudah ready ke cooking karena ada ronde item baru.
+// Transisi ini tidak ada di orderTransitions sehingga tidak bisa dipicu lewat PATCH status oleh role mana pun.
+func reopenOrder(tx *gorm.DB, transaction *transaction_model.Transaction) error {
+	res := tx.Model(&transaction_model.Transaction{}).
+		Where("id = ? AND order_status = ?", transaction.ID, OrderStatusReady).
+		Update("order_status", OrderStatusCooking)
+	if res.Error != nil {
+		return ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		return ErrStatusConflict
+	}
+	transaction.OrderStatus = OrderStatusCooking
+	return nil
+}
+
+// recalculateTransaction menghitung ulang diskon promo, service charge, pajak, dan total dari item transaksi saat ini
+// (setelah item open bill ditambah / dihapus) lalu menyimpannya, termasuk rincian pajak per aturan.
+// Kuota promo tidak dicek ulang (sudah terpakai saat transaksi dibuat); diskon menjadi 0 jika subtotal di bawah min_purchase.
+func recalculateTransaction(tx *gorm.DB, transaction *transaction_model.Transaction) error {
+	var items []transaction_model.TransactionItem
+	if err := tx.Where("transaction_id = ?", transaction.ID).Order("batch ASC, created_at ASC").Find(&items).Error; err != nil {
+		return ErrDatabaseError
+	}
+
+	menuIDs := make([]uuid.UUID, 0, len(items))
+	for _, it := range items {
+		menuIDs = append(menuIDs, it.MenuID)
+	}
+	var menus []menu_model.Menu
+	if err := tx.Select("id", "category_id").Where("id IN ?", menuIDs).Find(&menus).Error; err != nil {
+		return ErrDatabaseError
+	}
+	categories := make(map[uuid.UUID]uuid.UUID, len(menus))
+	for _, m := range menus {
+		categories[m.ID] = m.CategoryID
+	}
+
+	var subtotal utils.Money
+	lines := make([]TaxableLine, 0, len(items))
+	for _, it := range items {
+		subtotal += it.Subtotal
+		lines = append(lines, TaxableLine{CategoryID: categories[it.MenuID], Amount: it.Subtotal})
+	}
+
+	var discount utils.Money
+	if transaction.PromoCode != "" {
+		var promo promo_model.Promo
+		err := tx.Where("LOWER(code) = LOWER(?)", transaction.PromoCode).First(&promo).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDatabaseError
+		}
+		if err == nil && subtotal >= promo.MinPurchase {
+			discount = cappedPromoDiscount(promo, subtotal)
+		}
+	}
+
+	rules, err := activeTaxRules(tx)
+	if err != nil {
+		return ErrDatabaseError
+	}
+	calc := CalculateTaxes(lines, discount, rules, transaction.OrderType)
+	for i := range items {
+		items[i].Discount = calc.Lines[i].Discount
+		items[i].ServiceCharge = calc.Lines[i].ServiceCharge
+		items[i].TaxAmount = calc.Lines[i].Tax
+		if err := tx.Model(&items[i]).Updates(map[string]interface{}{
+			"discount":       items[i].Discount,
+			"service_charge": items[i].ServiceCharge,
+			"tax_amount":     items[i].TaxAmount,
+		}).Error; err != nil {
+			return ErrDatabaseError
+		}
+	}
+
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&transaction_model.TransactionTax{}).Error; err != nil {
+		return ErrDatabaseError
+	}
+	taxes := transactionTaxes(calc)
+	for i := range taxes {
+		taxes[i].TransactionID = transaction.ID
+	}
+	if len(taxes) > 0 {
+		if err := tx.Create(&taxes).Error; err != nil {
+			return ErrDatabaseError
+		}
+	}
+
+	if err := tx.Model(&transaction_model.Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+		"discount":       discount,
+		"subtotal":       calc.Subtotal,
+		"service_charge": calc.ServiceCharge,
+		"tax":            calc.Tax,
+		"total_amount":   calc.Total,
+	}).Error; err != nil {
+		return ErrDatabaseError
+	}
+
+	transaction.Discount = discount
+	transaction.Subtotal = calc.Subtotal
+	transaction.ServiceCharge = calc.ServiceCharge
+	transaction.Tax = calc.Tax
+	transaction.TotalAmount = calc.Total
+	transaction.Items = items
+	transaction.Taxes = taxes
+	return nil
+}
+
+// AddOpenBillItems menambah satu ronde item ke open bill: stok dipotong, total dihitung ulang, dan ronde baru dikirim
+// ke dapur sebagai batch tersendiri. Pesanan yang sudah ready kembali ke cooking.
+func (s TransactionService) AddOpenBillItems(id uuid.UUID, req dto.AddTransactionItemsRequest) (*transaction_model.Transaction, error) {
+	var previous transaction_model.Transaction
+	var added []transaction_model.TransactionItem
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenBill(tx, id)
+		if err != nil {
+			return err
+		}
+		previous = transaction
+
+		// Nomor ronde dihitung termasuk item yang sudah dihapus agar batch tidak dipakai ulang
+		var lastBatch int
+		if err := tx.Unscoped().Model(&transaction_model.TransactionItem{}).
+			Where("transaction_id = ?", id).
+			Select("COALESCE(MAX(batch), 0)").
+			Scan(&lastBatch).Error; err != nil {
+			return ErrDatabaseError
+		}
+
+		items, _, stockTracked, err := buildTransactionItems(tx, req.Items, lastBatch+1)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].TransactionID = id
+			if err := tx.Create(&items[i]).Error; err != nil {
+				return ErrDatabaseError
+			}
+			if stockTracked[items[i].MenuID] {
+				if err := deductStockForSale(tx, id, items[i].MenuID, items[i].Quantity); err != nil {
+					return err
+				}
+			}
+		}
+		added = items
+
+		if err := recalculateTransaction(tx, &transaction); err != nil {
+			return err
+		}
+		if transaction.OrderStatus == OrderStatusReady {
+			return reopenOrder(tx, &transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	batch := *transaction
+	batch.Items = added
+	publishTransactionEvent(EventOrderItemsAdded, &batch, previous.OrderStatus)
+
+	return transaction, nil
+}
+
+// RemoveOpenBillItem menghapus item open bill yang belum mulai dikerjakan (queued): stok dikembalikan, total dihitung ulang,
+// dan order_status diturunkan lagi dari item yang tersisa. Minimal satu item harus tersisa.
+func (s TransactionService) RemoveOpenBillItem(id, itemID uuid.UUID) (*transaction_model.Transaction, error) {
+	var previous transaction_model.Transaction
+	var removed transaction_model.TransactionItem
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenBill(tx, id)
+		if err != nil {
+			return err
+		}
+		previous = transaction
+
+		var items []transaction_model.TransactionItem
+		if err := tx.Preload("Modifiers").Where("transaction_id = ?", id).Find(&items).Error; err != nil {
+			return ErrDatabaseError
+		}
+		remaining := make([]transaction_model.TransactionItem, 0, len(items))
+		found := false
+		for _, it := range items {
+			if it.ID == itemID {
+				removed = it
+				found = true
+				continue
+			}
+			remaining = append(remaining, it)
+		}
+		if !found {
+			return ErrItemNotFound
+		}
+		if removed.Status != ItemStatusQueued {
+			return ErrItemAlreadyStarted
+		}
+		if len(remaining) == 0 {
+			return ErrLastOpenBillItem
+		}
+
+		res := tx.Where("id = ? AND status = ?", removed.ID, ItemStatusQueued).Delete(&transaction_model.TransactionItem{})
+		if res.Error != nil {
+			return ErrDatabaseError
+		}
+		if res.RowsAffected == 0 {
+			return ErrStatusConflict
+		}
+		if err := restoreStockForItem(tx, id, removed.MenuID, removed.Quantity); err != nil {
+			return err
+		}
+
+		if err := recalculateTransaction(tx, &transaction); err != nil {
+			return err
+		}
+		for _, step := range derivedOrderSteps(transaction.OrderStatus, deriveOrderStatus(remaining)) {
+			if err := applyStatusTransition(tx, &transaction, "", step, nil); err != nil {
+				return err
+			}
+			transaction.OrderStatus = step
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	event := *transaction
+	event.Items = []transaction_model.TransactionItem{removed}
+	publishTransactionEvent(EventOrderItemRemoved, &event, previous.OrderStatus)
+	publishStatusChange(&previous, transaction)
+
+	return transaction, nil
+}
+
+// CloseBill menutup open bill: item tidak bisa ditambah lagi dan total sudah final. Untuk non-cash, Snap token dibuat
+// dari total akhir dengan order_id baru (<id>-<unix>) agar tidak bentrok dengan percobaan sebelumnya, dan expired_at mulai berlaku.
+// Tunai dibayar lewat ConfirmCashPaid seperti biasa.
+func (s TransactionService) CloseBill(id uuid.UUID) (*transaction_model.Transaction, string, string, error) {
+	var snapToken, snapURL string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenBill(tx, id)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"is_open_bill": false}
+		if transaction.PaymentMethod != "cash" {
+			now := time.Now()
+			expiredAt := now.Add(24 * time.Hour)
+			transaction.MidtransOrderID = fmt.Sprintf("%s-%d", transaction.ID, now.Unix())
+			updates["midtrans_order_id"] = transaction.MidtransOrderID
+			updates["expired_at"] = expiredAt
+
+			if err := tx.Preload("Modifiers").Where("transaction_id = ?", id).Order("batch ASC, created_at ASC").Find(&transaction.Items).Error; err != nil {
+				return ErrDatabaseError
+			}
+			if err := tx.Where("transaction_id = ?", id).Order("created_at ASC").Find(&transaction.Taxes).Error; err != nil {
+				return ErrDatabaseError
+			}
+			// Token diminta sebelum commit: jika Midtrans gagal, bill tetap terbuka
+			snapToken, snapURL, err = s.GenerateSnapToken(transaction)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&transaction_model.Transaction{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return ErrDatabaseError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	transaction, err := s.GetTransactionByID(id)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return transaction, snapToken, snapURL, nil
+}
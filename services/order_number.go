@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FormatOrderNumber nomor urut harian ke bentuk tampilan: awalan config.OrderNumberPrefix dan minimal 3 digit (A-042)
+func FormatOrderNumber(number int) string {
+	if config.OrderNumberPrefix == "" {
+		return fmt.Sprintf("%03d", number)
+	}
+	return fmt.Sprintf("%s-%03d", config.OrderNumberPrefix, number)
+}
+
+// nextOrderNumber mengambil nomor pesanan berikutnya untuk hari bisnis businessDate. Upsert pada order_counters
+// mengunci baris hari tersebut sampai statement selesai, sehingga request bersamaan selalu mendapat nomor berbeda.
+// Dijalankan di luar transaksi pembuatan pesanan agar kunci tidak ditahan selama request ke Midtrans; nomor dari
+// pesanan yang gagal dibuat tidak dipakai ulang (boleh ada nomor yang terlewat).
+func nextOrderNumber(db *gorm.DB, businessDate time.Time) (string, error) {
+	var number int
+	err := db.Raw(`INSERT INTO order_counters (business_date, last_number, updated_at) VALUES (?, 1, ?)
+		ON CONFLICT (business_date) DO UPDATE SET last_number = order_counters.last_number + 1, updated_at = EXCLUDED.updated_at
+		RETURNING last_number`, businessDate, time.Now()).Scan(&number).Error
+	if err != nil || number == 0 {
+		return "", ErrDatabaseError
+	}
+	return FormatOrderNumber(number), nil
+}
+
+// orderLabel nomor pesanan untuk struk & tiket dapur; transaksi lama tanpa nomor memakai 8 karakter awal ID
+func orderLabel(orderNumber string, id uuid.UUID) string {
+	if orderNumber != "" {
+		return orderNumber
+	}
+	return shortID(id)
+}
+
+// GetNowServing nomor pesanan hari bisnis ini yang sudah siap diambil (order_status ready), urut dari yang paling lama
+// siap. Untuk layar antrian pelanggan, jadi hanya nomor pesanan tanpa data pelanggan.
+func (s TransactionService) GetNowServing() (*dto.NowServingResponse, error) {
+	today := config.StoreCalendar.Today()
+	var transactions []transaction_model.Transaction
+	if err := config.DB.Select("order_number", "order_type", "updated_at").
+		Where("business_date = ? AND order_status = ? AND order_number <> ''", today, OrderStatusReady).
+		Order("updated_at ASC").
+		Find(&transactions).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	res := &dto.NowServingResponse{
+		BusinessDate: today.Format("2006-01-02"),
+		Ready:        make([]dto.NowServingOrder, 0, len(transactions)),
+	}
+	for _, t := range transactions {
+		res.Ready = append(res.Ready, dto.NowServingOrder{
+			OrderNumber: t.OrderNumber,
+			OrderType:   t.OrderType,
+			ReadyAt:     config.StoreCalendar.Format(t.UpdatedAt),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status pembayaran (payment_status)
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusPaid      = "paid"
+	PaymentStatusCancelled = "cancelled"
+	PaymentStatusExpired   = "expired"
+
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded          = "refunded"
+)
+
+// PaidPaymentStatuses status pembayaran yang berarti uang pernah diterima (dipakai laporan & settlement;
+// nominal refund dikurangkan terpisah).
+var PaidPaymentStatuses = []string{PaymentStatusPaid, PaymentStatusPartiallyRefunded, PaymentStatusRefunded}
+
+// Status pesanan (order_status)
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusCooking   = "cooking"
+	OrderStatusReady     = "ready"
+	OrderStatusCompleted = "completed"
+	OrderStatusCancelled = "cancelled"
+)
+
+var (
+	ErrInvalidTransition = errors.New("Perubahan status tidak diizinkan")
+	ErrOrderNotPaid      = errors.New("Transaksi belum berstatus paid, tidak dapat diselesaikan")
+)
+
+// paymentTransitions tabel transisi payment_status yang sah (from -> daftar to).
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending:           {PaymentStatusPaid, PaymentStatusCancelled, PaymentStatusExpired},
+	PaymentStatusPaid:              {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusRefunded},
+}
+
+// orderTransitions tabel transisi order_status yang sah (from -> daftar to).
+// ready -> cooking sengaja tidak ada di sini; hanya AddOpenBillItems yang membuka lagi pesanan ready (lihat reopenOrder).
+var orderTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusCooking, OrderStatusCancelled},
+	OrderStatusCooking: {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:   {OrderStatusCompleted, OrderStatusCancelled},
+}
+
+// TransitionError dikembalikan jika perubahan status tidak ada di tabel transisi.
+// errors.Is(err, ErrInvalidTransition) bernilai true untuk error ini.
+type TransitionError struct {
+	Field string // payment_status | order_status
+	From  string
+	To    string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("Perubahan %s dari %s ke %s tidak diizinkan", e.Field, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+func allowedTransition(table map[string][]string, from, to string) bool {
+	for _, next := range table[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransitionPayment true jika payment_status boleh berubah dari -> ke (status sama dianggap no-op yang sah).
+func CanTransitionPayment(from, to string) bool {
+	return from == to || allowedTransition(paymentTransitions, from, to)
+}
+
+// CanTransitionOrder true jika order_status boleh berubah dari -> ke (status sama dianggap no-op yang sah).
+func CanTransitionOrder(from, to string) bool {
+	return from == to || allowedTransition(orderTransitions, from, to)
+}
+
+// ValidateStatusTransition memeriksa perubahan (payment, order) terhadap tabel transisi dan aturan silang
+// antar keduanya. String kosong berarti status tersebut tidak diubah.
+func ValidateStatusTransition(currentPayment, currentOrder, nextPayment, nextOrder string) error {
+	if nextPayment == "" {
+		nextPayment = currentPayment
+	}
+	if nextOrder == "" {
+		nextOrder = currentOrder
+	}
+
+	if !CanTransitionPayment(currentPayment, nextPayment) {
+		return &TransitionError{Field: "payment_status", From: currentPayment, To: nextPayment}
+	}
+	if !CanTransitionOrder(currentOrder, nextOrder) {
+		return &TransitionError{Field: "order_status", From: currentOrder, To: nextOrder}
+	}
+
+	// Pesanan yang sudah dibatalkan tidak boleh "hidup lagi" karena pembayaran masuk
+	if nextPayment == PaymentStatusPaid && currentPayment != PaymentStatusPaid && nextOrder == OrderStatusCancelled {
+		return &TransitionError{Field: "payment_status", From: currentPayment, To: nextPayment}
+	}
+	// Pembayaran batal / kadaluarsa berarti pesanan ikut batal
+	if (nextPayment == PaymentStatusCancelled || nextPayment == PaymentStatusExpired) && nextOrder != OrderStatusCancelled {
+		return &TransitionError{Field: "order_status", From: currentOrder, To: nextOrder}
+	}
+	// Hanya boleh selesai jika sudah dibayar (refund sebagian tetap dianggap dibayar)
+	if nextOrder == OrderStatusCompleted && currentOrder != OrderStatusCompleted &&
+		nextPayment != PaymentStatusPaid && nextPayment != PaymentStatusPartiallyRefunded {
+		return ErrOrderNotPaid
+	}
+
+	return nil
+}
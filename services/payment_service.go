@@ -0,0 +1,521 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/snap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status satu payment (tender)
+const (
+	TenderStatusPending   = "pending"
+	TenderStatusPaid      = "paid"
+	TenderStatusFailed    = "failed"
+	TenderStatusExpired   = "expired"
+	TenderStatusCancelled = "cancelled"
+)
+
+// PaymentMethodSplit payment_method transaksi yang lunas dengan lebih dari satu metode bayar
+const PaymentMethodSplit = "split"
+
+// Sentinel errors
+var (
+	ErrPaymentNotFound         = errors.New("Pembayaran tidak ditemukan")
+	ErrPaymentNotAllowed       = errors.New("Transaksi tidak sedang menunggu pembayaran")
+	ErrPaymentExceedsRemaining = errors.New("Nominal pembayaran melebihi sisa tagihan")
+	ErrPaymentNotPending       = errors.New("Pembayaran sudah tidak pending")
+	ErrPendingTenderExists     = errors.New("Masih ada pembayaran non-tunai yang menunggu, selesaikan atau batalkan dulu")
+	ErrInvalidSplit            = errors.New("Pembagian tagihan tidak valid")
+	ErrTenderedBelowAmount     = errors.New("Uang yang diterima kurang dari nominal yang harus dibayar")
+)
+
+type PaymentService struct{}
+
+func NewPaymentService() PaymentService {
+	return PaymentService{}
+}
+
+// lockPayableTransaction mengambil transaksi dengan row lock dan memastikan masih menunggu pembayaran
+func lockPayableTransaction(tx *gorm.DB, id uuid.UUID) (transaction_model.Transaction, error) {
+	var transaction transaction_model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return transaction_model.Transaction{}, ErrTransactionNotFound
+		}
+		return transaction_model.Transaction{}, ErrDatabaseError
+	}
+	if transaction.PaymentStatus != PaymentStatusPending || transaction.OrderStatus == OrderStatusCancelled {
+		return transaction_model.Transaction{}, ErrPaymentNotAllowed
+	}
+	return transaction, nil
+}
+
+// loadPayments payment transaksi urut waktu dibuat
+func loadPayments(db *gorm.DB, transactionID uuid.UUID) ([]transaction_model.Payment, error) {
+	var payments []transaction_model.Payment
+	if err := db.Where("transaction_id = ?", transactionID).Order("created_at ASC").Find(&payments).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return payments, nil
+}
+
+// sumPayments total payment yang sudah paid dan yang masih pending (menahan sebagian sisa tagihan)
+func sumPayments(payments []transaction_model.Payment) (paid, pending utils.Money) {
+	for _, p := range payments {
+		switch p.Status {
+		case TenderStatusPaid:
+			paid += p.Amount
+		case TenderStatusPending:
+			pending += p.Amount
+		}
+	}
+	return paid, pending
+}
+
+// settleTransactionPayments menandai transaksi paid jika payment yang paid sudah menutup TotalAmount.
+// payment_method transaksi diisi metode tender (atau "split" jika lebih dari satu metode) agar laporan lama tetap benar.
+func settleTransactionPayments(tx *gorm.DB, transaction *transaction_model.Transaction, cashierID *uuid.UUID) error {
+	if transaction.PaymentStatus != PaymentStatusPending {
+		return nil
+	}
+	payments, err := loadPayments(tx, transaction.ID)
+	if err != nil {
+		return err
+	}
+	paid, _ := sumPayments(payments)
+	if paid < transaction.TotalAmount {
+		return nil
+	}
+
+	method := ""
+	for _, p := range payments {
+		if p.Status != TenderStatusPaid {
+			continue
+		}
+		if method == "" {
+			method = p.Method
+		} else if method != p.Method {
+			method = PaymentMethodSplit
+		}
+	}
+	extra := map[string]interface{}{"payment_method": method, "is_open_bill": false}
+	if cashierID != nil {
+		extra["closed_by_user_id"] = cashierID
+	}
+	return applyStatusTransition(tx, transaction, PaymentStatusPaid, "", extra)
+}
+
+// recordRemainingPayment mencatat satu payment paid untuk sisa tagihan (bayar lunas tanpa split:
+// konfirmasi tunai kasir atau webhook Snap tagihan penuh). Tidak mencatat apa pun jika sudah tertutup.
+func recordRemainingPayment(tx *gorm.DB, transaction transaction_model.Transaction, method, externalRef string, cashierID *uuid.UUID) error {
+	payments, err := loadPayments(tx, transaction.ID)
+	if err != nil {
+		return err
+	}
+	paid, _ := sumPayments(payments)
+	remaining := transaction.TotalAmount - paid
+	if remaining <= 0 {
+		return nil
+	}
+	now := time.Now()
+	payment := transaction_model.Payment{
+		TransactionID: transaction.ID,
+		Method:        method,
+		Amount:        remaining,
+		Status:        TenderStatusPaid,
+		ExternalRef:   externalRef,
+		CashierUserID: cashierID,
+		PaidAt:        &now,
+	}
+	if err := tx.Create(&payment).Error; err != nil {
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+// paymentSnapToken token Snap untuk satu payment non-cash (order_id = ExternalRef, gross = nominal payment)
+func paymentSnapToken(transaction transaction_model.Transaction, payment transaction_model.Payment) (string, string, error) {
+	name := "Pembayaran sebagian"
+	if payment.Label != "" {
+		name += " - " + payment.Label
+	}
+	itemDetails := []midtrans.ItemDetails{{
+		ID:    "PAY",
+		Name:  midtransItemName(name),
+		Price: payment.Amount.Int64(),
+		Qty:   1,
+	}}
+	return requestSnapToken(&snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  payment.ExternalRef,
+			GrossAmt: payment.Amount.Int64(),
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: transaction.CustomerName,
+			Phone: transaction.CustomerPhone,
+		},
+		Items: &itemDetails,
+	})
+}
+
+// cancelSnapOrder membatalkan order Snap yang tidak dipakai lagi (best effort: order yang belum dibayar
+// customer bisa belum terdaftar di Midtrans, sehingga error hanya dicatat).
+func cancelSnapOrder(orderID string) {
+	if _, err := config.MidtransCoreClient.CancelTransaction(orderID); err != nil {
+		log.Printf("Gagal membatalkan order Midtrans %s: %s", orderID, err.GetMessage())
+	}
+}
+
+// CreatePayment mencatat satu tender. Tunai langsung paid (kasir menerima uang); non-cash dibuatkan Snap token
+// sendiri dan menjadi paid lewat webhook. Transaksi paid saat seluruh tender paid menutup TotalAmount.
+// Tender pertama menutup open bill dan membatalkan token Snap tagihan penuh (jika ada).
+func (s PaymentService) CreatePayment(transactionID uuid.UUID, req dto.CreatePaymentRequest, cashierID *uuid.UUID) (*dto.PaymentResponse, error) {
+	var payment transaction_model.Payment
+	var previous transaction_model.Transaction
+	var snapToken, snapURL, staleOrderID string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockPayableTransaction(tx, transactionID)
+		if err != nil {
+			return err
+		}
+		previous = transaction
+
+		payments, err := loadPayments(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		paid, pending := sumPayments(payments)
+		remaining := transaction.TotalAmount - paid - pending
+		if req.Amount > remaining {
+			return fmt.Errorf("%w: sisa Rp %s", ErrPaymentExceedsRemaining, remaining)
+		}
+
+		// Tagihan mulai dibayar per tender: item tidak bisa ditambah lagi dan token tagihan penuh tidak berlaku
+		if len(payments) == 0 {
+			if transaction.PaymentMethod != "cash" && transaction.ExpiredAt != nil {
+				staleOrderID = midtransOrderID(transaction)
+			}
+			if err := tx.Model(&transaction_model.Transaction{}).Where("id = ?", transaction.ID).
+				Updates(map[string]interface{}{"is_open_bill": false, "expired_at": nil}).Error; err != nil {
+				return ErrDatabaseError
+			}
+			transaction.IsOpenBill = false
+			transaction.ExpiredAt = nil
+		}
+
+		payment = transaction_model.Payment{
+			ID:            uuid.New(),
+			TransactionID: transaction.ID,
+			Method:        req.Method,
+			Amount:        req.Amount,
+			Status:        TenderStatusPending,
+			Label:         req.Label,
+			CashierUserID: cashierID,
+		}
+		if req.Method == "cash" {
+			tendered := req.AmountTendered
+			if tendered == 0 {
+				tendered = req.Amount
+			}
+			if tendered < req.Amount {
+				return fmt.Errorf("%w: Rp %s", ErrTenderedBelowAmount, req.Amount)
+			}
+			now := time.Now()
+			payment.Status = TenderStatusPaid
+			payment.PaidAt = &now
+			payment.AmountTendered = tendered
+			payment.ChangeAmount = tendered - req.Amount
+		} else {
+			payment.ExternalRef = fmt.Sprintf("%s-%s", transaction.ID, payment.ID.String()[:8])
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return ErrDatabaseError
+		}
+
+		if req.Method != "cash" {
+			// Token diminta sebelum commit: jika Midtrans gagal, payment tidak tersimpan
+			snapToken, snapURL, err = paymentSnapToken(transaction, payment)
+			return err
+		}
+		return settleTransactionPayments(tx, &transaction, cashierID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if staleOrderID != "" {
+		cancelSnapOrder(staleOrderID)
+	}
+	var after transaction_model.Transaction
+	if err := config.DB.Preload("Items.Modifiers").First(&after, "id = ?", transactionID).Error; err == nil {
+		publishStatusChange(&previous, &after)
+	}
+
+	res := toPaymentResponse(payment)
+	res.SnapToken = snapToken
+	res.SnapURL = snapURL
+	return &res, nil
+}
+
+// UpdatePaymentStatus status payment dari webhook Midtrans. Payment paid bisa melunasi transaksi;
+// payment gagal / kadaluarsa hanya membebaskan nominalnya (pesanan tidak dibatalkan).
+func (s PaymentService) UpdatePaymentStatus(paymentID uuid.UUID, status string) error {
+	var previous, transaction transaction_model.Transaction
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var payment transaction_model.Payment
+		if err := tx.First(&payment, "id = ?", paymentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return ErrDatabaseError
+		}
+		// Lock transaksi dulu (urutan sama dengan CreatePayment) agar tidak deadlock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", payment.TransactionID).Error; err != nil {
+			return ErrDatabaseError
+		}
+		previous = transaction
+
+		updates := map[string]interface{}{"status": status}
+		if status == TenderStatusPaid {
+			updates["paid_at"] = time.Now()
+		}
+		res := tx.Model(&transaction_model.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, TenderStatusPending).
+			Updates(updates)
+		if res.Error != nil {
+			return ErrDatabaseError
+		}
+		if res.RowsAffected == 0 {
+			var current transaction_model.Payment
+			if err := tx.Select("status").First(&current, "id = ?", payment.ID).Error; err == nil && current.Status == status {
+				return nil // notifikasi dikirim ulang
+			}
+			return &TransitionError{Field: "payment", From: payment.Status, To: status}
+		}
+
+		if status != TenderStatusPaid {
+			return nil
+		}
+		if transaction.PaymentStatus != PaymentStatusPending || transaction.OrderStatus == OrderStatusCancelled {
+			log.Printf("Payment %s dibayar tetapi transaksi %s sudah %s/%s, perlu refund manual",
+				payment.ID, transaction.ID, transaction.PaymentStatus, transaction.OrderStatus)
+			return nil
+		}
+		return settleTransactionPayments(tx, &transaction, payment.CashierUserID)
+	})
+	if err != nil {
+		return err
+	}
+
+	var after transaction_model.Transaction
+	if err := config.DB.Preload("Items.Modifiers").First(&after, "id = ?", transaction.ID).Error; err == nil {
+		publishStatusChange(&previous, &after)
+	}
+	return nil
+}
+
+// CancelPayment membatalkan payment non-cash yang masih pending (mis. customer ganti metode bayar);
+// nominalnya kembali menjadi sisa tagihan.
+func (s PaymentService) CancelPayment(transactionID, paymentID uuid.UUID) error {
+	var payment transaction_model.Payment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockPayableTransaction(tx, transactionID); err != nil {
+			return err
+		}
+		if err := tx.First(&payment, "id = ? AND transaction_id = ?", paymentID, transactionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return ErrDatabaseError
+		}
+		res := tx.Model(&transaction_model.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, TenderStatusPending).
+			Update("status", TenderStatusCancelled)
+		if res.Error != nil {
+			return ErrDatabaseError
+		}
+		if res.RowsAffected == 0 {
+			return ErrPaymentNotPending
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if payment.ExternalRef != "" {
+		cancelSnapOrder(payment.ExternalRef)
+	}
+	return nil
+}
+
+// GetPayments ringkasan dan daftar payment satu transaksi
+func (s PaymentService) GetPayments(transactionID uuid.UUID) (*dto.TransactionPaymentsResponse, error) {
+	var transaction transaction_model.Transaction
+	if err := config.DB.First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+	payments, err := loadPayments(config.DB, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	paid, pending := sumPayments(payments)
+	remaining := transaction.TotalAmount - paid - pending
+	if remaining < 0 {
+		remaining = 0
+	}
+	res := &dto.TransactionPaymentsResponse{
+		TransactionID:   transaction.ID,
+		TotalAmount:     transaction.TotalAmount,
+		PaidAmount:      paid,
+		PendingAmount:   pending,
+		RemainingAmount: remaining,
+		PaymentStatus:   transaction.PaymentStatus,
+		Payments:        make([]dto.PaymentResponse, 0, len(payments)),
+	}
+	for _, p := range payments {
+		res.Payments = append(res.Payments, toPaymentResponse(p))
+	}
+	return res, nil
+}
+
+// SplitBill pratinjau pembagian tagihan. even: sisa tagihan dibagi rata ke parts orang (selisih pembulatan
+// dibagi per rupiah). item: tiap grup menanggung item yang dipilih, porsinya proporsional terhadap total transaksi
+// (diskon, service charge, dan pajak ikut terbagi); item yang tidak dipilih menjadi bagian "Sisa".
+// Mode item hanya untuk tagihan yang belum punya tender: setelah ada pembayaran, item mana yang sudah lunas tidak diketahui.
+func (s PaymentService) SplitBill(transactionID uuid.UUID, req dto.SplitBillRequest) (*dto.SplitBillResponse, error) {
+	var transaction transaction_model.Transaction
+	if err := config.DB.Preload("Items").First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+	if transaction.PaymentStatus != PaymentStatusPending || transaction.OrderStatus == OrderStatusCancelled {
+		return nil, ErrPaymentNotAllowed
+	}
+	payments, err := loadPayments(config.DB, transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+	paid, pending := sumPayments(payments)
+	remaining := transaction.TotalAmount - paid - pending
+
+	res := &dto.SplitBillResponse{
+		Mode:            req.Mode,
+		TotalAmount:     transaction.TotalAmount,
+		RemainingAmount: remaining,
+	}
+
+	if req.Mode == "even" {
+		if req.Parts < 2 {
+			return nil, fmt.Errorf("%w: parts minimal 2", ErrInvalidSplit)
+		}
+		weights := make([]utils.Money, req.Parts)
+		for i := range weights {
+			weights[i] = 1
+		}
+		for i, amount := range utils.Allocate(remaining, weights) {
+			res.Parts = append(res.Parts, dto.SplitPartResponse{Label: fmt.Sprintf("Bagian %d", i+1), Amount: amount})
+		}
+		return res, nil
+	}
+
+	if len(req.Groups) == 0 {
+		return nil, fmt.Errorf("%w: groups wajib diisi", ErrInvalidSplit)
+	}
+	if paid+pending > 0 {
+		return nil, fmt.Errorf("%w: tagihan sudah dibayar sebagian, gunakan mode even untuk sisa tagihan", ErrInvalidSplit)
+	}
+	items := make(map[uuid.UUID]transaction_model.TransactionItem, len(transaction.Items))
+	left := make(map[uuid.UUID]int, len(transaction.Items))
+	for _, it := range transaction.Items {
+		items[it.ID] = it
+		left[it.ID] = it.Quantity
+	}
+
+	// Satu bobot per (grup, item) ditambah sisa item yang tidak dipilih, dialokasikan dari TotalAmount
+	type line struct {
+		group int
+		item  transaction_model.TransactionItem
+		qty   int
+	}
+	var lines []line
+	var weights []utils.Money
+	for gi, g := range req.Groups {
+		for _, in := range g.Items {
+			it, ok := items[in.TransactionItemID]
+			if !ok || in.Quantity > left[it.ID] {
+				return nil, fmt.Errorf("%w: item tidak ditemukan atau qty melebihi pesanan", ErrInvalidSplit)
+			}
+			left[it.ID] -= in.Quantity
+			lines = append(lines, line{group: gi, item: it, qty: in.Quantity})
+			weights = append(weights, (it.MenuPrice + it.ModifierPrice).Mul(in.Quantity))
+		}
+	}
+	for _, it := range transaction.Items {
+		if left[it.ID] > 0 {
+			lines = append(lines, line{group: len(req.Groups), item: it, qty: left[it.ID]})
+			weights = append(weights, (it.MenuPrice + it.ModifierPrice).Mul(left[it.ID]))
+		}
+	}
+
+	parts := make([]dto.SplitPartResponse, len(req.Groups), len(req.Groups)+1)
+	for gi, g := range req.Groups {
+		parts[gi].Label = g.Label
+		if parts[gi].Label == "" {
+			parts[gi].Label = fmt.Sprintf("Bagian %d", gi+1)
+		}
+	}
+	for i, amount := range utils.Allocate(transaction.TotalAmount, weights) {
+		l := lines[i]
+		if l.group == len(parts) {
+			parts = append(parts, dto.SplitPartResponse{Label: "Sisa"})
+		}
+		parts[l.group].Amount += amount
+		parts[l.group].Items = append(parts[l.group].Items, dto.SplitItemResponse{
+			TransactionItemID: l.item.ID,
+			MenuName:          l.item.MenuName,
+			Quantity:          l.qty,
+			Amount:            amount,
+		})
+	}
+	res.Parts = parts
+	return res, nil
+}
+
+func toPaymentResponse(p transaction_model.Payment) dto.PaymentResponse {
+	res := dto.PaymentResponse{
+		ID:             p.ID,
+		Method:         p.Method,
+		Amount:         p.Amount,
+		Status:         p.Status,
+		Label:          p.Label,
+		ExternalRef:    p.ExternalRef,
+		CashierUserID:  p.CashierUserID,
+		AmountTendered: p.AmountTendered,
+		ChangeAmount:   p.ChangeAmount,
+		RefundedAmount: p.RefundedAmount,
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.PaidAt != nil {
+		paidAt := p.PaidAt.Format(time.RFC3339)
+		res.PaidAt = &paidAt
+	}
+	return res
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPrinterNotConfigured = errors.New("Alamat printer belum diatur")
+	ErrPrinterUnreachable   = errors.New("Printer tidak bisa dihubungi")
+	ErrNoKitchenItems       = errors.New("Tidak ada item untuk stasiun / ronde tersebut")
+)
+
+// printerTimeout batas waktu koneksi & kirim data ke printer jaringan
+const printerTimeout = 5 * time.Second
+
+// RenderReceiptESCPOS struk pembayaran sebagai byte stream ESC/POS: logo, header toko, item, total, pembayaran,
+// footer (config.Printer) dan QR berisi qr (kosong = tanpa QR). paperWidth 58 / 80 mm; 0 = default dari config.
+func RenderReceiptESCPOS(r *dto.ReceiptResponse, paperWidth int, qr string) []byte {
+	if paperWidth == 0 {
+		paperWidth = config.Printer.PaperWidth
+	}
+	w := utils.NewESCPOSWriter(paperWidth)
+
+	w.Align(utils.AlignCenter)
+	if config.Printer.Logo != nil {
+		w.Image(config.Printer.Logo)
+	}
+	for i, l := range config.Printer.ReceiptHeader {
+		w.Bold(i == 0)
+		w.Line(l)
+	}
+	w.Bold(false)
+	w.Align(utils.AlignLeft)
+	w.Rule('-')
+
+	w.Columns2("No", orderLabel(r.OrderNumber, r.ID))
+	w.Columns2("Waktu", receiptTime(r.CreatedAt))
+	w.Columns2("Kasir", r.ClosedByUserName)
+	if r.CustomerName != "" {
+		w.Columns2("Pelanggan", r.CustomerName)
+	}
+	orderType := r.OrderType
+	if r.TableNumber != nil {
+		orderType += fmt.Sprintf(" - Meja %d", *r.TableNumber)
+	}
+	w.Columns2("Jenis", orderType)
+	w.Rule('-')
+
+	for _, it := range r.Items {
+		w.Line(it.MenuName)
+		for _, m := range it.Modifiers {
+			w.Line("  + " + m.OptionName)
+		}
+		w.Columns2(fmt.Sprintf("  %d x %s", it.Quantity, it.MenuPrice.Rupiah()), it.Subtotal.Rupiah())
+	}
+	w.Rule('-')
+
+	w.Columns2("Subtotal", r.Subtotal.Rupiah())
+	if r.Discount > 0 {
+		w.Columns2("Diskon", "-"+r.Discount.Rupiah())
+	}
+	for _, t := range r.Taxes {
+		label := t.Name
+		if t.IsInclusive {
+			label += " (termasuk)"
+		}
+		w.Columns2(label, t.Amount.Rupiah())
+	}
+	if len(r.Taxes) == 0 {
+		if r.ServiceCharge > 0 {
+			w.Columns2("Service charge", r.ServiceCharge.Rupiah())
+		}
+		if r.Tax > 0 {
+			w.Columns2("Pajak", r.Tax.Rupiah())
+		}
+	}
+	w.Bold(true)
+	w.Columns2("TOTAL", r.TotalAmount.Rupiah())
+	w.Bold(false)
+	w.Columns2("Bayar ("+r.PaymentMethod+")", r.PaymentStatus)
+	if r.AmountTendered > 0 {
+		w.Columns2("Tunai", r.AmountTendered.Rupiah())
+		w.Columns2("Kembali", r.ChangeAmount.Rupiah())
+	}
+	w.Rule('-')
+
+	w.Align(utils.AlignCenter)
+	for _, l := range config.Printer.ReceiptFooter {
+		w.Line(l)
+	}
+	if qr != "" {
+		w.QR(qr, 6)
+	}
+	w.Feed(3)
+	w.Cut()
+	return w.Bytes()
+}
+
+// ReceiptQRData isi QR struk cetak: link struk digital jika RECEIPT_PUBLIC_URL diatur, selain itu teks statis RECEIPT_QR.
+// Token aktif dipakai ulang; create = false (unduh struk) tidak membuat token baru sehingga link yang sudah dicabut
+// tidak muncul lagi, dan tanpa token aktif QR kembali ke teks statis.
+func (s TransactionService) ReceiptQRData(transactionID uuid.UUID, userID *uuid.UUID, create bool) (string, error) {
+	if config.ReceiptPublicURL == "" {
+		return config.Printer.ReceiptQR, nil
+	}
+	if !create {
+		token, err := activeReceiptToken(config.DB, transactionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return config.Printer.ReceiptQR, nil
+		}
+		if err != nil {
+			return "", ErrDatabaseError
+		}
+		return receiptTokenResponse(*token).URL, nil
+	}
+	token, err := s.EnsureReceiptToken(transactionID, userID)
+	if err != nil {
+		return "", err
+	}
+	return token.URL, nil
+}
+
+// GetKitchenTicket transaksi dengan item untuk tiket dapur: hanya item stasiun station (kosong = semua)
+// dan ronde batch (0 = semua ronde)
+func (s TransactionService) GetKitchenTicket(id uuid.UUID, station string, batch int) (*transaction_model.Transaction, error) {
+	tx, err := s.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]transaction_model.TransactionItem, 0, len(tx.Items))
+	for _, it := range tx.Items {
+		if (station == "" || it.Station == station) && (batch == 0 || it.Batch == batch) {
+			items = append(items, it)
+		}
+	}
+	if len(items) == 0 {
+		return nil, ErrNoKitchenItems
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Batch < items[j].Batch })
+	tx.Items = items
+	return tx, nil
+}
+
+// RenderKitchenTicketESCPOS tiket dapur: meja / jenis, nomor pesanan harian, dan item dengan huruf besar beserta modifier & catatan.
+// printedAt dicetak di bawah tiket (waktu cetak).
+func RenderKitchenTicketESCPOS(tx *transaction_model.Transaction, station string, paperWidth int, printedAt time.Time) []byte {
+	if paperWidth == 0 {
+		paperWidth = config.Printer.PaperWidth
+	}
+	w := utils.NewESCPOSWriter(paperWidth)
+
+	w.Align(utils.AlignCenter)
+	w.Bold(true)
+	w.Size(2, 2)
+	if tx.TableNumber != nil {
+		w.Line(fmt.Sprintf("MEJA %d", *tx.TableNumber))
+	} else {
+		w.Line(strings.ToUpper(tx.OrderType))
+	}
+	w.Line("No. " + orderLabel(tx.OrderNumber, tx.ID))
+	w.Size(1, 1)
+	w.Bold(false)
+	if station != "" {
+		w.Line("Stasiun: " + station)
+	}
+	w.Align(utils.AlignLeft)
+	w.Columns2(tx.CustomerName, tx.CreatedAt.In(config.StoreCalendar.Location).Format("15:04"))
+	w.Rule('=')
+
+	batch := -1
+	for _, it := range tx.Items {
+		if it.Batch != batch {
+			batch = it.Batch
+			if batch > 1 {
+				w.Bold(true)
+				w.Line(fmt.Sprintf("-- Tambahan ronde %d --", batch))
+				w.Bold(false)
+			}
+		}
+		w.Size(2, 2)
+		w.Bold(true)
+		w.Line(fmt.Sprintf("%dx %s", it.Quantity, it.MenuName))
+		w.Bold(false)
+		w.Size(1, 2)
+		for _, m := range it.Modifiers {
+			w.Line("   + " + m.GroupName + ": " + m.OptionName)
+		}
+		if it.Notes != "" {
+			w.Line("   ! " + it.Notes)
+		}
+		w.Size(1, 1)
+		w.Rule('-')
+	}
+	w.Line("Dicetak " + printedAt.In(config.StoreCalendar.Location).Format("15:04:05"))
+	w.Feed(3)
+	w.Cut()
+	return w.Bytes()
+}
+
+// SendToPrinter mengirim byte stream ke printer jaringan lewat raw TCP (umumnya port 9100)
+func SendToPrinter(addr string, data []byte) error {
+	if addr == "" {
+		return ErrPrinterNotConfigured
+	}
+	conn, err := net.DialTimeout("tcp", addr, printerTimeout)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPrinterUnreachable, err)
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(printerTimeout))
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("%w: %v", ErrPrinterUnreachable, err)
+	}
+	return nil
+}
+
+// shortID 8 karakter awal ID transaksi (pengganti nomor pesanan untuk transaksi lama)
+func shortID(id uuid.UUID) string {
+	return strings.ToUpper(id.String()[:8])
+}
+
+// receiptTime "2006-01-02 15:04" dari timestamp RFC3339 struk
+func receiptTime(rfc3339 string) string {
+	t, err := time.Parse(time.RFC3339, rfc3339)
+	if err != nil {
+		return rfc3339
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var updateGolden = flag.Bool("update", false, "tulis ulang file golden di testdata/")
+
+// assertGolden membandingkan got byte per byte dengan testdata/name (atau menulisnya dengan -update)
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v (jalankan go test -run %s -update)", path, err, t.Name())
+	}
+	if !bytes.Equal(got, want) {
+		i := 0
+		for i < len(got) && i < len(want) && got[i] == want[i] {
+			i++
+		}
+		t.Fatalf("%s: output berbeda mulai byte %d (panjang %d, golden %d)", name, i, len(got), len(want))
+	}
+}
+
+// usePrinterFixture pengaturan toko & printer tetap (header, footer, logo, zona WIB) selama test
+func usePrinterFixture(t *testing.T) {
+	t.Helper()
+	previousPrinter, previousCalendar := config.Printer, config.StoreCalendar
+	t.Cleanup(func() {
+		config.Printer = previousPrinter
+		config.StoreCalendar = previousCalendar
+	})
+
+	logo := image.NewGray(image.Rect(0, 0, 24, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 24; x++ {
+			if (x/4+y/4)%2 == 0 {
+				logo.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				logo.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	config.Printer = config.PrinterConfig{
+		PaperWidth:    58,
+		ReceiptHeader: []string{"Warung Kopi Nusantara", "Jl. Merdeka No. 1, Jakarta"},
+		ReceiptFooter: []string{"Terima kasih atas kunjungan Anda", "Wi-Fi: kopinusantara"},
+		Logo:          logo,
+	}
+	config.StoreCalendar = utils.BusinessCalendar{Location: time.FixedZone("WIB", 7*3600)}
+}
+
+func receiptFixture() *dto.ReceiptResponse {
+	table := 7
+	return &dto.ReceiptResponse{
+		ID:           uuid.MustParse("3f2b8c1e-9a4d-4e5f-8b6a-1c2d3e4f5a6b"),
+		OrderNumber:  "A-042",
+		CreatedAt:    "2024-05-01T19:30:00+07:00",
+		CustomerName: "José",
+		OrderType:    "dine_in",
+		TableNumber:  &table,
+		Items: []dto.ReceiptItemResponse{
+			{
+				MenuName:  "Nasi Goreng Spesial",
+				Modifiers: []dto.ItemModifierResponse{{GroupName: "Level", OptionName: "Pedas 3"}, {GroupName: "Topping", OptionName: "Telur Mata Sapi"}},
+				Quantity:  2,
+				MenuPrice: 32000,
+				Subtotal:  64000,
+			},
+			{MenuName: "Es Kopi Susu Gula Aren dengan Nama yang Sangat Panjang Sekali", Quantity: 1, MenuPrice: 28000, Subtotal: 28000},
+			{MenuName: "Air Mineral", Quantity: 3, MenuPrice: 5000, Subtotal: 15000},
+		},
+		Subtotal:      97000,
+		Discount:      10000,
+		ServiceCharge: 4850,
+		Tax:           11204,
+		Taxes: []dto.TransactionTaxResponse{
+			{Name: "Service Charge", Type: TaxTypeServiceCharge, Rate: 5, Amount: 4850},
+			{Name: "PPN", Type: TaxTypeTax, Rate: 11, Amount: 11204},
+		},
+		TotalAmount:      113054,
+		PaymentMethod:    "cash",
+		PaymentStatus:    PaymentStatusPaid,
+		AmountTendered:   150000,
+		ChangeAmount:     36946,
+		ClosedByUserName: "Siti",
+	}
+}
+
+func kitchenTicketFixture() *transaction_model.Transaction {
+	return &transaction_model.Transaction{
+		ID:           uuid.MustParse("3f2b8c1e-9a4d-4e5f-8b6a-1c2d3e4f5a6b"),
+		OrderNumber:  "A-042",
+		CustomerName: "Budi",
+		OrderType:    "take_away",
+		CreatedAt:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Items: []transaction_model.TransactionItem{
+			{
+				MenuName: "Nasi Goreng Spesial",
+				Quantity: 2,
+				Batch:    1,
+				Station:  "kitchen",
+				Notes:    "tanpa bawang",
+				Modifiers: []transaction_model.TransactionItemModifier{
+					{GroupName: "Level", OptionName: "Pedas 3", SortOrder: 0},
+					{GroupName: "Topping", OptionName: "Telur Mata Sapi", SortOrder: 1},
+				},
+			},
+			{MenuName: "Mie Goreng", Quantity: 1, Batch: 1, Station: "kitchen"},
+			{MenuName: "Pisang Goreng Keju Cokelat", Quantity: 3, Batch: 2, Station: "kitchen", Notes: "bungkus terpisah"},
+		},
+	}
+}
+
+func TestRenderReceiptESCPOSGolden(t *testing.T) {
+	usePrinterFixture(t)
+	for _, width := range []int{58, 80} {
+		got := RenderReceiptESCPOS(receiptFixture(), width, "https://pos.example.com/receipt/Zx9aQ2")
+		assertGolden(t, fmt.Sprintf("receipt_%dmm.bin", width), got)
+	}
+}
+
+func TestRenderReceiptESCPOSLegacyTransaction(t *testing.T) {
+	usePrinterFixture(t)
+	r := receiptFixture()
+	r.OrderNumber = ""
+	got := RenderReceiptESCPOS(r, 58, "")
+	if !bytes.Contains(got, []byte("3F2B8C1E")) {
+		t.Fatal("struk transaksi tanpa nomor pesanan harus memakai ID pendek")
+	}
+	if bytes.Contains(got, []byte{0x1D, 0x28, 0x6B}) {
+		t.Fatal("QR tidak boleh dicetak jika isi QR kosong")
+	}
+}
+
+func TestRenderKitchenTicketESCPOSGolden(t *testing.T) {
+	usePrinterFixture(t)
+	printedAt := time.Date(2024, 5, 1, 12, 31, 5, 0, time.UTC)
+	for _, width := range []int{58, 80} {
+		got := RenderKitchenTicketESCPOS(kitchenTicketFixture(), "kitchen", width, printedAt)
+		assertGolden(t, fmt.Sprintf("kitchen_ticket_%dmm.bin", width), got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"pos-go/config"
 	"pos-go/dto"
 	promo_model "pos-go/models/promo_model"
+	"pos-go/utils"
 	"strings"
 	"time"
 
@@ -31,10 +32,10 @@ type PromoService interface {
 	CreatePromo(input dto.CreatePromoDTO) (promo_model.Promo, error)
 	UpdatePromo(promoID string, input dto.UpdatePromoDTO) (promo_model.Promo, error)
 	DeletePromo(promoID string) error
-	GetAllPromos() ([]promo_model.Promo, error)
+	GetAllPromos(filter dto.PromoListFilter, page utils.PageQuery) ([]promo_model.Promo, int64, error)
 	GetPromoByID(promoID string) (promo_model.Promo, error)
 	GetActivePromos() ([]promo_model.Promo, error)
-	ValidatePromo(code string, subtotal float64) (promo_model.Promo, float64, error)
+	ValidatePromo(code string, subtotal utils.Money) (promo_model.Promo, utils.Money, error)
 }
 
 type promoService struct{}
@@ -80,15 +81,33 @@ func (s *promoService) CreatePromo(input dto.CreatePromoDTO) (promo_model.Promo,
 	return promo, nil
 }
 
-// GetAllPromos mengambil semua promo
-func (s *promoService) GetAllPromos() ([]promo_model.Promo, error) {
+// PromoSortColumns kolom yang boleh dipakai untuk sort_by pada GET /promo
+var PromoSortColumns = map[string]string{
+	"created_at": "created_at",
+	"code":       "code",
+	"start_date": "start_date",
+	"end_date":   "end_date",
+}
+
+// GetAllPromos mengambil promo (dengan filter + paginasi)
+func (s *promoService) GetAllPromos(filter dto.PromoListFilter, page utils.PageQuery) ([]promo_model.Promo, int64, error) {
 	var promos []promo_model.Promo
 
-	if err := config.DB.Order("created_at DESC").Find(&promos).Error; err != nil {
-		return nil, ErrGetPromosFailed
+	q := config.DB.Model(&promo_model.Promo{})
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := utils.ContainsPattern(search)
+		q = q.Where("LOWER(code) LIKE ? OR LOWER(name) LIKE ?", like, like)
+	}
+	if filter.IsActive != nil {
+		q = q.Where("is_active = ?", *filter.IsActive)
 	}
 
-	return promos, nil
+	total, err := utils.Paginate(q, page, &promos)
+	if err != nil {
+		return nil, 0, ErrGetPromosFailed
+	}
+
+	return promos, total, nil
 }
 
 // GetPromoByID mengambil promo berdasarkan ID
@@ -164,6 +183,7 @@ func (s *promoService) UpdatePromo(promoID string, input dto.UpdatePromoDTO) (pr
 	}
 
 	promo.IsActive = input.IsActive
+	promo.Exhausted = false // status aktif sekarang ditentukan admin
 
 	// Simpan perubahan
 	if err := config.DB.Save(&promo).Error; err != nil {
@@ -216,7 +236,7 @@ func (s *promoService) DeletePromo(promoID string) error {
 }
 
 // ValidatePromo memvalidasi kode promo dan menghitung discount
-func (s *promoService) ValidatePromo(code string, subtotal float64) (promo_model.Promo, float64, error) {
+func (s *promoService) ValidatePromo(code string, subtotal utils.Money) (promo_model.Promo, utils.Money, error) {
 	var promo promo_model.Promo
 
 	// Find promo by code (case insensitive)
@@ -245,16 +265,15 @@ func (s *promoService) ValidatePromo(code string, subtotal float64) (promo_model
 
 	// Check: min purchase
 	if subtotal < promo.MinPurchase {
-		return promo_model.Promo{}, 0, fmt.Errorf("Minimum pembelian Rp %.0f", promo.MinPurchase)
+		return promo_model.Promo{}, 0, fmt.Errorf("Minimum pembelian Rp %s", promo.MinPurchase)
 	}
 
-	// Calculate discount
-	var discount float64
-	if promo.Type == "percentage" {
-		discount = subtotal * (promo.Value / 100)
-	} else { // fixed
-		discount = promo.Value
-	}
+	return promo, cappedPromoDiscount(promo, subtotal), nil
+}
+
+// cappedPromoDiscount diskon promo setelah batas max_discount dan tidak melebihi subtotal
+func cappedPromoDiscount(promo promo_model.Promo, subtotal utils.Money) utils.Money {
+	discount := promoDiscount(promo, subtotal)
 
 	// Apply max discount (0 = unlimited)
 	if promo.MaxDiscount > 0 && discount > promo.MaxDiscount {
@@ -265,6 +284,29 @@ func (s *promoService) ValidatePromo(code string, subtotal float64) (promo_model
 	if discount > subtotal {
 		discount = subtotal
 	}
+	return discount
+}
 
-	return promo, discount, nil
-}
\ No newline at end of file
+// promoDiscount menghitung diskon dari promo (sebelum batas max_discount).
+// Value berupa persen untuk percentage (dibulatkan half-up ke rupiah) atau nominal untuk fixed.
+func promoDiscount(promo promo_model.Promo, subtotal utils.Money) utils.Money {
+	if promo.Type == "percentage" {
+		return subtotal.MulRate(promo.Value)
+	}
+	return utils.RoundMoney(promo.Value) // fixed
+}
+
+// releasePromoUsage mengembalikan kuota promo (usage_count - 1) saat transaksi yang memakainya batal / kadaluarsa.
+// Promo yang dinonaktifkan otomatis karena kuota habis (exhausted) aktif lagi; promo yang dinonaktifkan admin tetap nonaktif.
+func releasePromoUsage(db *gorm.DB, code string) error {
+	if code == "" {
+		return nil
+	}
+	return db.Model(&promo_model.Promo{}).
+		Where("LOWER(code) = LOWER(?) AND usage_count > 0", code).
+		Updates(map[string]interface{}{
+			"usage_count": gorm.Expr("usage_count - ?", 1),
+			"is_active":   gorm.Expr("is_active OR (exhausted AND usage_count - 1 < usage_limit)"),
+			"exhausted":   gorm.Expr("exhausted AND usage_count - 1 >= usage_limit"),
+		}).Error
+}
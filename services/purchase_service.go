@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	ingredient_model "pos-go/models/ingredient_model"
+	purchase_model "pos-go/models/purchase_model"
+	"pos-go/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sentinel errors
+var (
+	ErrPurchaseNotFound     = errors.New("Pembelian tidak ditemukan")
+	ErrInvalidReceivedAt    = errors.New("Format received_at tidak valid (gunakan RFC3339)")
+	ErrCreatePurchaseFailed = errors.New("Gagal menyimpan pembelian")
+	ErrGetPurchasesFailed   = errors.New("Gagal mengambil daftar pembelian")
+)
+
+type PurchaseService interface {
+	CreatePurchase(input dto.CreatePurchaseDTO, userID uuid.UUID) (purchase_model.Purchase, error)
+	GetAllPurchases(filter dto.PurchaseListFilter, page utils.PageQuery) ([]purchase_model.Purchase, int64, error)
+	GetPurchaseByID(purchaseID string) (purchase_model.Purchase, error)
+}
+
+type purchaseService struct{}
+
+func NewPurchaseService() PurchaseService {
+	return &purchaseService{}
+}
+
+// PurchaseSortColumns kolom yang boleh dipakai untuk sort_by pada GET /purchase
+var PurchaseSortColumns = map[string]string{
+	"received_at": "received_at",
+	"total_cost":  "total_cost",
+	"created_at":  "created_at",
+}
+
+// CreatePurchase mencatat penerimaan barang: stok bahan bertambah (movement purchase) dan harga per unit terakhir diperbarui
+func (s *purchaseService) CreatePurchase(input dto.CreatePurchaseDTO, userID uuid.UUID) (purchase_model.Purchase, error) {
+	receivedAt := time.Now()
+	if input.ReceivedAt != "" {
+		t, err := time.Parse(time.RFC3339, input.ReceivedAt)
+		if err != nil {
+			return purchase_model.Purchase{}, ErrInvalidReceivedAt
+		}
+		receivedAt = t
+	}
+
+	purchase := purchase_model.Purchase{
+		Supplier:        input.Supplier,
+		InvoiceNumber:   input.InvoiceNumber,
+		ReceivedAt:      receivedAt,
+		Notes:           input.Notes,
+		CreatedByUserID: userID,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, in := range input.Items {
+			ingredient, err := findIngredient(tx, in.IngredientID)
+			if err != nil {
+				return err
+			}
+			subtotal := utils.RoundMoney(float64(in.UnitCost) * in.Quantity)
+			purchase.TotalCost += subtotal
+			purchase.Items = append(purchase.Items, purchase_model.PurchaseItem{
+				IngredientID:   ingredient.ID,
+				IngredientName: ingredient.Name,
+				Unit:           ingredient.Unit,
+				Quantity:       in.Quantity,
+				UnitCost:       in.UnitCost,
+				Subtotal:       subtotal,
+			})
+		}
+
+		if err := tx.Create(&purchase).Error; err != nil {
+			return ErrCreatePurchaseFailed
+		}
+
+		for _, item := range purchase.Items {
+			if _, err := applyIngredientChange(tx, item.IngredientID, item.Quantity, ingredient_model.IngredientMovement{
+				Type:       IngredientMovementPurchase,
+				PurchaseID: &purchase.ID,
+				UserID:     &userID,
+			}); err != nil {
+				return err
+			}
+			if err := tx.Model(&ingredient_model.Ingredient{}).
+				Where("id = ?", item.IngredientID).
+				Update("last_unit_cost", item.UnitCost).Error; err != nil {
+				return ErrCreatePurchaseFailed
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return purchase_model.Purchase{}, err
+	}
+
+	return purchase, nil
+}
+
+// GetAllPurchases daftar pembelian (filter supplier + rentang tanggal terima, paginasi)
+func (s *purchaseService) GetAllPurchases(filter dto.PurchaseListFilter, page utils.PageQuery) ([]purchase_model.Purchase, int64, error) {
+	q := config.DB.Model(&purchase_model.Purchase{})
+	if supplier := strings.TrimSpace(filter.Supplier); supplier != "" {
+		q = q.Where("LOWER(supplier) LIKE ?", utils.ContainsPattern(supplier))
+	}
+	if filter.DateFrom != "" {
+		from, _, err := config.StoreCalendar.ParseDay(filter.DateFrom)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("received_at >= ?", from)
+	}
+	if filter.DateTo != "" {
+		_, to, err := config.StoreCalendar.ParseDay(filter.DateTo)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("received_at < ?", to)
+	}
+
+	var purchases []purchase_model.Purchase
+	total, err := utils.Paginate(q, page, &purchases, "Items")
+	if err != nil {
+		return nil, 0, ErrGetPurchasesFailed
+	}
+	return purchases, total, nil
+}
+
+// GetPurchaseByID detail pembelian beserta item
+func (s *purchaseService) GetPurchaseByID(purchaseID string) (purchase_model.Purchase, error) {
+	id, err := uuid.Parse(purchaseID)
+	if err != nil {
+		return purchase_model.Purchase{}, ErrPurchaseNotFound
+	}
+	var purchase purchase_model.Purchase
+	if err := config.DB.Preload("Items").First(&purchase, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return purchase_model.Purchase{}, ErrPurchaseNotFound
+		}
+		return purchase_model.Purchase{}, ErrDatabaseError
+	}
+	return purchase, nil
+}
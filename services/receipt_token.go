@@ -0,0 +1,162 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	transaction_model "pos-go/models/transaction_model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReceiptTokenNotFound = errors.New("Struk tidak ditemukan")
+	ErrReceiptTokenExpired  = errors.New("Link struk sudah tidak berlaku")
+)
+
+// newReceiptToken 32 byte acak (crypto/rand) dalam base64url tanpa padding (43 karakter)
+func newReceiptToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ReceiptLink link publik untuk token (kosong jika RECEIPT_PUBLIC_URL belum diatur)
+func ReceiptLink(token string) string {
+	if config.ReceiptPublicURL == "" {
+		return ""
+	}
+	return config.ReceiptPublicURL + "/" + token
+}
+
+// activeReceiptToken token terbaru transaksi yang belum dicabut & belum kedaluwarsa
+func activeReceiptToken(db *gorm.DB, transactionID uuid.UUID) (*transaction_model.ReceiptToken, error) {
+	var token transaction_model.ReceiptToken
+	err := db.Where("transaction_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", transactionID, time.Now()).
+		Order("created_at DESC").First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateReceiptToken membuat token struk digital baru untuk transaksi. expiresIn nil = default config.ReceiptTokenTTL
+// (0 = tidak kedaluwarsa). Token lama tetap berlaku sampai dicabut.
+func (s TransactionService) CreateReceiptToken(transactionID uuid.UUID, userID *uuid.UUID, expiresIn *time.Duration) (*dto.ReceiptTokenResponse, error) {
+	var count int64
+	if err := config.DB.Model(&transaction_model.Transaction{}).Where("id = ?", transactionID).Count(&count).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	if count == 0 {
+		return nil, ErrTransactionNotFound
+	}
+
+	value, err := newReceiptToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl := config.ReceiptTokenTTL
+	if expiresIn != nil {
+		ttl = *expiresIn
+	}
+	token := transaction_model.ReceiptToken{
+		TransactionID:   transactionID,
+		Token:           value,
+		CreatedByUserID: userID,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := config.DB.Create(&token).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return receiptTokenResponse(token), nil
+}
+
+// EnsureReceiptToken token aktif transaksi; dibuat baru (TTL default) jika belum ada. Dipakai saat mencetak QR di struk.
+func (s TransactionService) EnsureReceiptToken(transactionID uuid.UUID, userID *uuid.UUID) (*dto.ReceiptTokenResponse, error) {
+	token, err := activeReceiptToken(config.DB, transactionID)
+	if err == nil {
+		return receiptTokenResponse(*token), nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDatabaseError
+	}
+	return s.CreateReceiptToken(transactionID, userID, nil)
+}
+
+// RevokeReceiptTokens mencabut semua token struk digital transaksi yang masih aktif; mengembalikan jumlah yang dicabut
+func (s TransactionService) RevokeReceiptTokens(transactionID uuid.UUID) (int64, error) {
+	res := config.DB.Model(&transaction_model.ReceiptToken{}).
+		Where("transaction_id = ? AND revoked_at IS NULL", transactionID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return 0, ErrDatabaseError
+	}
+	return res.RowsAffected, nil
+}
+
+// GetPublicReceipt struk digital dari token publik. Token tidak dikenal / dicabut -> ErrReceiptTokenNotFound,
+// kedaluwarsa -> ErrReceiptTokenExpired.
+func (s TransactionService) GetPublicReceipt(value string) (*dto.PublicReceiptResponse, error) {
+	var token transaction_model.ReceiptToken
+	if err := config.DB.Where("token = ?", value).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReceiptTokenNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+	if token.RevokedAt != nil {
+		return nil, ErrReceiptTokenNotFound
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return nil, ErrReceiptTokenExpired
+	}
+
+	r, err := s.GetTransactionReceipt(token.TransactionID)
+	if err != nil {
+		if errors.Is(err, ErrTransactionNotFound) {
+			return nil, ErrReceiptTokenNotFound
+		}
+		return nil, err
+	}
+	return &dto.PublicReceiptResponse{
+		OrderNumber:      r.OrderNumber,
+		CreatedAt:        r.CreatedAt,
+		CustomerName:     r.CustomerName,
+		OrderType:        r.OrderType,
+		TableNumber:      r.TableNumber,
+		Items:            r.Items,
+		Subtotal:         r.Subtotal,
+		Discount:         r.Discount,
+		ServiceCharge:    r.ServiceCharge,
+		Tax:              r.Tax,
+		Taxes:            r.Taxes,
+		TotalAmount:      r.TotalAmount,
+		PaymentMethod:    r.PaymentMethod,
+		PaymentStatus:    r.PaymentStatus,
+		AmountTendered:   r.AmountTendered,
+		ChangeAmount:     r.ChangeAmount,
+		ClosedByUserName: r.ClosedByUserName,
+	}, nil
+}
+
+func receiptTokenResponse(t transaction_model.ReceiptToken) *dto.ReceiptTokenResponse {
+	res := &dto.ReceiptTokenResponse{
+		Token:     t.Token,
+		URL:       ReceiptLink(t.Token),
+		CreatedAt: config.StoreCalendar.Format(t.CreatedAt),
+	}
+	if t.ExpiresAt != nil {
+		expiresAt := config.StoreCalendar.Format(*t.ExpiresAt)
+		res.ExpiresAt = &expiresAt
+	}
+	return res
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pos-go/config"
+	"pos-go/dto"
+	refund_model "pos-go/models/refund_model"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sentinel errors
+var (
+	ErrRefundNotAllowed    = errors.New("Transaksi belum dibayar atau sudah direfund penuh")
+	ErrRefundItemsRequired = errors.New("Item refund wajib diisi untuk refund partial")
+	ErrRefundItemInvalid   = errors.New("Item refund tidak valid atau melebihi jumlah yang bisa direfund")
+	ErrRefundAmountZero    = errors.New("Nominal refund nol")
+	ErrRefundGatewayFailed = errors.New("Refund ke payment gateway gagal")
+	ErrRefundExceedsTender = errors.New("Nominal refund melebihi sisa satu pembayaran, lakukan refund partial per pembayaran")
+)
+
+// Status refund
+const (
+	RefundStatusPending   = "pending" // menunggu hasil payment gateway
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// RefundGateway abstraksi refund ke payment gateway (Midtrans). Bisa diganti fake lokal untuk testing.
+type RefundGateway interface {
+	Refund(orderID, refundKey string, amount int64, reason string) error
+}
+
+// midtransRefundGateway implementasi RefundGateway via Midtrans Core API
+type midtransRefundGateway struct{}
+
+func (midtransRefundGateway) Refund(orderID, refundKey string, amount int64, reason string) error {
+	_, err := config.MidtransCoreClient.RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: refundKey,
+		Amount:    amount,
+		Reason:    reason,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrRefundGatewayFailed, err.GetMessage())
+	}
+	return nil
+}
+
+type RefundService struct {
+	Gateway RefundGateway
+}
+
+func NewRefundService() RefundService {
+	return RefundService{Gateway: midtransRefundGateway{}}
+}
+
+// CreateRefund memproses refund full / partial atas transaksi yang sudah dibayar.
+// Refund disimpan pending lebih dulu: qty dan nominalnya langsung dipesan (refunded_quantity / refunded_amount)
+// agar refund paralel tidak melebihi sisa. Refund non-cash lalu dikirim ke gateway di luar DB transaction dan
+// hasilnya dicatat sebagai succeeded atau failed (pesanan qty / nominal dilepas). Refund tunai langsung succeeded.
+func (s RefundService) CreateRefund(transactionID uuid.UUID, req dto.CreateRefundRequest, approvedBy uuid.UUID) (*refund_model.Refund, error) {
+	var refund refund_model.Refund
+	var before transaction_model.Transaction
+	var orderID string
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var transaction transaction_model.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&transaction, "id = ?", transactionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransactionNotFound
+			}
+			return ErrDatabaseError
+		}
+		if transaction.PaymentStatus != PaymentStatusPaid && transaction.PaymentStatus != PaymentStatusPartiallyRefunded {
+			return ErrRefundNotAllowed
+		}
+		before = transaction
+
+		refundQty, err := refundQuantities(transaction, req)
+		if err != nil {
+			return err
+		}
+
+		remaining := transaction.TotalAmount - transaction.RefundedAmount
+		amount, items := allocateRefund(transaction, refundQty, remaining)
+		if amount <= 0 {
+			return ErrRefundAmountZero
+		}
+
+		// Refund dikembalikan ke satu tender; transaksi lama tanpa payment memakai metode & order_id transaksi
+		tender, err := refundTender(tx, transaction.ID, amount)
+		if err != nil {
+			return err
+		}
+		method := transaction.PaymentMethod
+		orderID = transaction.ID.String()
+		if tender != nil {
+			method, orderID = tender.Method, tender.ExternalRef
+		}
+
+		refund = refund_model.Refund{
+			ID:               uuid.New(),
+			TransactionID:    transaction.ID,
+			Type:             req.Type,
+			Amount:           amount,
+			Reason:           req.Reason,
+			PaymentMethod:    method,
+			Status:           RefundStatusPending,
+			ApprovedByUserID: approvedBy,
+			Items:            items,
+		}
+		if tender != nil {
+			refund.PaymentID = &tender.ID
+		}
+
+		if method != "cash" {
+			refund.ExternalRef = refund.ID.String()
+		}
+
+		if err := tx.Create(&refund).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if err := reserveRefund(tx, &refund, 1); err != nil {
+			return err
+		}
+
+		// Tunai: uang keluar dari laci saat itu juga, tidak menunggu gateway
+		if method == "cash" {
+			return completeRefund(tx, &refund)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if refund.Status == RefundStatusPending {
+		gatewayErr := s.Gateway.Refund(orderID, refund.ExternalRef, refund.Amount.Int64(), req.Reason)
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if gatewayErr != nil {
+				return failRefund(tx, &refund)
+			}
+			return completeRefund(tx, &refund)
+		})
+		if gatewayErr != nil {
+			if err != nil {
+				log.Printf("refund %s gagal di gateway dan gagal ditandai failed: %v", refund.ID, err)
+			}
+			return nil, gatewayErr
+		}
+		if err != nil {
+			// Gateway sudah mengembalikan dana: refund tetap pending agar bisa dicek dan diselesaikan manual
+			log.Printf("refund %s berhasil di gateway tetapi gagal disimpan: %v", refund.ID, err)
+			return nil, err
+		}
+	}
+
+	var after transaction_model.Transaction
+	if err := config.DB.Preload("Items").First(&after, "id = ?", transactionID).Error; err == nil {
+		publishStatusChange(&before, &after)
+	}
+
+	return &refund, nil
+}
+
+// reserveRefund menambah (sign = 1) atau melepas (sign = -1) qty dan nominal refund pada item, transaksi dan tender
+func reserveRefund(tx *gorm.DB, refund *refund_model.Refund, sign int) error {
+	for _, it := range refund.Items {
+		if err := tx.Model(&transaction_model.TransactionItem{}).Where("id = ?", it.TransactionItemID).
+			Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", sign*it.Quantity)).Error; err != nil {
+			return ErrDatabaseError
+		}
+	}
+	amount := refund.Amount.Mul(sign)
+	if err := tx.Model(&transaction_model.Transaction{}).Where("id = ?", refund.TransactionID).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount)).Error; err != nil {
+		return ErrDatabaseError
+	}
+	if refund.PaymentID != nil {
+		if err := tx.Model(&transaction_model.Payment{}).Where("id = ?", *refund.PaymentID).
+			Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount)).Error; err != nil {
+			return ErrDatabaseError
+		}
+	}
+	return nil
+}
+
+// completeRefund menandai refund succeeded lalu memperbarui status transaksi dari total refund yang berhasil
+// (refund lain yang masih pending tidak ikut dihitung). Refund penuh atas pesanan yang belum selesai ikut
+// membatalkan pesanan.
+func completeRefund(tx *gorm.DB, refund *refund_model.Refund) error {
+	var transaction transaction_model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", refund.TransactionID).Error; err != nil {
+		return ErrDatabaseError
+	}
+	if err := setRefundStatus(tx, refund, RefundStatusSucceeded); err != nil {
+		return err
+	}
+
+	var succeeded utils.Money
+	if err := tx.Model(&refund_model.Refund{}).Select("COALESCE(SUM(amount), 0)").
+		Where("transaction_id = ? AND status = ?", transaction.ID, RefundStatusSucceeded).
+		Scan(&succeeded).Error; err != nil {
+		return ErrDatabaseError
+	}
+
+	nextPayment := PaymentStatusPartiallyRefunded
+	nextOrder := ""
+	if succeeded >= transaction.TotalAmount {
+		nextPayment = PaymentStatusRefunded
+		if transaction.OrderStatus != OrderStatusCompleted {
+			nextOrder = OrderStatusCancelled
+		}
+	}
+	return applyStatusTransition(tx, &transaction, nextPayment, nextOrder, nil)
+}
+
+// failRefund menandai refund failed dan melepas qty / nominal yang dipesan saat pending
+func failRefund(tx *gorm.DB, refund *refund_model.Refund) error {
+	var transaction transaction_model.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&transaction, "id = ?", refund.TransactionID).Error; err != nil {
+		return ErrDatabaseError
+	}
+	if err := setRefundStatus(tx, refund, RefundStatusFailed); err != nil {
+		return err
+	}
+	return reserveRefund(tx, refund, -1)
+}
+
+// setRefundStatus mengubah status refund yang masih pending (refund yang sudah final tidak diubah lagi)
+func setRefundStatus(tx *gorm.DB, refund *refund_model.Refund, status string) error {
+	res := tx.Model(&refund_model.Refund{}).Where("id = ? AND status = ?", refund.ID, RefundStatusPending).Update("status", status)
+	if res.Error != nil {
+		return ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		return ErrStatusConflict
+	}
+	refund.Status = status
+	return nil
+}
+
+// GetRefundsByTransaction daftar refund untuk satu transaksi
+func (s RefundService) GetRefundsByTransaction(transactionID uuid.UUID) ([]refund_model.Refund, error) {
+	var refunds []refund_model.Refund
+	if err := config.DB.Preload("Items").Where("transaction_id = ?", transactionID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return refunds, nil
+}
+
+// refundTender memilih payment yang menerima refund: tender paid terbaru yang sisa (amount - refunded) cukup.
+// Nil jika transaksi belum punya payment (transaksi lama sebelum multi-tender).
+func refundTender(tx *gorm.DB, transactionID uuid.UUID, amount utils.Money) (*transaction_model.Payment, error) {
+	var payments []transaction_model.Payment
+	if err := tx.Where("transaction_id = ? AND status = ?", transactionID, TenderStatusPaid).
+		Order("created_at DESC").Find(&payments).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	for i := range payments {
+		if payments[i].Amount-payments[i].RefundedAmount >= amount {
+			return &payments[i], nil
+		}
+	}
+	return nil, ErrRefundExceedsTender
+}
+
+// refundQuantities menentukan qty per transaction item yang direfund (full = semua sisa qty).
+func refundQuantities(transaction transaction_model.Transaction, req dto.CreateRefundRequest) (map[uuid.UUID]int, error) {
+	refundQty := make(map[uuid.UUID]int)
+	if req.Type == "full" {
+		for _, it := range transaction.Items {
+			if left := it.Quantity - it.RefundedQuantity; left > 0 {
+				refundQty[it.ID] = left
+			}
+		}
+		return refundQty, nil
+	}
+
+	if len(req.Items) == 0 {
+		return nil, ErrRefundItemsRequired
+	}
+	for _, reqItem := range req.Items {
+		refundQty[reqItem.TransactionItemID] += reqItem.Quantity
+	}
+	for itemID, qty := range refundQty {
+		found := false
+		for _, it := range transaction.Items {
+			if it.ID == itemID {
+				found = true
+				if qty > it.Quantity-it.RefundedQuantity {
+					return nil, ErrRefundItemInvalid
+				}
+			}
+		}
+		if !found {
+			return nil, ErrRefundItemInvalid
+		}
+	}
+	return refundQty, nil
+}
+
+// allocateRefund menghitung nominal refund per item secara proporsional terhadap TotalAmount
+// (diskon & pajak ikut terbagi). Jika semua item habis direfund, nominal = sisa yang belum direfund
+// agar pembulatan tidak menyisakan selisih.
+func allocateRefund(transaction transaction_model.Transaction, refundQty map[uuid.UUID]int, remaining utils.Money) (utils.Money, []refund_model.RefundItem) {
+	var itemsTotal utils.Money
+	for _, it := range transaction.Items {
+		itemsTotal += it.Subtotal
+	}
+
+	var amount utils.Money
+	allRefunded := true
+	items := make([]refund_model.RefundItem, 0, len(refundQty))
+	for _, it := range transaction.Items {
+		qty := refundQty[it.ID]
+		if it.Quantity-it.RefundedQuantity-qty > 0 {
+			allRefunded = false
+		}
+		if qty == 0 {
+			continue
+		}
+		var lineAmount utils.Money
+		if itemsTotal > 0 {
+			lineAmount = transaction.TotalAmount.MulDiv((it.MenuPrice + it.ModifierPrice).Mul(qty), itemsTotal)
+		}
+		amount += lineAmount
+		items = append(items, refund_model.RefundItem{
+			TransactionItemID: it.ID,
+			MenuName:          it.MenuName,
+			Quantity:          qty,
+			Amount:            lineAmount,
+		})
+	}
+
+	if (allRefunded || amount > remaining) && len(items) > 0 {
+		// Selisih pembulatan dibebankan ke item terakhir agar sum(items) = amount
+		items[len(items)-1].Amount += remaining - amount
+		amount = remaining
+	}
+	return amount, items
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	"pos-go/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxReportRangeDays batas panjang periode laporan (inklusif)
+const MaxReportRangeDays = 366
+
+// ErrReportRangeTooLong periode laporan melebihi MaxReportRangeDays
+var ErrReportRangeTooLong = fmt.Errorf("Periode laporan maksimal %d hari", MaxReportRangeDays)
+
+// weekdayNames nama hari ISO (1 = Senin)
+var weekdayNames = [...]string{"", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu", "Minggu"}
+
+// reportScope filter transaksi laporan: completed & dibayar dalam rentang [start, end), opsional per kasir.
+// db koneksi yang dipakai query (config.DB, atau transaksi saat menutup Z-report).
+type reportScope struct {
+	db        *gorm.DB
+	start     time.Time
+	end       time.Time
+	cashierID *uuid.UUID
+}
+
+// where kondisi SQL untuk tabel transactions dengan alias t
+func (r reportScope) where() (string, []interface{}) {
+	cond := "t.deleted_at IS NULL AND t.created_at >= ? AND t.created_at < ? AND t.order_status = ? AND t.payment_status IN ?"
+	args := []interface{}{r.start, r.end, OrderStatusCompleted, PaidPaymentStatuses}
+	if r.cashierID != nil {
+		cond += " AND t.closed_by_user_id = ?"
+		args = append(args, *r.cashierID)
+	}
+	return cond, args
+}
+
+// businessDateSQL ekspresi SQL tanggal hari bisnis (zona waktu toko + cutoff) dari kolom timestamp, beserta argumennya
+func businessDateSQL(column string) (string, []interface{}) {
+	cal := config.StoreCalendar
+	return fmt.Sprintf("((%s AT TIME ZONE ?::text) - make_interval(hours => ?::int))::date", column),
+		[]interface{}{cal.Location.String(), cal.CutoffHour}
+}
+
+// GetReportByRange laporan periode dateFrom..dateTo (YYYY-MM-DD, hari bisnis, inklusif): ringkasan dan rincian per hari, menu, kategori,
+// jam, hari dalam minggu, jenis pesanan, metode bayar, dan kode promo. Semua agregasi dijalankan di database (GROUP BY);
+// item yang sudah dihapus (open bill) tidak dihitung. Jika cashierID != nil, hanya transaksi yang ditutup kasir tersebut.
+func (s ReportService) GetReportByRange(dateFrom, dateTo string, cashierID *uuid.UUID) (*dto.ReportRangeResponse, error) {
+	start, _, err := config.StoreCalendar.ParseDay(dateFrom)
+	if err != nil {
+		return nil, err
+	}
+	_, end, err := config.StoreCalendar.ParseDay(dateTo)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := time.Parse("2006-01-02", dateFrom)
+	to, _ := time.Parse("2006-01-02", dateTo)
+	if to.Before(from) {
+		return nil, ErrInvalidDateRange
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days > MaxReportRangeDays {
+		return nil, ErrReportRangeTooLong
+	}
+
+	scope := reportScope{db: config.DB, start: start, end: end, cashierID: cashierID}
+	summary, err := reportRangeSummary(scope)
+	if err != nil {
+		return nil, err
+	}
+	summary.DateFrom = dateFrom
+	summary.DateTo = dateTo
+
+	res := &dto.ReportRangeResponse{Summary: *summary}
+	if res.Daily, err = reportDaily(scope, from, days); err != nil {
+		return nil, err
+	}
+	if res.ByMenu, err = reportByMenu(scope); err != nil {
+		return nil, err
+	}
+	if res.ByCategory, err = reportByCategory(scope); err != nil {
+		return nil, err
+	}
+	if res.ByHour, err = reportByHour(scope); err != nil {
+		return nil, err
+	}
+	if res.ByWeekday, err = reportByWeekday(scope); err != nil {
+		return nil, err
+	}
+	if res.ByOrderType, err = reportByOrderType(scope); err != nil {
+		return nil, err
+	}
+	if res.ByPaymentMethod, err = reportByPaymentMethod(scope); err != nil {
+		return nil, err
+	}
+	if res.ByPromo, err = reportByPromo(scope); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// reportRangeSummary total periode. Porsi tunai diambil dari tender tunai yang paid; transaksi lama tanpa payment
+// memakai payment_method transaksi (sama dengan cashTendered).
+func reportRangeSummary(scope reportScope) (*dto.ReportRangeSummary, error) {
+	cond, args := scope.where()
+	var row struct {
+		Transactions  int
+		Sales         utils.Money
+		Discount      utils.Money
+		ServiceCharge utils.Money
+		Tax           utils.Money
+		Cash          utils.Money
+	}
+	if err := scope.db.Table("transactions AS t").
+		Select(`COUNT(*) AS transactions,
+			COALESCE(SUM(t.total_amount), 0) AS sales,
+			COALESCE(SUM(t.discount), 0) AS discount,
+			COALESCE(SUM(t.service_charge), 0) AS service_charge,
+			COALESCE(SUM(t.tax), 0) AS tax,
+			COALESCE(SUM(CASE
+				WHEN EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id AND p.status = ?)
+					THEN (SELECT COALESCE(SUM(p.amount), 0) FROM payments p WHERE p.transaction_id = t.id AND p.status = ? AND p.method = 'cash')
+				WHEN t.payment_method = 'cash' THEN t.total_amount
+				ELSE 0 END), 0) AS cash`, TenderStatusPaid, TenderStatusPaid).
+		Where(cond, args...).
+		Scan(&row).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	var taxes []dto.ReportTaxLine
+	if err := scope.db.Table("transaction_taxes AS tt").
+		Select("tt.name, tt.type, tt.rate, tt.is_inclusive, SUM(tt.taxable_amount) AS taxable_amount, SUM(tt.amount) AS amount").
+		Joins("JOIN transactions AS t ON t.id = tt.transaction_id").
+		Where(cond, args...).
+		Group("tt.name, tt.type, tt.rate, tt.is_inclusive").
+		Order("tt.type, tt.name").
+		Scan(&taxes).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	totalRefund, cashRefund, err := refundTotals(scope.db, scope.start, scope.end, scope.cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.ReportRangeSummary{
+		TotalTransactions:  row.Transactions,
+		TotalSales:         row.Sales,
+		TotalCash:          row.Cash - cashRefund,
+		TotalNonCash:       row.Sales - row.Cash - (totalRefund - cashRefund),
+		TotalDiscount:      row.Discount,
+		TotalServiceCharge: row.ServiceCharge,
+		TotalTax:           row.Tax,
+		Taxes:              taxes,
+		TotalRefund:        totalRefund,
+		NetSales:           row.Sales - totalRefund,
+	}
+	if summary.Taxes == nil {
+		summary.Taxes = []dto.ReportTaxLine{}
+	}
+	if row.Transactions > 0 {
+		summary.AverageTransaction = row.Sales.MulDiv(1, utils.Money(row.Transactions))
+	}
+	return summary, nil
+}
+
+// reportDaily penjualan per hari bisnis; hari tanpa transaksi tetap muncul dengan nilai 0
+func reportDaily(scope reportScope, from time.Time, days int) ([]dto.ReportDayLine, error) {
+	cond, args := scope.where()
+	dateExpr, dateArgs := businessDateSQL("t.created_at")
+	var rows []struct {
+		Day          time.Time
+		Transactions int
+		Sales        utils.Money
+	}
+	if err := scope.db.Table("transactions AS t").
+		Select(dateExpr+" AS day, COUNT(*) AS transactions, COALESCE(SUM(t.total_amount), 0) AS sales", dateArgs...).
+		Where(cond, args...).
+		Group("day").
+		Scan(&rows).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	byDay := make(map[string]int, len(rows))
+	for i, r := range rows {
+		byDay[r.Day.Format("2006-01-02")] = i
+	}
+
+	lines := make([]dto.ReportDayLine, 0, days)
+	for d := 0; d < days; d++ {
+		key := from.AddDate(0, 0, d).Format("2006-01-02")
+		line := dto.ReportDayLine{Date: key}
+		if i, ok := byDay[key]; ok {
+			line.TotalTransactions = rows[i].Transactions
+			line.TotalSales = rows[i].Sales
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// reportByMenu qty dan penjualan per menu (nama menu terakhir yang tercatat di item)
+func reportByMenu(scope reportScope) ([]dto.ReportMenuLine, error) {
+	cond, args := scope.where()
+	lines := make([]dto.ReportMenuLine, 0)
+	if err := scope.db.Table("transaction_items AS ti").
+		Select(`ti.menu_id, MAX(ti.menu_name) AS menu_name, COALESCE(MAX(c.name), '') AS category_name,
+			SUM(ti.quantity) AS quantity, SUM(ti.refunded_quantity) AS refunded_quantity,
+			SUM(ti.subtotal) AS gross_sales, SUM(ti.discount) AS discount, SUM(ti.subtotal - ti.discount) AS net_sales`).
+		Joins("JOIN transactions AS t ON t.id = ti.transaction_id").
+		Joins("LEFT JOIN menus AS m ON m.id = ti.menu_id").
+		Joins("LEFT JOIN categories AS c ON c.id = m.category_id").
+		Where("ti.deleted_at IS NULL").
+		Where(cond, args...).
+		Group("ti.menu_id").
+		Order("quantity DESC, net_sales DESC").
+		Scan(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
+
+// reportByCategory qty dan penjualan per kategori menu saat ini
+func reportByCategory(scope reportScope) ([]dto.ReportCategoryLine, error) {
+	cond, args := scope.where()
+	lines := make([]dto.ReportCategoryLine, 0)
+	if err := scope.db.Table("transaction_items AS ti").
+		Select(`c.id AS category_id, COALESCE(c.name, 'Tanpa kategori') AS category_name,
+			SUM(ti.quantity) AS quantity, SUM(ti.subtotal) AS gross_sales, SUM(ti.discount) AS discount,
+			SUM(ti.subtotal - ti.discount) AS net_sales`).
+		Joins("JOIN transactions AS t ON t.id = ti.transaction_id").
+		Joins("LEFT JOIN menus AS m ON m.id = ti.menu_id").
+		Joins("LEFT JOIN categories AS c ON c.id = m.category_id").
+		Where("ti.deleted_at IS NULL").
+		Where(cond, args...).
+		Group("c.id, c.name").
+		Order("net_sales DESC").
+		Scan(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
+
+// reportByHour penjualan per jam waktu lokal toko; selalu 24 baris
+func reportByHour(scope reportScope) ([]dto.ReportHourLine, error) {
+	cond, args := scope.where()
+	var rows []struct {
+		Hour         int
+		Transactions int
+		Sales        utils.Money
+	}
+	if err := scope.db.Table("transactions AS t").
+		Select("EXTRACT(HOUR FROM t.created_at AT TIME ZONE ?::text)::int AS hour, COUNT(*) AS transactions, COALESCE(SUM(t.total_amount), 0) AS sales",
+			config.StoreCalendar.Location.String()).
+		Where(cond, args...).
+		Group("hour").
+		Scan(&rows).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	lines := make([]dto.ReportHourLine, 24)
+	for h := range lines {
+		lines[h].Hour = h
+	}
+	for _, r := range rows {
+		if r.Hour >= 0 && r.Hour < 24 {
+			lines[r.Hour].TotalTransactions = r.Transactions
+			lines[r.Hour].TotalSales = r.Sales
+		}
+	}
+	return lines, nil
+}
+
+// reportByWeekday penjualan per hari dalam minggu menurut hari bisnis; selalu 7 baris (Senin..Minggu)
+func reportByWeekday(scope reportScope) ([]dto.ReportWeekdayLine, error) {
+	cond, args := scope.where()
+	dateExpr, dateArgs := businessDateSQL("t.created_at")
+	var rows []struct {
+		Weekday      int
+		Transactions int
+		Sales        utils.Money
+	}
+	if err := scope.db.Table("transactions AS t").
+		Select("EXTRACT(ISODOW FROM "+dateExpr+")::int AS weekday, COUNT(*) AS transactions, COALESCE(SUM(t.total_amount), 0) AS sales", dateArgs...).
+		Where(cond, args...).
+		Group("weekday").
+		Scan(&rows).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	lines := make([]dto.ReportWeekdayLine, 7)
+	for i := range lines {
+		lines[i].Weekday = i + 1
+		lines[i].Name = weekdayNames[i+1]
+	}
+	for _, r := range rows {
+		if r.Weekday >= 1 && r.Weekday <= 7 {
+			lines[r.Weekday-1].TotalTransactions = r.Transactions
+			lines[r.Weekday-1].TotalSales = r.Sales
+		}
+	}
+	return lines, nil
+}
+
+// reportByOrderType penjualan per jenis pesanan
+func reportByOrderType(scope reportScope) ([]dto.ReportOrderTypeLine, error) {
+	cond, args := scope.where()
+	lines := make([]dto.ReportOrderTypeLine, 0)
+	if err := scope.db.Table("transactions AS t").
+		Select("t.order_type, COUNT(*) AS total_transactions, COALESCE(SUM(t.total_amount), 0) AS total_sales").
+		Where(cond, args...).
+		Group("t.order_type").
+		Order("total_sales DESC").
+		Scan(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
+
+// reportByPaymentMethod nominal per metode bayar dari tender yang paid; transaksi lama tanpa payment memakai payment_method transaksi
+func reportByPaymentMethod(scope reportScope) ([]dto.ReportPaymentMethodLine, error) {
+	cond, args := scope.where()
+	query := `SELECT x.method AS payment_method, COUNT(DISTINCT x.transaction_id) AS total_transactions, COALESCE(SUM(x.amount), 0) AS amount
+		FROM (
+			SELECT p.method, p.transaction_id, p.amount
+			FROM payments AS p JOIN transactions AS t ON t.id = p.transaction_id
+			WHERE p.status = ? AND ` + cond + `
+			UNION ALL
+			SELECT t.payment_method, t.id, t.total_amount
+			FROM transactions AS t
+			WHERE ` + cond + ` AND NOT EXISTS (SELECT 1 FROM payments AS p WHERE p.transaction_id = t.id AND p.status = ?)
+		) AS x
+		GROUP BY x.method
+		ORDER BY amount DESC`
+	params := append([]interface{}{TenderStatusPaid}, args...)
+	params = append(params, args...)
+	params = append(params, TenderStatusPaid)
+
+	lines := make([]dto.ReportPaymentMethodLine, 0)
+	if err := scope.db.Raw(query, params...).Scan(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
+
+// reportByPromo pemakaian kode promo (transaksi tanpa promo tidak ikut)
+func reportByPromo(scope reportScope) ([]dto.ReportPromoLine, error) {
+	cond, args := scope.where()
+	lines := make([]dto.ReportPromoLine, 0)
+	if err := scope.db.Table("transactions AS t").
+		Select("UPPER(t.promo_code) AS promo_code, COUNT(*) AS total_transactions, COALESCE(SUM(t.discount), 0) AS total_discount, COALESCE(SUM(t.total_amount), 0) AS total_sales").
+		Where("t.promo_code <> ''").
+		Where(cond, args...).
+		Group("UPPER(t.promo_code)").
+		Order("total_transactions DESC").
+		Scan(&lines).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	return lines, nil
+}
@@ -1,56 +1,69 @@
 package services
 
 import (
+	"errors"
+	"math"
 	"pos-go/config"
 	"pos-go/dto"
 	transaction_model "pos-go/models/transaction_model"
 	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// ErrInvalidDateRange date_to lebih awal dari date_from
+var ErrInvalidDateRange = errors.New("date_to tidak boleh lebih awal dari date_from")
+
 type ReportService struct{}
 
 func NewReportService() ReportService {
 	return ReportService{}
 }
 
-// GetReportByDate mengembalikan laporan harian: agregasi + list transaksi (completed & paid) untuk tanggal tertentu.
+// GetReportByDate mengembalikan laporan harian: agregasi + list transaksi (completed & dibayar) untuk tanggal tertentu.
+// Refund yang diproses di tanggal tersebut dikurangkan dari total tunai / non-tunai.
 // Jika cashierID != nil, hanya transaksi yang closed_by_user_id = cashierID (laporan per kasir).
 // dateStr format: YYYY-MM-DD.
 func (s ReportService) GetReportByDate(dateStr string, cashierID *uuid.UUID) (*dto.ReportResponse, error) {
-	date, err := time.Parse("2006-01-02", dateStr)
+	return reportByDate(config.DB, dateStr, cashierID)
+}
+
+// reportByDate isi GetReportByDate dengan koneksi db (dipakai juga di dalam transaksi penutupan Z-report)
+func reportByDate(db *gorm.DB, dateStr string, cashierID *uuid.UUID) (*dto.ReportResponse, error) {
+	startOfDay, endOfDay, err := config.StoreCalendar.ParseDay(dateStr)
 	if err != nil {
 		return nil, err
 	}
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := startOfDay.AddDate(0, 0, 1)
 
 	var transactions []transaction_model.Transaction
-	q := config.DB.Where(
-		"created_at >= ? AND created_at < ? AND order_status = ? AND payment_status = ?",
-		startOfDay, endOfDay, "completed", "paid",
-	)
-	if cashierID != nil {
-		q = q.Where("closed_by_user_id = ?", *cashierID)
-	}
-	if err := q.Order("created_at ASC").Find(&transactions).Error; err != nil {
+	q := reportTransactionsQuery(db, startOfDay, endOfDay, cashierID)
+	if err := q.Preload("Taxes").Preload("Payments", "status = ?", TenderStatusPaid).Order("created_at ASC").Find(&transactions).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Agregasi
-	var totalSales, totalCash, totalNonCash, totalDiscount, totalTax float64
+	var totalSales, totalCash, totalNonCash, totalDiscount, totalServiceCharge, totalTax utils.Money
 	for _, t := range transactions {
 		totalSales += t.TotalAmount
 		totalDiscount += t.Discount
+		totalServiceCharge += t.ServiceCharge
 		totalTax += t.Tax
-		if t.PaymentMethod == "cash" {
-			totalCash += t.TotalAmount
-		} else {
-			totalNonCash += t.TotalAmount
-		}
+		cash := cashTendered(t)
+		totalCash += cash
+		totalNonCash += t.TotalAmount - cash
+	}
+
+	// Refund yang diproses di tanggal ini mengurangi penjualan (tunai & non-tunai)
+	totalRefund, cashRefund, err := refundTotals(db, startOfDay, endOfDay, cashierID)
+	if err != nil {
+		return nil, err
 	}
+	totalCash -= cashRefund
+	totalNonCash -= totalRefund - cashRefund
 
 	summary := dto.ReportSummary{
 		Date:               dateStr,
@@ -59,7 +72,11 @@ func (s ReportService) GetReportByDate(dateStr string, cashierID *uuid.UUID) (*d
 		TotalCash:          totalCash,
 		TotalNonCash:       totalNonCash,
 		TotalDiscount:      totalDiscount,
+		TotalServiceCharge: totalServiceCharge,
 		TotalTax:           totalTax,
+		Taxes:              aggregateTaxes(transactions),
+		TotalRefund:        totalRefund,
+		NetSales:           totalSales - totalRefund,
 	}
 
 	// Map closed_by_user_id -> name
@@ -72,7 +89,7 @@ func (s ReportService) GetReportByDate(dateStr string, cashierID *uuid.UUID) (*d
 	userNameMap := make(map[uuid.UUID]string)
 	if len(userIDs) > 0 {
 		var users []user_model.User
-		if err := config.DB.Where("id IN ?", userIDs).Find(&users).Error; err == nil {
+		if err := db.Where("id IN ?", userIDs).Find(&users).Error; err == nil {
 			for _, u := range users {
 				userNameMap[u.ID] = u.Name
 			}
@@ -90,13 +107,15 @@ func (s ReportService) GetReportByDate(dateStr string, cashierID *uuid.UUID) (*d
 		}
 		list = append(list, dto.ReportTransactionItem{
 			ID:               t.ID,
+			OrderNumber:      t.OrderNumber,
 			CustomerName:     t.CustomerName,
 			OrderType:        t.OrderType,
 			PaymentMethod:    t.PaymentMethod,
 			TotalAmount:      t.TotalAmount,
+			RefundedAmount:   t.RefundedAmount,
 			ClosedByUserID:   t.ClosedByUserID,
 			ClosedByUserName: closedByName,
-			CreatedAt:        t.CreatedAt.Format(time.RFC3339),
+			CreatedAt:        config.StoreCalendar.Format(t.CreatedAt),
 		})
 	}
 
@@ -106,7 +125,109 @@ func (s ReportService) GetReportByDate(dateStr string, cashierID *uuid.UUID) (*d
 	}, nil
 }
 
+// reportTransactionsQuery transaksi yang masuk laporan: completed & dibayar dalam rentang [start, end), opsional per kasir
+func reportTransactionsQuery(db *gorm.DB, start, end time.Time, cashierID *uuid.UUID) *gorm.DB {
+	q := db.Model(&transaction_model.Transaction{}).Where(
+		"created_at >= ? AND created_at < ? AND order_status = ? AND payment_status IN ?",
+		start, end, "completed", PaidPaymentStatuses,
+	)
+	if cashierID != nil {
+		q = q.Where("closed_by_user_id = ?", *cashierID)
+	}
+	return q
+}
+
+// ExportReport menulis transaksi laporan tanggal dateFrom..dateTo (YYYY-MM-DD, inklusif) ke w secara streaming:
+// sheet transaksi (kolom dto.ReportTransactionItem) dan sheet rincian item. Untuk laporan harian dateFrom = dateTo.
+func (s ReportService) ExportReport(dateFrom, dateTo string, cashierID *uuid.UUID, w utils.TableWriter) error {
+	start, _, err := config.StoreCalendar.ParseDay(dateFrom)
+	if err != nil {
+		return err
+	}
+	_, end, err := config.StoreCalendar.ParseDay(dateTo)
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return ErrInvalidDateRange
+	}
+	return exportTransactionSheets(w, func() *gorm.DB {
+		return reportTransactionsQuery(config.DB, start, end, cashierID)
+	}, false)
+}
+
+// cashTendered porsi tunai transaksi: jumlah tender tunai yang paid (Payments sudah di-preload);
+// transaksi lama tanpa payment memakai payment_method transaksi.
+func cashTendered(t transaction_model.Transaction) utils.Money {
+	if len(t.Payments) == 0 {
+		if t.PaymentMethod == "cash" {
+			return t.TotalAmount
+		}
+		return 0
+	}
+	var cash utils.Money
+	for _, p := range t.Payments {
+		if p.Method == "cash" && p.Status == TenderStatusPaid {
+			cash += p.Amount
+		}
+	}
+	return cash
+}
+
+// refundTotals total refund (semua metode & khusus tunai) yang diproses dalam rentang waktu.
+// Jika cashierID != nil, hanya refund atas transaksi yang ditutup kasir tersebut.
+func refundTotals(db *gorm.DB, start, end time.Time, cashierID *uuid.UUID) (utils.Money, utils.Money, error) {
+	var row struct {
+		Total utils.Money
+		Cash  utils.Money
+	}
+	q := db.Table("refunds").
+		Select("COALESCE(SUM(refunds.amount), 0) AS total, COALESCE(SUM(CASE WHEN refunds.payment_method = 'cash' THEN refunds.amount ELSE 0 END), 0) AS cash").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.created_at >= ? AND refunds.created_at < ? AND refunds.status = ? AND refunds.deleted_at IS NULL", start, end, "succeeded")
+	if cashierID != nil {
+		q = q.Where("transactions.closed_by_user_id = ?", *cashierID)
+	}
+	if err := q.Scan(&row).Error; err != nil {
+		return 0, 0, ErrDatabaseError
+	}
+	return row.Total, row.Cash, nil
+}
+
+// aggregateTaxes menjumlahkan rincian pajak / service charge per aturan (nama, jenis, tarif, inklusif)
+// dari transaction_taxes, sehingga laporan memakai angka yang sama dengan struk.
+func aggregateTaxes(transactions []transaction_model.Transaction) []dto.ReportTaxLine {
+	type key struct {
+		name        string
+		typ         string
+		rate        float64
+		isInclusive bool
+	}
+	index := make(map[key]int)
+	lines := make([]dto.ReportTaxLine, 0)
+	for _, t := range transactions {
+		for _, tt := range t.Taxes {
+			k := key{tt.Name, tt.Type, tt.Rate, tt.IsInclusive}
+			i, ok := index[k]
+			if !ok {
+				i = len(lines)
+				index[k] = i
+				lines = append(lines, dto.ReportTaxLine{Name: tt.Name, Type: tt.Type, Rate: tt.Rate, IsInclusive: tt.IsInclusive})
+			}
+			lines[i].TaxableAmount += tt.TaxableAmount
+			lines[i].Amount += tt.Amount
+		}
+	}
+	return lines
+}
+
+// succeededRefundSQL total refund berhasil per transaksi (alias t). transactions.refunded_amount tidak dipakai karena
+// ikut menampung refund yang masih pending, sama seperti refundTotals yang hanya menghitung refund succeeded.
+const succeededRefundSQL = "(SELECT COALESCE(SUM(r.amount), 0) FROM refunds r WHERE r.transaction_id = t.id AND r.status = '" +
+	RefundStatusSucceeded + "' AND r.deleted_at IS NULL)"
+
 // GetReportCharts mengembalikan data untuk grafik: harian (N hari terakhir) dan bulanan (N bulan terakhir).
+// Agregasi dijalankan di database (GROUP BY per hari / bulan bisnis).
 func (s ReportService) GetReportCharts(days, months int) (*dto.ChartResponse, error) {
 	if days <= 0 {
 		days = 7
@@ -115,99 +236,68 @@ func (s ReportService) GetReportCharts(days, months int) (*dto.ChartResponse, er
 		months = 6
 	}
 
-	now := time.Now().UTC()
-	startDaily := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -days+1)
-	endMonthly := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -months, 0)
+	// Bucket per hari / bulan bisnis toko (zona waktu + cutoff), bukan hari UTC
+	cal := config.StoreCalendar
+	today := cal.Today()
+	startDaily := today.AddDate(0, 0, -days+1)
+	endMonthly := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -months, 0)
 
-	var txDaily []transaction_model.Transaction
-	if err := config.DB.Where(
-		"created_at >= ? AND order_status = ? AND payment_status = ?",
-		startDaily, "completed", "paid",
-	).Find(&txDaily).Error; err != nil {
+	dateExpr, dateArgs := businessDateSQL("t.created_at")
+	var dailyRows []struct {
+		Day          time.Time
+		Transactions int
+		Sales        utils.Money
+	}
+	if err := config.DB.Table("transactions AS t").
+		Select(dateExpr+" AS day, COUNT(*) AS transactions, COALESCE(SUM(t.total_amount - "+succeededRefundSQL+"), 0) AS sales", dateArgs...).
+		Where("t.deleted_at IS NULL AND t.created_at >= ? AND t.order_status = ? AND t.payment_status IN ?",
+			cal.DayStart(startDaily), OrderStatusCompleted, PaidPaymentStatuses).
+		Group("day").
+		Scan(&dailyRows).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
-
-	// Agregasi per hari
-	dailyMap := make(map[string]struct {
-		count int
-		sales float64
-	})
-	for d := 0; d < days; d++ {
-		day := startDaily.AddDate(0, 0, d)
-		key := day.Format("2006-01-02")
-		dailyMap[key] = struct {
-			count int
-			sales float64
-		}{0, 0}
-	}
-	for _, t := range txDaily {
-		key := t.CreatedAt.UTC().Format("2006-01-02")
-		if v, ok := dailyMap[key]; ok {
-			v.count++
-			v.sales += t.TotalAmount
-			dailyMap[key] = v
-		} else {
-			dailyMap[key] = struct {
-				count int
-				sales float64
-			}{1, t.TotalAmount}
-		}
+	dailyIndex := make(map[string]int, len(dailyRows))
+	for i, r := range dailyRows {
+		dailyIndex[r.Day.Format("2006-01-02")] = i
 	}
 	dailyList := make([]dto.ChartDailyItem, 0, days)
 	for d := 0; d < days; d++ {
-		day := startDaily.AddDate(0, 0, d)
-		key := day.Format("2006-01-02")
-		v := dailyMap[key]
-		dailyList = append(dailyList, dto.ChartDailyItem{
-			Date:              key,
-			TotalTransactions: v.count,
-			TotalSales:        v.sales,
-		})
+		key := startDaily.AddDate(0, 0, d).Format("2006-01-02")
+		item := dto.ChartDailyItem{Date: key}
+		if i, ok := dailyIndex[key]; ok {
+			item.TotalTransactions = dailyRows[i].Transactions
+			item.TotalSales = dailyRows[i].Sales
+		}
+		dailyList = append(dailyList, item)
 	}
 
-	// Transaksi untuk bulanan (last N months)
-	var txMonthly []transaction_model.Transaction
-	if err := config.DB.Where(
-		"created_at >= ? AND order_status = ? AND payment_status = ?",
-		endMonthly, "completed", "paid",
-	).Find(&txMonthly).Error; err != nil {
+	// Bulanan (N bulan terakhir), dikelompokkan per bulan hari bisnis
+	var monthlyRows []struct {
+		Month        string
+		Transactions int
+		Sales        utils.Money
+	}
+	if err := config.DB.Table("transactions AS t").
+		Select("to_char("+dateExpr+", 'YYYY-MM') AS month, COUNT(*) AS transactions, COALESCE(SUM(t.total_amount - "+succeededRefundSQL+"), 0) AS sales", dateArgs...).
+		Where("t.deleted_at IS NULL AND t.created_at >= ? AND t.order_status = ? AND t.payment_status IN ?",
+			cal.DayStart(endMonthly), OrderStatusCompleted, PaidPaymentStatuses).
+		Group("month").
+		Scan(&monthlyRows).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
-	monthlyMap := make(map[string]struct {
-		count int
-		sales float64
-	})
-	for m := 0; m < months; m++ {
-		month := endMonthly.AddDate(0, m, 0)
-		key := month.Format("2006-01")
-		monthlyMap[key] = struct {
-			count int
-			sales float64
-		}{0, 0}
-	}
-	for _, t := range txMonthly {
-		key := t.CreatedAt.UTC().Format("2006-01")
-		if v, ok := monthlyMap[key]; ok {
-			v.count++
-			v.sales += t.TotalAmount
-			monthlyMap[key] = v
-		} else {
-			monthlyMap[key] = struct {
-				count int
-				sales float64
-			}{1, t.TotalAmount}
-		}
+	monthlyIndex := make(map[string]int, len(monthlyRows))
+	for i, r := range monthlyRows {
+		monthlyIndex[r.Month] = i
 	}
 	monthlyList := make([]dto.ChartMonthlyItem, 0, months)
 	for m := 0; m < months; m++ {
-		month := endMonthly.AddDate(0, m, 0)
-		key := month.Format("2006-01")
-		v := monthlyMap[key]
-		monthlyList = append(monthlyList, dto.ChartMonthlyItem{
-			Month:             key,
-			TotalTransactions: v.count,
-			TotalSales:        v.sales,
-		})
+		key := endMonthly.AddDate(0, m, 0).Format("2006-01")
+		item := dto.ChartMonthlyItem{Month: key}
+		if i, ok := monthlyIndex[key]; ok {
+			item.TotalTransactions = monthlyRows[i].Transactions
+			item.TotalSales = monthlyRows[i].Sales
+		}
+		monthlyList = append(monthlyList, item)
 	}
 
 	return &dto.ChartResponse{
@@ -216,3 +306,77 @@ func (s ReportService) GetReportCharts(days, months int) (*dto.ChartResponse, er
 	}, nil
 }
 
+
+// GetIngredientUsageReport membandingkan pemakaian bahan teoritis (resep x penjualan yang dibayar) dengan
+// pemakaian aktual (teoritis + waste + selisih stock opname) per bahan dalam rentang tanggal (inklusif).
+// Sumber data: ingredient_movements, diagregasi dengan satu GROUP BY.
+func (s ReportService) GetIngredientUsageReport(dateFrom, dateTo string) (*dto.IngredientUsageReport, error) {
+	from, err := time.Parse("2006-01-02", dateFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse("2006-01-02", dateTo)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, ErrInvalidDateRange
+	}
+
+	var rows []struct {
+		IngredientID uuid.UUID
+		Name         string
+		Unit         string
+		LastUnitCost utils.Money
+		Usage        float64
+		Waste        float64
+		Correction   float64
+		Purchased    float64
+	}
+	if err := config.DB.Table("ingredient_movements AS m").
+		Select(`m.ingredient_id, i.name, i.unit, i.last_unit_cost,
+			COALESCE(SUM(CASE WHEN m.type = ? THEN -m.quantity END), 0) AS usage,
+			COALESCE(SUM(CASE WHEN m.type = ? THEN -m.quantity END), 0) AS waste,
+			COALESCE(SUM(CASE WHEN m.type = ? THEN m.quantity END), 0) AS correction,
+			COALESCE(SUM(CASE WHEN m.type = ? THEN m.quantity END), 0) AS purchased`,
+			IngredientMovementUsage, IngredientMovementWaste, IngredientMovementCorrection, IngredientMovementPurchase).
+		Joins("JOIN ingredients AS i ON i.id = m.ingredient_id").
+		Where("m.created_at >= ? AND m.created_at < ?", config.StoreCalendar.DayStart(from), config.StoreCalendar.DayStart(to.AddDate(0, 0, 1))).
+		Group("m.ingredient_id, i.name, i.unit, i.last_unit_cost").
+		Scan(&rows).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	report := &dto.IngredientUsageReport{
+		DateFrom: from.Format("2006-01-02"),
+		DateTo:   to.Format("2006-01-02"),
+		Items:    make([]dto.IngredientUsageLine, 0, len(rows)),
+	}
+	for _, r := range rows {
+		actual := r.Usage + r.Waste - r.Correction
+		variance := actual - r.Usage
+		line := dto.IngredientUsageLine{
+			IngredientID: r.IngredientID,
+			Name:         r.Name,
+			Unit:         r.Unit,
+			Theoretical:  r.Usage,
+			Waste:        r.Waste,
+			Correction:   r.Correction,
+			Purchased:    r.Purchased,
+			Actual:       actual,
+			Variance:     variance,
+			UnitCost:     r.LastUnitCost,
+			VarianceCost: utils.RoundMoney(float64(r.LastUnitCost) * variance),
+		}
+		if r.Usage != 0 {
+			line.VariancePercent = math.Round(variance/r.Usage*10000) / 100
+		}
+		report.Items = append(report.Items, line)
+		report.TotalVarianceCost += line.VarianceCost
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		return report.Items[i].VarianceCost > report.Items[j].VarianceCost
+	})
+
+	return report, nil
+}
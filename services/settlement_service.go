@@ -2,18 +2,38 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"pos-go/config"
 	"pos-go/dto"
 	settlement_model "pos-go/models/settlement_model"
-	transaction_model "pos-go/models/transaction_model"
 	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var ErrSettlementAlreadyExists = errors.New("Settlement untuk tanggal ini sudah ada")
+// Status settlement (review admin)
+const (
+	SettlementStatusSubmitted = "submitted"
+	SettlementStatusApproved  = "approved"
+	SettlementStatusRejected  = "rejected"
+)
+
+// Sentinel errors
+var (
+	ErrActualCashRequired     = errors.New("Isi actual_cash atau rincian denominations")
+	ErrDenominationMismatch   = errors.New("Total rincian pecahan tidak sama dengan actual_cash")
+	ErrSettlementNotFound     = errors.New("Settlement tidak ditemukan")
+	ErrSettlementNoteRequired = errors.New("Catatan wajib diisi karena selisih melebihi batas")
+	ErrSettlementNotSubmitted = errors.New("Settlement tidak sedang menunggu review")
+	ErrSettlementNotRejected  = errors.New("Hanya settlement yang ditolak yang bisa diajukan ulang")
+	ErrReviewNoteRequired     = errors.New("Catatan wajib diisi saat menolak settlement")
+	ErrSettlementNotOwner     = errors.New("Settlement ini milik kasir lain")
+)
 
 type SettlementService struct{}
 
@@ -21,156 +41,609 @@ func NewSettlementService() SettlementService {
 	return SettlementService{}
 }
 
-// expectedCashFromTransactionsByUser menghitung total tunai yang seharusnya untuk kasir tersebut (transaksi cash, paid, completed, closed_by_user_id = userID).
-func expectedCashFromTransactionsByUser(startOfDay, endOfDay time.Time, userID uuid.UUID) (float64, error) {
-	var total float64
-	err := config.DB.Model(&transaction_model.Transaction{}).
-		Select("COALESCE(SUM(total_amount), 0)").
-		Where("created_at >= ? AND created_at < ? AND order_status = ? AND payment_status = ? AND payment_method = ? AND closed_by_user_id = ?",
-			startOfDay, endOfDay, "completed", "paid", "cash", userID).
-		Scan(&total).Error
-	if err != nil {
-		return 0, ErrDatabaseError
+// countSettlementCash uang fisik dari request: actual_cash dan/atau rincian pecahan (keduanya harus cocok jika diisi)
+func countSettlementCash(req dto.CreateSettlementRequest) (utils.Money, []settlement_model.SettlementDenomination, error) {
+	denominations := make([]settlement_model.SettlementDenomination, 0, len(req.Denominations))
+	var counted utils.Money
+	for _, d := range req.Denominations {
+		if d.Quantity == 0 {
+			continue
+		}
+		subtotal := d.Denomination.Mul(d.Quantity)
+		counted += subtotal
+		denominations = append(denominations, settlement_model.SettlementDenomination{
+			Denomination: d.Denomination,
+			Quantity:     d.Quantity,
+			Subtotal:     subtotal,
+		})
+	}
+
+	var actualCash utils.Money
+	switch {
+	case req.ActualCash != nil && len(req.Denominations) > 0:
+		if *req.ActualCash != counted {
+			return 0, nil, fmt.Errorf("%w: pecahan Rp %s, actual_cash Rp %s", ErrDenominationMismatch, counted, *req.ActualCash)
+		}
+		actualCash = counted
+	case req.ActualCash != nil:
+		actualCash = *req.ActualCash
+	case len(req.Denominations) > 0:
+		actualCash = counted
+	default:
+		return 0, nil, ErrActualCashRequired
+	}
+	return actualCash, denominations, nil
+}
+
+// checkSettlementNote catatan wajib jika selisih (nilai absolut) melebihi config.SettlementNoteThreshold
+func checkSettlementNote(discrepancy utils.Money, note string) error {
+	if discrepancy < 0 {
+		discrepancy = -discrepancy
+	}
+	if discrepancy > config.SettlementNoteThreshold && note == "" {
+		return fmt.Errorf("%w (Rp %s)", ErrSettlementNoteRequired, config.SettlementNoteThreshold)
+	}
+	return nil
+}
+
+// createSettlementRevision mencatat pengajuan settlement saat ini ke riwayat revisi
+func createSettlementRevision(tx *gorm.DB, settlement settlement_model.Settlement, userID uuid.UUID) error {
+	snapshot := make([]settlement_model.DenominationSnapshot, 0, len(settlement.Denominations))
+	for _, d := range settlement.Denominations {
+		snapshot = append(snapshot, settlement_model.DenominationSnapshot{
+			Denomination: d.Denomination,
+			Quantity:     d.Quantity,
+			Subtotal:     d.Subtotal,
+		})
+	}
+	revision := settlement_model.SettlementRevision{
+		SettlementID:      settlement.ID,
+		Revision:          settlement.Revision,
+		ExpectedCash:      settlement.ExpectedCash,
+		ActualCash:        settlement.ActualCash,
+		Discrepancy:       settlement.Discrepancy,
+		Denominations:     snapshot,
+		Note:              settlement.Note,
+		SubmittedByUserID: userID,
+		Status:            SettlementStatusSubmitted,
 	}
-	return total, nil
+	if err := tx.Create(&revision).Error; err != nil {
+		return ErrDatabaseError
+	}
+	return nil
 }
 
-// CreateSettlement menyimpan settlement (tutup kasir). Satu settlement per (date, user_id).
-func (s SettlementService) CreateSettlement(userID uuid.UUID, dateStr string, actualCash float64) (*dto.SettlementResponse, error) {
-	date, err := time.Parse("2006-01-02", dateStr)
+// CreateSettlement menutup shift open milik user (tutup kasir): uang fisik dihitung (actual_cash dan/atau rincian pecahan),
+// expected cash dihitung dari jendela shift, lalu shift ditandai closed. Satu settlement per shift, berstatus submitted
+// (menunggu review admin). Selisih di atas batas wajib disertai catatan.
+func (s SettlementService) CreateSettlement(userID uuid.UUID, req dto.CreateSettlementRequest) (*dto.SettlementResponse, error) {
+	actualCash, denominations, err := countSettlementCash(req)
 	if err != nil {
 		return nil, err
 	}
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := startOfDay.AddDate(0, 0, 1)
+	note := strings.TrimSpace(req.Note)
+
+	var settlement settlement_model.Settlement
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		shift, err := findOpenShift(tx, userID, true)
+		if err != nil {
+			return err
+		}
+
+		closedAt := time.Now()
+		shift.ClosedAt = &closedAt
+		summary, err := shiftCashSummary(tx, shift)
+		if err != nil {
+			return err
+		}
+
+		if err := checkSettlementNote(actualCash-summary.ExpectedCash, note); err != nil {
+			return err
+		}
+
+		settlement = settlement_model.Settlement{
+			ShiftID:       &shift.ID,
+			Date:          config.StoreCalendar.DateOf(shift.OpenedAt),
+			UserID:        userID,
+			OpeningFloat:  summary.OpeningFloat,
+			ExpectedCash:  summary.ExpectedCash,
+			ActualCash:    actualCash,
+			Discrepancy:   actualCash - summary.ExpectedCash,
+			TotalTendered: summary.TotalTendered,
+			TotalChange:   summary.TotalChange,
+			CashRefunds:   summary.CashRefunds,
+			PayIns:        summary.PayIns,
+			PayOuts:       summary.PayOuts,
+			Note:          note,
+			Denominations: denominations,
+			Status:        SettlementStatusSubmitted,
+			Revision:      1,
+		}
+		if err := tx.Create(&settlement).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if err := createSettlementRevision(tx, settlement, userID); err != nil {
+			return err
+		}
 
-	expectedCash, err := expectedCashFromTransactionsByUser(startOfDay, endOfDay, userID)
+		if err := tx.Model(&settlement_model.Shift{}).Where("id = ?", shift.ID).Updates(map[string]interface{}{
+			"status":    ShiftStatusClosed,
+			"closed_at": closedAt,
+		}).Error; err != nil {
+			return ErrDatabaseError
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	discrepancy := actualCash - expectedCash
+	return toSettlementResponse(&settlement), nil
+}
 
-	// Cek sudah ada settlement untuk (date, user_id)?
-	var existing settlement_model.Settlement
-	if err := config.DB.Where("date = ? AND user_id = ?", startOfDay, userID).First(&existing).Error; err == nil {
-		return nil, ErrSettlementAlreadyExists
-	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, ErrDatabaseError
+// shiftsWithSettlement shift yang dibuka dalam rentang waktu (opsional hanya milik satu user) beserta settlement-nya
+func shiftsWithSettlement(db *gorm.DB, start, end time.Time, userID *uuid.UUID) ([]settlement_model.Shift, map[uuid.UUID]*settlement_model.Settlement, error) {
+	q := db.Preload("CashMovements", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("opened_at >= ? AND opened_at < ?", start, end)
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	var shifts []settlement_model.Shift
+	if err := q.Order("opened_at ASC").Find(&shifts).Error; err != nil {
+		return nil, nil, ErrDatabaseError
 	}
 
-	settlement := settlement_model.Settlement{
-		Date:         startOfDay,
-		UserID:       userID,
-		ExpectedCash: expectedCash,
-		ActualCash:   actualCash,
-		Discrepancy:  discrepancy,
+	settlements := make(map[uuid.UUID]*settlement_model.Settlement, len(shifts))
+	if len(shifts) == 0 {
+		return shifts, settlements, nil
 	}
-	if err := config.DB.Create(&settlement).Error; err != nil {
-		return nil, ErrDatabaseError
+	ids := make([]uuid.UUID, 0, len(shifts))
+	for _, sh := range shifts {
+		ids = append(ids, sh.ID)
 	}
-
-	return toSettlementResponse(&settlement), nil
+	var rows []settlement_model.Settlement
+	if err := db.Preload("Denominations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("denomination DESC")
+	}).Where("shift_id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, nil, ErrDatabaseError
+	}
+	for i := range rows {
+		settlements[*rows[i].ShiftID] = &rows[i]
+	}
+	return shifts, settlements, nil
 }
 
-// GetSettlementByDateAndUser mengembalikan settlement untuk tanggal dan user (kasir) tertentu. Nil jika belum ada.
-func (s SettlementService) GetSettlementByDateAndUser(dateStr string, userID uuid.UUID) (*dto.SettlementResponse, error) {
-	date, err := time.Parse("2006-01-02", dateStr)
+// GetSettlementsByDate shift milik user yang dibuka pada tanggal tersebut, beserta rekap tunai dan settlement (untuk GET /settlement?date=)
+func (s SettlementService) GetSettlementsByDate(dateStr string, userID uuid.UUID) (*dto.GetSettlementResponse, error) {
+	start, end, err := config.StoreCalendar.ParseDay(dateStr)
+	if err != nil {
+		return nil, err
+	}
+	shifts, settlements, err := shiftsWithSettlement(config.DB, start, end, &userID)
 	if err != nil {
 		return nil, err
 	}
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
-	var settlement settlement_model.Settlement
-	if err := config.DB.Where("date = ? AND user_id = ?", startOfDay, userID).First(&settlement).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil // belum ada settlement
+	shiftService := NewShiftService()
+	res := make([]dto.ShiftResponse, 0, len(shifts))
+	for _, sh := range shifts {
+		item, err := shiftService.shiftResponse(sh, settlements[sh.ID])
+		if err != nil {
+			return nil, err
 		}
-		return nil, ErrDatabaseError
+		res = append(res, *item)
 	}
-	return toSettlementResponse(&settlement), nil
+	return &dto.GetSettlementResponse{
+		Date:   dateStr,
+		Shifts: res,
+	}, nil
+}
+
+// GetSettlementStatusByDate untuk admin: semua shift yang dibuka di tanggal tersebut + status settlement (sudah/belum).
+// Settlement lama (per tanggal, sebelum ada shift) ikut ditampilkan tanpa shift_id.
+func (s SettlementService) GetSettlementStatusByDate(dateStr string) (*dto.GetSettlementStatusByDateResponse, error) {
+	return settlementStatusByDate(config.DB, dateStr)
 }
 
-// GetSettlementWithExpected mengembalikan expected_cash untuk tanggal + kasir tersebut + settlement jika sudah ada (untuk GET /settlement?date=)
-func (s SettlementService) GetSettlementWithExpected(dateStr string, userID uuid.UUID) (*dto.GetSettlementResponse, error) {
-	expectedCash, err := s.GetExpectedCashForDateAndUser(dateStr, userID)
+// settlementStatusByDate isi GetSettlementStatusByDate dengan koneksi db (dipakai juga saat menutup Z-report)
+func settlementStatusByDate(db *gorm.DB, dateStr string) (*dto.GetSettlementStatusByDateResponse, error) {
+	start, end, err := config.StoreCalendar.ParseDay(dateStr)
 	if err != nil {
 		return nil, err
 	}
-	settlement, err := s.GetSettlementByDateAndUser(dateStr, userID)
+	shifts, settlements, err := shiftsWithSettlement(db, start, end, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &dto.GetSettlementResponse{
-		ExpectedCash: expectedCash,
-		Settlement:   settlement,
+
+	names := make(map[uuid.UUID]string)
+	userName := func(uid uuid.UUID) string {
+		if name, ok := names[uid]; ok {
+			return name
+		}
+		name := "-"
+		var u user_model.User
+		if err := db.Select("name").First(&u, "id = ?", uid).Error; err == nil {
+			name = u.Name
+		}
+		names[uid] = name
+		return name
+	}
+
+	items := make([]dto.SettlementStatusItem, 0, len(shifts))
+	for _, sh := range shifts {
+		summary, err := shiftCashSummary(db, sh)
+		if err != nil {
+			return nil, err
+		}
+		item := dto.SettlementStatusItem{
+			ShiftID:       sh.ID.String(),
+			UserID:        sh.UserID.String(),
+			UserName:      userName(sh.UserID),
+			Status:        sh.Status,
+			OpenedAt:      config.StoreCalendar.Format(sh.OpenedAt),
+			ExpectedCash:  summary.ExpectedCash,
+			TotalTendered: summary.TotalTendered,
+			TotalChange:   summary.TotalChange,
+		}
+		if sh.ClosedAt != nil {
+			closedAt := config.StoreCalendar.Format(*sh.ClosedAt)
+			item.ClosedAt = &closedAt
+		}
+		if settlement := settlements[sh.ID]; settlement != nil {
+			item.Settlement = toSettlementResponse(settlement)
+		}
+		items = append(items, item)
+	}
+
+	var legacy []settlement_model.Settlement
+	if err := db.Where("date = ? AND shift_id IS NULL", dateStr).Order("created_at ASC").Find(&legacy).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	for i := range legacy {
+		closedAt := config.StoreCalendar.Format(legacy[i].CreatedAt)
+		items = append(items, dto.SettlementStatusItem{
+			UserID:        legacy[i].UserID.String(),
+			UserName:      userName(legacy[i].UserID),
+			Status:        ShiftStatusClosed,
+			OpenedAt:      legacy[i].Date.Format(time.RFC3339),
+			ClosedAt:      &closedAt,
+			ExpectedCash:  legacy[i].ExpectedCash,
+			TotalTendered: legacy[i].TotalTendered,
+			TotalChange:   legacy[i].TotalChange,
+			Settlement:    toSettlementResponse(&legacy[i]),
+		})
+	}
+
+	return &dto.GetSettlementStatusByDateResponse{
+		Date:  dateStr,
+		Items: items,
 	}, nil
 }
 
-// GetExpectedCashForDateAndUser mengembalikan expected cash untuk tanggal dan kasir (tunai dari transaksi yang ditutup kasir tersebut).
-func (s SettlementService) GetExpectedCashForDateAndUser(dateStr string, userID uuid.UUID) (float64, error) {
-	date, err := time.Parse("2006-01-02", dateStr)
+// ExportSettlementStatus menulis status settlement per shift pada tanggal dateStr ke w: sheet settlement
+// (kolom dto.SettlementStatusItem + ringkasan settlement) dan sheet rincian pecahan uang.
+func (s SettlementService) ExportSettlementStatus(dateStr string, w utils.TableWriter) error {
+	resp, err := s.GetSettlementStatusByDate(dateStr)
 	if err != nil {
-		return 0, err
+		return err
+	}
+
+	if w.Accepts(ExportSheetSettlements) {
+		if err := w.NewSheet(ExportSheetSettlements, []string{
+			"shift_id", "user_id", "user_name", "status", "opened_at", "closed_at", "expected_cash", "total_tendered", "total_change",
+			"settlement_id", "actual_cash", "discrepancy", "settlement_status", "revision", "note", "review_note",
+		}); err != nil {
+			return err
+		}
+		for _, it := range resp.Items {
+			values := []interface{}{
+				it.ShiftID, it.UserID, it.UserName, it.Status, it.OpenedAt, it.ClosedAt, it.ExpectedCash, it.TotalTendered, it.TotalChange,
+			}
+			if st := it.Settlement; st != nil {
+				values = append(values, st.ID, st.ActualCash, st.Discrepancy, st.Status, st.Revision, st.Note, st.ReviewNote)
+			} else {
+				// shift tanpa settlement: kolom settlement dikosongkan agar jumlah kolom sama dengan header
+				values = append(values, nil, nil, nil, nil, nil, nil, nil)
+			}
+			if err := w.WriteRow(values...); err != nil {
+				return err
+			}
+		}
 	}
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := startOfDay.AddDate(0, 0, 1)
-	return expectedCashFromTransactionsByUser(startOfDay, endOfDay, userID)
+
+	if !w.Accepts(ExportSheetDenominations) {
+		return nil
+	}
+	if err := w.NewSheet(ExportSheetDenominations, []string{
+		"shift_id", "user_id", "user_name", "settlement_id", "denomination", "quantity", "subtotal",
+	}); err != nil {
+		return err
+	}
+	for _, it := range resp.Items {
+		if it.Settlement == nil {
+			continue
+		}
+		for _, d := range it.Settlement.Denominations {
+			if err := w.WriteRow(it.ShiftID, it.UserID, it.UserName, it.Settlement.ID, d.Denomination, d.Quantity, d.Subtotal); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
-// GetSettlementStatusByDate untuk admin: daftar kasir yang punya transaksi di tanggal tersebut + status settlement (sudah/belum).
-func (s SettlementService) GetSettlementStatusByDate(dateStr string) (*dto.GetSettlementStatusByDateResponse, error) {
-	date, err := time.Parse("2006-01-02", dateStr)
+// lockSettlement mengambil settlement dengan row lock (review dan ajukan ulang tidak boleh saling timpa)
+func lockSettlement(tx *gorm.DB, id uuid.UUID) (settlement_model.Settlement, error) {
+	var settlement settlement_model.Settlement
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&settlement, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settlement_model.Settlement{}, ErrSettlementNotFound
+		}
+		return settlement_model.Settlement{}, ErrDatabaseError
+	}
+	return settlement, nil
+}
+
+// ResubmitSettlement kasir mengajukan ulang settlement yang ditolak dengan hitungan uang baru. Expected cash tetap
+// (shift sudah ditutup); revision naik, rincian pecahan diganti, dan pengajuan baru dicatat di riwayat revisi.
+func (s SettlementService) ResubmitSettlement(id, userID uuid.UUID, req dto.CreateSettlementRequest) (*dto.SettlementResponse, error) {
+	actualCash, denominations, err := countSettlementCash(req)
+	if err != nil {
+		return nil, err
+	}
+	note := strings.TrimSpace(req.Note)
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		settlement, err := lockSettlement(tx, id)
+		if err != nil {
+			return err
+		}
+		if settlement.UserID != userID {
+			return ErrSettlementNotOwner
+		}
+		if settlement.Status != SettlementStatusRejected {
+			return ErrSettlementNotRejected
+		}
+		discrepancy := actualCash - settlement.ExpectedCash
+		if err := checkSettlementNote(discrepancy, note); err != nil {
+			return err
+		}
+
+		if err := tx.Where("settlement_id = ?", id).Delete(&settlement_model.SettlementDenomination{}).Error; err != nil {
+			return ErrDatabaseError
+		}
+		for i := range denominations {
+			denominations[i].SettlementID = id
+		}
+		if len(denominations) > 0 {
+			if err := tx.Create(&denominations).Error; err != nil {
+				return ErrDatabaseError
+			}
+		}
+
+		settlement.Revision++
+		if err := tx.Model(&settlement_model.Settlement{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"actual_cash":         actualCash,
+			"discrepancy":         discrepancy,
+			"note":                note,
+			"status":              SettlementStatusSubmitted,
+			"revision":            settlement.Revision,
+			"reviewed_by_user_id": nil,
+			"reviewed_at":         nil,
+			"review_note":         "",
+		}).Error; err != nil {
+			return ErrDatabaseError
+		}
+
+		settlement.ActualCash = actualCash
+		settlement.Discrepancy = discrepancy
+		settlement.Note = note
+		settlement.Denominations = denominations
+		return createSettlementRevision(tx, settlement, userID)
+	})
 	if err != nil {
 		return nil, err
 	}
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	endOfDay := startOfDay.AddDate(0, 0, 1)
+	return s.GetSettlementDetail(id, nil)
+}
+
+// ReviewSettlement admin menyetujui / menolak settlement yang berstatus submitted. Menolak wajib disertai catatan;
+// keputusan juga dicatat pada revisi yang sedang diajukan.
+func (s SettlementService) ReviewSettlement(id, adminID uuid.UUID, req dto.ReviewSettlementRequest) (*dto.SettlementResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	status := SettlementStatusApproved
+	if req.Action == "reject" {
+		status = SettlementStatusRejected
+		if note == "" {
+			return nil, ErrReviewNoteRequired
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		settlement, err := lockSettlement(tx, id)
+		if err != nil {
+			return err
+		}
+		if settlement.Status != SettlementStatusSubmitted {
+			return ErrSettlementNotSubmitted
+		}
 
-	var userIDs []uuid.UUID
-	err = config.DB.Model(&transaction_model.Transaction{}).
-		Distinct("closed_by_user_id").
-		Where("created_at >= ? AND created_at < ? AND order_status = ? AND payment_status = ? AND closed_by_user_id IS NOT NULL",
-			startOfDay, endOfDay, "completed", "paid").
-		Pluck("closed_by_user_id", &userIDs).Error
+		review := map[string]interface{}{
+			"status":              status,
+			"reviewed_by_user_id": adminID,
+			"reviewed_at":         time.Now(),
+			"review_note":         note,
+		}
+		if err := tx.Model(&settlement_model.Settlement{}).Where("id = ?", id).Updates(review).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if err := tx.Model(&settlement_model.SettlementRevision{}).
+			Where("settlement_id = ? AND revision = ?", id, settlement.Revision).
+			Updates(review).Error; err != nil {
+			return ErrDatabaseError
+		}
+		return nil
+	})
 	if err != nil {
+		return nil, err
+	}
+	return s.GetSettlementDetail(id, nil)
+}
+
+// GetSettlementDetail settlement beserta rincian pecahan dan riwayat revisi. ownerID != nil membatasi ke settlement milik kasir tersebut.
+func (s SettlementService) GetSettlementDetail(id uuid.UUID, ownerID *uuid.UUID) (*dto.SettlementResponse, error) {
+	var settlement settlement_model.Settlement
+	if err := config.DB.Preload("Denominations", func(db *gorm.DB) *gorm.DB {
+		return db.Order("denomination DESC")
+	}).First(&settlement, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSettlementNotFound
+		}
 		return nil, ErrDatabaseError
 	}
+	if ownerID != nil && settlement.UserID != *ownerID {
+		return nil, ErrSettlementNotOwner
+	}
 
-	items := make([]dto.SettlementStatusItem, 0, len(userIDs))
-	for _, uid := range userIDs {
-		expected, _ := expectedCashFromTransactionsByUser(startOfDay, endOfDay, uid)
-		settlement, _ := s.GetSettlementByDateAndUser(dateStr, uid)
-		var name string
-		var u user_model.User
-		if err := config.DB.Select("name").First(&u, "id = ?", uid).Error; err == nil {
-			name = u.Name
-		} else {
+	var revisions []settlement_model.SettlementRevision
+	if err := config.DB.Where("settlement_id = ?", id).Order("revision ASC").Find(&revisions).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+
+	res := toSettlementResponse(&settlement)
+	res.Revisions = make([]dto.SettlementRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		denominations := make([]dto.DenominationResponse, 0, len(r.Denominations))
+		for _, d := range r.Denominations {
+			denominations = append(denominations, dto.DenominationResponse{
+				Denomination: d.Denomination,
+				Quantity:     d.Quantity,
+				Subtotal:     d.Subtotal,
+			})
+		}
+		item := dto.SettlementRevisionResponse{
+			Revision:          r.Revision,
+			ExpectedCash:      r.ExpectedCash,
+			ActualCash:        r.ActualCash,
+			Discrepancy:       r.Discrepancy,
+			Denominations:     denominations,
+			Note:              r.Note,
+			SubmittedByUserID: r.SubmittedByUserID.String(),
+			Status:            r.Status,
+			ReviewNote:        r.ReviewNote,
+			CreatedAt:         config.StoreCalendar.Format(r.CreatedAt),
+		}
+		if r.ReviewedByUserID != nil {
+			reviewer := r.ReviewedByUserID.String()
+			item.ReviewedByUserID = &reviewer
+		}
+		if r.ReviewedAt != nil {
+			reviewedAt := config.StoreCalendar.Format(*r.ReviewedAt)
+			item.ReviewedAt = &reviewedAt
+		}
+		res.Revisions = append(res.Revisions, item)
+	}
+	return res, nil
+}
+
+// SettlementDiscrepancySortColumns kolom yang boleh dipakai untuk sort_by pada GET /settlement/discrepancies
+var SettlementDiscrepancySortColumns = map[string]string{
+	"date":        "date",
+	"discrepancy": "discrepancy",
+	"created_at":  "created_at",
+}
+
+// GetUnresolvedDiscrepancies settlement yang belum selesai: submitted dengan selisih, atau rejected (berapa pun
+// selisihnya, karena kasir wajib menghitung ulang)
+// lintas tanggal, untuk admin.
+func (s SettlementService) GetUnresolvedDiscrepancies(filter dto.SettlementDiscrepancyFilter, page utils.PageQuery) ([]dto.SettlementDiscrepancyItem, int64, error) {
+	q := config.DB.Model(&settlement_model.Settlement{}).
+		Where("(status = ? AND discrepancy <> 0) OR status = ?", SettlementStatusSubmitted, SettlementStatusRejected)
+	if filter.DateFrom != "" {
+		from, err := time.Parse("2006-01-02", filter.DateFrom)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("date >= ?", from)
+	}
+	if filter.DateTo != "" {
+		to, err := time.Parse("2006-01-02", filter.DateTo)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("date <= ?", to)
+	}
+
+	var settlements []settlement_model.Settlement
+	total, err := utils.Paginate(q, page, &settlements, "Denominations")
+	if err != nil {
+		return nil, 0, ErrDatabaseError
+	}
+
+	names := make(map[uuid.UUID]string)
+	items := make([]dto.SettlementDiscrepancyItem, 0, len(settlements))
+	for i := range settlements {
+		uid := settlements[i].UserID
+		name, ok := names[uid]
+		if !ok {
 			name = "-"
+			var u user_model.User
+			if err := config.DB.Select("name").First(&u, "id = ?", uid).Error; err == nil {
+				name = u.Name
+			}
+			names[uid] = name
 		}
-		items = append(items, dto.SettlementStatusItem{
-			UserID:       uid.String(),
-			UserName:     name,
-			ExpectedCash: expected,
-			Settlement:   settlement,
+		items = append(items, dto.SettlementDiscrepancyItem{
+			UserName:   name,
+			Settlement: *toSettlementResponse(&settlements[i]),
 		})
 	}
-
-	return &dto.GetSettlementStatusByDateResponse{
-		Date:  dateStr,
-		Items: items,
-	}, nil
+	return items, total, nil
 }
 
 func toSettlementResponse(s *settlement_model.Settlement) *dto.SettlementResponse {
 	dateStr := s.Date.Format("2006-01-02")
-	return &dto.SettlementResponse{
-		ID:           s.ID.String(),
-		Date:         dateStr,
-		UserID:       s.UserID.String(),
-		ExpectedCash: s.ExpectedCash,
-		ActualCash:   s.ActualCash,
-		Discrepancy:  s.Discrepancy,
-		CreatedAt:    s.CreatedAt.Format(time.RFC3339),
+	denominations := make([]dto.DenominationResponse, 0, len(s.Denominations))
+	for _, d := range s.Denominations {
+		denominations = append(denominations, dto.DenominationResponse{
+			Denomination: d.Denomination,
+			Quantity:     d.Quantity,
+			Subtotal:     d.Subtotal,
+		})
+	}
+	res := &dto.SettlementResponse{
+		ID:            s.ID.String(),
+		Date:          dateStr,
+		UserID:        s.UserID.String(),
+		OpeningFloat:  s.OpeningFloat,
+		ExpectedCash:  s.ExpectedCash,
+		ActualCash:    s.ActualCash,
+		Discrepancy:   s.Discrepancy,
+		TotalTendered: s.TotalTendered,
+		TotalChange:   s.TotalChange,
+		CashRefunds:   s.CashRefunds,
+		PayIns:        s.PayIns,
+		PayOuts:       s.PayOuts,
+		Denominations: denominations,
+		Note:          s.Note,
+		Status:        s.Status,
+		Revision:      s.Revision,
+		ReviewNote:    s.ReviewNote,
+		CreatedAt:     config.StoreCalendar.Format(s.CreatedAt),
+	}
+	if s.ShiftID != nil {
+		shiftID := s.ShiftID.String()
+		res.ShiftID = &shiftID
+	}
+	if s.ReviewedByUserID != nil {
+		reviewer := s.ReviewedByUserID.String()
+		res.ReviewedByUserID = &reviewer
+	}
+	if s.ReviewedAt != nil {
+		reviewedAt := config.StoreCalendar.Format(*s.ReviewedAt)
+		res.ReviewedAt = &reviewedAt
 	}
+	return res
 }
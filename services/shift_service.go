@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	settlement_model "pos-go/models/settlement_model"
+	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Status shift kasir
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+// Jenis kas masuk / keluar di tengah shift
+const (
+	CashMovementPayIn  = "pay_in"
+	CashMovementPayOut = "pay_out"
+)
+
+// Sentinel errors
+var (
+	ErrShiftAlreadyOpen = errors.New("Masih ada shift yang terbuka, tutup shift tersebut terlebih dahulu")
+	ErrNoOpenShift      = errors.New("Tidak ada shift yang sedang terbuka, buka shift terlebih dahulu")
+)
+
+type ShiftService struct{}
+
+func NewShiftService() ShiftService {
+	return ShiftService{}
+}
+
+// findOpenShift shift open milik user. lock = true mengambil row lock (dipakai saat tutup shift / catat pay-in pay-out).
+func findOpenShift(db *gorm.DB, userID uuid.UUID, lock bool) (settlement_model.Shift, error) {
+	q := db.Where("user_id = ? AND status = ?", userID, ShiftStatusOpen)
+	if lock {
+		q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var shift settlement_model.Shift
+	if err := q.First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settlement_model.Shift{}, ErrNoOpenShift
+		}
+		return settlement_model.Shift{}, ErrDatabaseError
+	}
+	return shift, nil
+}
+
+// shiftCashSummary merekap uang tunai shift dalam jendela [opened_at, closed_at) — atau sampai sekarang jika masih open:
+// tender tunai yang diterima kasir tersebut (uang diterima & kembalian), refund tunai atas tender kasir tersebut,
+// serta pay-in / pay-out shift. Status pesanan tidak dilihat: yang dihitung uang yang benar-benar masuk laci.
+func shiftCashSummary(db *gorm.DB, shift settlement_model.Shift) (dto.ShiftCashSummary, error) {
+	end := time.Now()
+	if shift.ClosedAt != nil {
+		end = *shift.ClosedAt
+	}
+
+	var sales struct {
+		Tendered utils.Money
+		Change   utils.Money
+	}
+	if err := db.Table("payments").
+		Select("COALESCE(SUM(amount_tendered), 0) AS tendered, COALESCE(SUM(change_amount), 0) AS change").
+		Where("method = ? AND status = ? AND cashier_user_id = ? AND paid_at >= ? AND paid_at < ?",
+			"cash", TenderStatusPaid, shift.UserID, shift.OpenedAt, end).
+		Scan(&sales).Error; err != nil {
+		return dto.ShiftCashSummary{}, ErrDatabaseError
+	}
+
+	// Refund tunai dibayarkan dari laci kasir yang menerima tender-nya (transaksi lama: kasir yang menutup transaksi)
+	var refunds utils.Money
+	if err := db.Table("refunds").
+		Select("COALESCE(SUM(refunds.amount), 0)").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Joins("LEFT JOIN payments ON payments.id = refunds.payment_id").
+		Where("refunds.payment_method = ? AND refunds.status = ? AND refunds.deleted_at IS NULL", "cash", "succeeded").
+		Where("refunds.created_at >= ? AND refunds.created_at < ?", shift.OpenedAt, end).
+		Where("COALESCE(payments.cashier_user_id, transactions.closed_by_user_id) = ?", shift.UserID).
+		Scan(&refunds).Error; err != nil {
+		return dto.ShiftCashSummary{}, ErrDatabaseError
+	}
+
+	var movements struct {
+		PayIns  utils.Money
+		PayOuts utils.Money
+	}
+	if err := db.Model(&settlement_model.ShiftCashMovement{}).
+		Select("COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS pay_ins, COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE 0 END), 0) AS pay_outs",
+			CashMovementPayIn, CashMovementPayOut).
+		Where("shift_id = ?", shift.ID).
+		Scan(&movements).Error; err != nil {
+		return dto.ShiftCashSummary{}, ErrDatabaseError
+	}
+
+	summary := dto.ShiftCashSummary{
+		OpeningFloat:  shift.OpeningFloat,
+		TotalTendered: sales.Tendered,
+		TotalChange:   sales.Change,
+		CashSales:     sales.Tendered - sales.Change,
+		CashRefunds:   refunds,
+		PayIns:        movements.PayIns,
+		PayOuts:       movements.PayOuts,
+	}
+	summary.ExpectedCash = summary.OpeningFloat + summary.CashSales - summary.CashRefunds + summary.PayIns - summary.PayOuts
+	return summary, nil
+}
+
+// OpenShift membuka shift baru untuk kasir dengan modal awal. Gagal jika kasir masih punya shift open
+// (dijaga juga oleh unique index parsial idx_shift_user_open).
+func (s ShiftService) OpenShift(userID uuid.UUID, req dto.OpenShiftRequest) (*dto.ShiftResponse, error) {
+	if _, err := findOpenShift(config.DB, userID, false); err == nil {
+		return nil, ErrShiftAlreadyOpen
+	} else if !errors.Is(err, ErrNoOpenShift) {
+		return nil, err
+	}
+
+	shift := settlement_model.Shift{
+		UserID:       userID,
+		Status:       ShiftStatusOpen,
+		OpeningFloat: req.OpeningFloat,
+		OpenedAt:     time.Now(),
+		Note:         strings.TrimSpace(req.Note),
+	}
+	if err := config.DB.Create(&shift).Error; err != nil {
+		if strings.Contains(err.Error(), "idx_shift_user_open") {
+			return nil, ErrShiftAlreadyOpen
+		}
+		return nil, ErrDatabaseError
+	}
+	return s.shiftResponse(shift, nil)
+}
+
+// GetCurrentShift shift open milik user beserta rekap tunai sampai saat ini
+func (s ShiftService) GetCurrentShift(userID uuid.UUID) (*dto.ShiftResponse, error) {
+	shift, err := findOpenShift(config.DB.Preload("CashMovements", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}), userID, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.shiftResponse(shift, nil)
+}
+
+// AddCashMovement mencatat pay-in / pay-out pada shift open milik user
+func (s ShiftService) AddCashMovement(userID uuid.UUID, req dto.ShiftCashMovementRequest) (*dto.ShiftResponse, error) {
+	var shift settlement_model.Shift
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		shift, err = findOpenShift(tx, userID, true)
+		if err != nil {
+			return err
+		}
+		movement := settlement_model.ShiftCashMovement{
+			ShiftID: shift.ID,
+			Type:    req.Type,
+			Amount:  req.Amount,
+			Reason:  strings.TrimSpace(req.Reason),
+			UserID:  userID,
+		}
+		if err := tx.Create(&movement).Error; err != nil {
+			return ErrDatabaseError
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCurrentShift(userID)
+}
+
+// shiftResponse menyusun response shift: rekap tunai, pay-in / pay-out, nama kasir, dan settlement (jika sudah ditutup)
+func (s ShiftService) shiftResponse(shift settlement_model.Shift, settlement *settlement_model.Settlement) (*dto.ShiftResponse, error) {
+	summary, err := shiftCashSummary(config.DB, shift)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "-"
+	var u user_model.User
+	if err := config.DB.Select("name").First(&u, "id = ?", shift.UserID).Error; err == nil {
+		name = u.Name
+	}
+
+	movements := make([]dto.ShiftCashMovementResponse, 0, len(shift.CashMovements))
+	for _, m := range shift.CashMovements {
+		movements = append(movements, dto.ShiftCashMovementResponse{
+			ID:        m.ID.String(),
+			Type:      m.Type,
+			Amount:    m.Amount,
+			Reason:    m.Reason,
+			UserID:    m.UserID.String(),
+			CreatedAt: config.StoreCalendar.Format(m.CreatedAt),
+		})
+	}
+
+	res := &dto.ShiftResponse{
+		ID:            shift.ID.String(),
+		UserID:        shift.UserID.String(),
+		UserName:      name,
+		Status:        shift.Status,
+		OpenedAt:      config.StoreCalendar.Format(shift.OpenedAt),
+		Note:          shift.Note,
+		Summary:       summary,
+		CashMovements: movements,
+	}
+	if shift.ClosedAt != nil {
+		closedAt := config.StoreCalendar.Format(*shift.ClosedAt)
+		res.ClosedAt = &closedAt
+	}
+	if settlement != nil {
+		res.Settlement = toSettlementResponse(settlement)
+	}
+	return res, nil
+}
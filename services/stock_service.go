@@ -0,0 +1,246 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	menu_model "pos-go/models/menu_model"
+	stock_model "pos-go/models/stock_model"
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Jenis stock movement
+const (
+	StockMovementSale       = "sale"
+	StockMovementSaleVoid   = "sale_void" // stok dikembalikan karena pesanan batal / kadaluarsa
+	StockMovementWaste      = "waste"
+	StockMovementRestock    = "restock"
+	StockMovementCorrection = "correction"
+)
+
+// Sentinel errors
+var (
+	ErrInsufficientStock      = errors.New("Stok menu tidak mencukupi")
+	ErrStockNotTracked        = errors.New("Stok menu ini tidak dilacak")
+	ErrInvalidStockAdjustment = errors.New("Jumlah penyesuaian stok tidak valid")
+	ErrAdjustStockFailed      = errors.New("Gagal menyesuaikan stok")
+	ErrGetStockMovementFailed = errors.New("Gagal mengambil riwayat stok")
+)
+
+type StockService interface {
+	AdjustStock(menuID string, input dto.StockAdjustmentDTO, userID uuid.UUID) (stock_model.StockMovement, error)
+	GetStockMovements(menuID string, filter dto.StockMovementListFilter, page utils.PageQuery) ([]stock_model.StockMovement, int64, error)
+	GetLowStockMenus() ([]menu_model.Menu, error)
+}
+
+type stockService struct{}
+
+func NewStockService() StockService {
+	return &stockService{}
+}
+
+// StockMovementSortColumns kolom yang boleh dipakai untuk sort_by pada GET /menu/:id/stock-movements
+var StockMovementSortColumns = map[string]string{
+	"created_at": "created_at",
+	"quantity":   "quantity",
+}
+
+// applyStockChange mengubah stok menu secara atomik (satu UPDATE dengan syarat stok tidak negatif) lalu mencatat movement.
+// Stok <= 0 membuat menu tidak tersedia (sold_out = true); stok kembali > 0 membuat menu yang sold_out tersedia lagi.
+// Menu yang dinonaktifkan manual oleh admin tidak diaktifkan otomatis.
+// Mengembalikan ErrInsufficientStock jika stok kurang, ErrStockNotTracked jika menu tidak melacak stok.
+func applyStockChange(tx *gorm.DB, menuID uuid.UUID, delta int, movement stock_model.StockMovement) (stock_model.StockMovement, error) {
+	var menu menu_model.Menu
+	res := tx.Model(&menu).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}, {Name: "stock"}}}).
+		Where("id = ? AND track_stock = ? AND stock + ? >= 0", menuID, true, delta).
+		Updates(map[string]interface{}{
+			"stock":        gorm.Expr("stock + ?", delta),
+			"is_available": gorm.Expr("CASE WHEN stock + ? <= 0 THEN false ELSE (is_available OR sold_out) END", delta),
+			"sold_out":     gorm.Expr("CASE WHEN stock + ? <= 0 THEN (sold_out OR is_available) ELSE false END", delta),
+		})
+	if res.Error != nil {
+		return stock_model.StockMovement{}, ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		if delta < 0 {
+			var current menu_model.Menu
+			if err := tx.Select("name", "track_stock").First(&current, "id = ?", menuID).Error; err == nil && current.TrackStock {
+				return stock_model.StockMovement{}, fmt.Errorf("%w: %s", ErrInsufficientStock, current.Name)
+			}
+		}
+		return stock_model.StockMovement{}, ErrStockNotTracked
+	}
+
+	movement.MenuID = menuID
+	movement.MenuName = menu.Name
+	movement.Quantity = delta
+	movement.StockAfter = menu.Stock
+	if err := tx.Create(&movement).Error; err != nil {
+		return stock_model.StockMovement{}, ErrDatabaseError
+	}
+	return movement, nil
+}
+
+// deductStockForSale mengurangi stok untuk item transaksi (hanya menu dengan track_stock). Dipanggil di dalam DB transaction CreateTransaction.
+func deductStockForSale(tx *gorm.DB, transactionID uuid.UUID, menuID uuid.UUID, quantity int) error {
+	_, err := applyStockChange(tx, menuID, -quantity, stock_model.StockMovement{
+		Type:          StockMovementSale,
+		TransactionID: &transactionID,
+	})
+	return err
+}
+
+// restoreStockForTransaction mengembalikan stok yang terjual oleh transaksi (pesanan batal / kadaluarsa).
+// Jumlah diambil dari stock_movements (sale - sale_void) sehingga aman dipanggil lebih dari sekali.
+// Menu yang sudah tidak melacak stok dilewati.
+func restoreStockForTransaction(tx *gorm.DB, transactionID uuid.UUID) error {
+	var rows []struct {
+		MenuID   uuid.UUID
+		Quantity int
+	}
+	if err := tx.Model(&stock_model.StockMovement{}).
+		Select("menu_id, SUM(quantity) AS quantity").
+		Where("transaction_id = ? AND type IN ?", transactionID, []string{StockMovementSale, StockMovementSaleVoid}).
+		Group("menu_id").
+		Having("SUM(quantity) < 0").
+		Scan(&rows).Error; err != nil {
+		return ErrDatabaseError
+	}
+
+	for _, row := range rows {
+		_, err := applyStockChange(tx, row.MenuID, -row.Quantity, stock_model.StockMovement{
+			Type:          StockMovementSaleVoid,
+			TransactionID: &transactionID,
+		})
+		if err != nil && !errors.Is(err, ErrStockNotTracked) {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreStockForItem mengembalikan stok satu item yang dihapus dari open bill. Jumlah dibatasi stok yang memang terjual
+// untuk menu tersebut di transaksi ini (sale - sale_void), sehingga menu yang baru dilacak setelah dipesan tidak bertambah stoknya.
+func restoreStockForItem(tx *gorm.DB, transactionID, menuID uuid.UUID, quantity int) error {
+	var sold int
+	if err := tx.Model(&stock_model.StockMovement{}).
+		Select("COALESCE(-SUM(quantity), 0)").
+		Where("transaction_id = ? AND menu_id = ? AND type IN ?", transactionID, menuID, []string{StockMovementSale, StockMovementSaleVoid}).
+		Scan(&sold).Error; err != nil {
+		return ErrDatabaseError
+	}
+	if sold < quantity {
+		quantity = sold
+	}
+	if quantity <= 0 {
+		return nil
+	}
+
+	_, err := applyStockChange(tx, menuID, quantity, stock_model.StockMovement{
+		Type:          StockMovementSaleVoid,
+		TransactionID: &transactionID,
+	})
+	if err != nil && !errors.Is(err, ErrStockNotTracked) {
+		return err
+	}
+	return nil
+}
+
+// AdjustStock penyesuaian stok manual oleh admin (waste / restock / correction), tercatat sebagai movement.
+func (s *stockService) AdjustStock(menuID string, input dto.StockAdjustmentDTO, userID uuid.UUID) (stock_model.StockMovement, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return stock_model.StockMovement{}, ErrMenuNotFound
+	}
+
+	var movement stock_model.StockMovement
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var menu menu_model.Menu
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&menu, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMenuNotFound
+			}
+			return ErrAdjustStockFailed
+		}
+		if !menu.TrackStock {
+			return ErrStockNotTracked
+		}
+
+		var delta int
+		switch input.Type {
+		case StockMovementWaste:
+			delta = -input.Quantity
+		case StockMovementRestock:
+			delta = input.Quantity
+		case StockMovementCorrection:
+			delta = input.Quantity - menu.Stock
+		}
+		if input.Type != StockMovementCorrection && input.Quantity <= 0 {
+			return ErrInvalidStockAdjustment
+		}
+
+		movement, err = applyStockChange(tx, id, delta, stock_model.StockMovement{
+			Type:   input.Type,
+			UserID: &userID,
+			Note:   input.Note,
+		})
+		return err
+	})
+	if err != nil {
+		return stock_model.StockMovement{}, err
+	}
+
+	return movement, nil
+}
+
+// GetStockMovements riwayat perubahan stok satu menu (dengan filter + paginasi)
+func (s *stockService) GetStockMovements(menuID string, filter dto.StockMovementListFilter, page utils.PageQuery) ([]stock_model.StockMovement, int64, error) {
+	id, err := uuid.Parse(menuID)
+	if err != nil {
+		return nil, 0, ErrMenuNotFound
+	}
+
+	q := config.DB.Model(&stock_model.StockMovement{}).Where("menu_id = ?", id)
+	if filter.Type != "" {
+		q = q.Where("type = ?", filter.Type)
+	}
+	if filter.DateFrom != "" {
+		from, _, err := config.StoreCalendar.ParseDay(filter.DateFrom)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("created_at >= ?", from)
+	}
+	if filter.DateTo != "" {
+		_, to, err := config.StoreCalendar.ParseDay(filter.DateTo)
+		if err != nil {
+			return nil, 0, err
+		}
+		q = q.Where("created_at < ?", to)
+	}
+
+	var movements []stock_model.StockMovement
+	total, err := utils.Paginate(q, page, &movements)
+	if err != nil {
+		return nil, 0, ErrGetStockMovementFailed
+	}
+	return movements, total, nil
+}
+
+// GetLowStockMenus menu yang melacak stok dan stoknya <= low_stock_threshold (stok paling sedikit di atas)
+func (s *stockService) GetLowStockMenus() ([]menu_model.Menu, error) {
+	var menus []menu_model.Menu
+	if err := config.DB.Preload("Category").
+		Where("track_stock = ? AND stock <= low_stock_threshold", true).
+		Order("stock ASC, name ASC").
+		Find(&menus).Error; err != nil {
+		return nil, ErrGetMenusFailed
+	}
+	return menus, nil
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pos-go/config"
+	"pos-go/dto"
+	table_model "pos-go/models/table_model"
+	transaction_model "pos-go/models/transaction_model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status meja
+const (
+	TableStatusFree     = "free"
+	TableStatusOccupied = "occupied"
+	TableStatusReserved = "reserved"
+	TableStatusCleaning = "cleaning"
+)
+
+// Sentinel errors
+var (
+	ErrTableNotFound     = errors.New("Meja tidak ditemukan")
+	ErrTableNumberExists = errors.New("Nomor meja sudah digunakan")
+	ErrTableNotAvailable = errors.New("Meja sedang tidak tersedia")
+	ErrTableOccupied     = errors.New("Meja sedang terisi pesanan aktif")
+	ErrCreateTableFailed = errors.New("Gagal membuat meja")
+	ErrUpdateTableFailed = errors.New("Gagal mengupdate meja")
+	ErrDeleteTableFailed = errors.New("Gagal menghapus meja")
+	ErrGetTablesFailed   = errors.New("Gagal mengambil daftar meja")
+)
+
+type TableService interface {
+	CreateTable(input dto.CreateTableDTO) (table_model.Table, error)
+	GetAllTables() ([]table_model.Table, error)
+	UpdateTable(tableID string, input dto.UpdateTableDTO) (table_model.Table, error)
+	DeleteTable(tableID string) error
+	SetTableStatus(tableID string, status string) (table_model.Table, error)
+	GetFloor() (*dto.FloorResponse, error)
+}
+
+type tableService struct{}
+
+func NewTableService() TableService {
+	return &tableService{}
+}
+
+// occupyTable menandai meja dipakai oleh pesanan dine-in (meja free atau reserved). Dipanggil di dalam DB transaction
+// CreateTransaction sehingga dua kasir tidak bisa menempatkan pesanan di meja yang sama.
+// Jika toko belum mengatur meja sama sekali, nomor meja hanya dicatat di transaksi (tanpa status meja);
+// begitu ada satu meja, nomor yang tidak terdaftar ditolak dengan ErrTableNotFound.
+func occupyTable(tx *gorm.DB, number int, transactionID uuid.UUID) error {
+	now := time.Now()
+	res := tx.Model(&table_model.Table{}).
+		Where("number = ? AND status IN ?", number, []string{TableStatusFree, TableStatusReserved}).
+		Updates(map[string]interface{}{
+			"status":                 TableStatusOccupied,
+			"current_transaction_id": transactionID,
+			"occupied_at":            now,
+		})
+	if res.Error != nil {
+		return ErrDatabaseError
+	}
+	if res.RowsAffected == 0 {
+		var count int64
+		if err := tx.Model(&table_model.Table{}).Where("number = ?", number).Count(&count).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if count == 0 {
+			var configured int64
+			if err := tx.Model(&table_model.Table{}).Count(&configured).Error; err != nil {
+				return ErrDatabaseError
+			}
+			if configured == 0 {
+				return nil
+			}
+			return fmt.Errorf("%w: nomor %d", ErrTableNotFound, number)
+		}
+		return fmt.Errorf("%w: meja %d", ErrTableNotAvailable, number)
+	}
+	return nil
+}
+
+// releaseTable mengosongkan meja yang ditempati transaksi (pesanan selesai / batal). Tidak error jika tidak ada meja.
+func releaseTable(tx *gorm.DB, transactionID uuid.UUID) error {
+	return tx.Model(&table_model.Table{}).
+		Where("current_transaction_id = ?", transactionID).
+		Updates(map[string]interface{}{
+			"status":                 TableStatusFree,
+			"current_transaction_id": nil,
+			"occupied_at":            nil,
+		}).Error
+}
+
+// findTable mengambil meja berdasarkan ID (string)
+func findTable(db *gorm.DB, tableID string) (table_model.Table, error) {
+	id, err := uuid.Parse(tableID)
+	if err != nil {
+		return table_model.Table{}, ErrTableNotFound
+	}
+	var table table_model.Table
+	if err := db.First(&table, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return table_model.Table{}, ErrTableNotFound
+		}
+		return table_model.Table{}, ErrDatabaseError
+	}
+	return table, nil
+}
+
+func (s *tableService) CreateTable(input dto.CreateTableDTO) (table_model.Table, error) {
+	var existing table_model.Table
+	if err := config.DB.Where("number = ?", input.Number).First(&existing).Error; err == nil {
+		return table_model.Table{}, ErrTableNumberExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return table_model.Table{}, ErrCreateTableFailed
+	}
+
+	table := table_model.Table{
+		Number:   input.Number,
+		Area:     input.Area,
+		Capacity: input.Capacity,
+		Status:   TableStatusFree,
+	}
+	if err := config.DB.Create(&table).Error; err != nil {
+		return table_model.Table{}, ErrCreateTableFailed
+	}
+	return table, nil
+}
+
+func (s *tableService) GetAllTables() ([]table_model.Table, error) {
+	var tables []table_model.Table
+	if err := config.DB.Order("number ASC").Find(&tables).Error; err != nil {
+		return nil, ErrGetTablesFailed
+	}
+	return tables, nil
+}
+
+// UpdateTable mengupdate nomor / area / kapasitas meja (partial update). Nomor tidak bisa diubah saat meja terisi.
+func (s *tableService) UpdateTable(tableID string, input dto.UpdateTableDTO) (table_model.Table, error) {
+	table, err := findTable(config.DB, tableID)
+	if err != nil {
+		if errors.Is(err, ErrTableNotFound) {
+			return table_model.Table{}, err
+		}
+		return table_model.Table{}, ErrUpdateTableFailed
+	}
+
+	if input.Number > 0 && input.Number != table.Number {
+		if table.Status == TableStatusOccupied {
+			return table_model.Table{}, ErrTableOccupied
+		}
+		var existing table_model.Table
+		if err := config.DB.Where("number = ? AND id != ?", input.Number, table.ID).First(&existing).Error; err == nil {
+			return table_model.Table{}, ErrTableNumberExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return table_model.Table{}, ErrUpdateTableFailed
+		}
+		table.Number = input.Number
+	}
+	if input.Area != nil {
+		table.Area = *input.Area
+	}
+	if input.Capacity != nil {
+		table.Capacity = *input.Capacity
+	}
+
+	// Status tidak ikut disimpan agar tidak menimpa perubahan dari transaksi yang berjalan bersamaan
+	if err := config.DB.Model(&table).Select("number", "area", "capacity").Updates(&table).Error; err != nil {
+		return table_model.Table{}, ErrUpdateTableFailed
+	}
+	return table, nil
+}
+
+// DeleteTable menghapus meja. Ditolak jika meja sedang terisi; transaksi lama tetap menyimpan nomor meja.
+func (s *tableService) DeleteTable(tableID string) error {
+	table, err := findTable(config.DB, tableID)
+	if err != nil {
+		if errors.Is(err, ErrTableNotFound) {
+			return err
+		}
+		return ErrDeleteTableFailed
+	}
+	if table.Status == TableStatusOccupied {
+		return ErrTableOccupied
+	}
+	if err := config.DB.Delete(&table).Error; err != nil {
+		return ErrDeleteTableFailed
+	}
+	return nil
+}
+
+// SetTableStatus mengubah status meja secara manual (free / reserved / cleaning). Meja yang terisi pesanan aktif
+// hanya berubah lewat pesanan (selesai / batal).
+func (s *tableService) SetTableStatus(tableID string, status string) (table_model.Table, error) {
+	table, err := findTable(config.DB, tableID)
+	if err != nil {
+		if errors.Is(err, ErrTableNotFound) {
+			return table_model.Table{}, err
+		}
+		return table_model.Table{}, ErrUpdateTableFailed
+	}
+
+	res := config.DB.Model(&table_model.Table{}).
+		Where("id = ? AND status <> ?", table.ID, TableStatusOccupied).
+		Update("status", status)
+	if res.Error != nil {
+		return table_model.Table{}, ErrUpdateTableFailed
+	}
+	if res.RowsAffected == 0 {
+		return table_model.Table{}, ErrTableOccupied
+	}
+	table.Status = status
+	return table, nil
+}
+
+// GetFloor status semua meja per area beserta ringkasan pesanan yang sedang menempati meja
+func (s *tableService) GetFloor() (*dto.FloorResponse, error) {
+	var tables []table_model.Table
+	if err := config.DB.Order("area ASC, number ASC").Find(&tables).Error; err != nil {
+		return nil, ErrGetTablesFailed
+	}
+
+	var transactionIDs []uuid.UUID
+	for _, t := range tables {
+		if t.CurrentTransactionID != nil {
+			transactionIDs = append(transactionIDs, *t.CurrentTransactionID)
+		}
+	}
+	transactions := make(map[uuid.UUID]transaction_model.Transaction)
+	if len(transactionIDs) > 0 {
+		var rows []transaction_model.Transaction
+		if err := config.DB.Where("id IN ?", transactionIDs).Find(&rows).Error; err != nil {
+			return nil, ErrGetTablesFailed
+		}
+		for _, t := range rows {
+			transactions[t.ID] = t
+		}
+	}
+
+	floor := &dto.FloorResponse{Summary: map[string]int{
+		TableStatusFree:     0,
+		TableStatusOccupied: 0,
+		TableStatusReserved: 0,
+		TableStatusCleaning: 0,
+	}}
+	areaIndex := make(map[string]int)
+	for _, t := range tables {
+		floor.Summary[t.Status]++
+		item := dto.FloorTableResponse{
+			ID:         t.ID,
+			Number:     t.Number,
+			Capacity:   t.Capacity,
+			Status:     t.Status,
+			OccupiedAt: t.OccupiedAt,
+		}
+		if t.CurrentTransactionID != nil {
+			if tr, ok := transactions[*t.CurrentTransactionID]; ok {
+				item.Transaction = &dto.FloorTransactionResponse{
+					ID:            tr.ID,
+					CustomerName:  tr.CustomerName,
+					OrderStatus:   tr.OrderStatus,
+					PaymentStatus: tr.PaymentStatus,
+					TotalAmount:   tr.TotalAmount,
+				}
+			}
+		}
+
+		i, ok := areaIndex[t.Area]
+		if !ok {
+			i = len(floor.Areas)
+			areaIndex[t.Area] = i
+			floor.Areas = append(floor.Areas, dto.FloorAreaResponse{Area: t.Area})
+		}
+		floor.Areas[i].Tables = append(floor.Areas[i].Tables, item)
+	}
+
+	return floor, nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	tax_model "pos-go/models/tax_model"
+	"pos-go/utils"
+
+	"github.com/google/uuid"
+)
+
+// Jenis aturan pada tax_model.TaxRule
+const (
+	TaxTypeTax           = "tax"
+	TaxTypeServiceCharge = "service_charge"
+)
+
+// TaxableLine satu baris pesanan yang akan dihitung pajaknya (Amount = harga * qty, sebelum diskon)
+type TaxableLine struct {
+	CategoryID uuid.UUID
+	Amount     utils.Money
+}
+
+// LineTaxResult hasil per baris: porsi diskon, service charge, dan pajak
+type LineTaxResult struct {
+	Discount      utils.Money
+	ServiceCharge utils.Money
+	Tax           utils.Money
+}
+
+// TaxBreakdownLine hasil per aturan pajak / service charge
+type TaxBreakdownLine struct {
+	RuleID        uuid.UUID
+	Name          string
+	Type          string
+	Rate          float64
+	IsInclusive   bool
+	TaxableAmount utils.Money
+	Amount        utils.Money
+}
+
+// TaxCalculation hasil perhitungan lengkap. Semua nominal dibulatkan ke rupiah per baris (half-up, lihat utils.Money),
+// sehingga total = jumlah baris (struk, laporan, dan item_details Midtrans memakai angka yang sama).
+type TaxCalculation struct {
+	Lines         []LineTaxResult
+	Breakdown     []TaxBreakdownLine
+	Subtotal      utils.Money // sum(Amount) - diskon
+	ServiceCharge utils.Money
+	Tax           utils.Money // total pajak (inklusif + eksklusif)
+	ExclusiveTax  utils.Money // pajak yang ditambahkan ke total
+	Total         utils.Money // Subtotal + ServiceCharge + ExclusiveTax
+}
+
+// CalculateTaxes menghitung diskon per baris, service charge, dan pajak dari aturan aktif.
+//
+// Urutan: diskon dibagi proporsional ke tiap baris -> service charge (eksklusif) atas baris setelah diskon ->
+// pajak atas (baris setelah diskon + service charge). Pajak inklusif diekstrak dari dasar pengenaan
+// (base * rate / (100 + rate)) dan tidak menambah total. Kategori yang dibebaskan oleh sebuah aturan
+// tidak dikenai aturan tersebut. Aturan dengan OrderType berbeda dari orderType dilewati.
+func CalculateTaxes(lines []TaxableLine, discount utils.Money, rules []tax_model.TaxRule, orderType string) TaxCalculation {
+	result := TaxCalculation{Lines: make([]LineTaxResult, len(lines))}
+
+	var gross utils.Money
+	weights := make([]utils.Money, len(lines))
+	for i, l := range lines {
+		gross += l.Amount
+		weights[i] = l.Amount
+	}
+
+	// 1. Bagi diskon proporsional (jumlah porsi = diskon, lihat utils.Allocate)
+	if discount > gross {
+		discount = gross
+	}
+	for i, d := range utils.Allocate(discount, weights) {
+		result.Lines[i].Discount = d
+	}
+	result.Subtotal = gross - discount
+
+	applies := func(rule tax_model.TaxRule, line TaxableLine) bool {
+		for _, c := range rule.ExemptCategories {
+			if c.ID == line.CategoryID {
+				return false
+			}
+		}
+		return true
+	}
+
+	// 2. Service charge, lalu 3. pajak
+	for _, ruleType := range []string{TaxTypeServiceCharge, TaxTypeTax} {
+		for _, rule := range rules {
+			if rule.Type != ruleType || !rule.IsActive {
+				continue
+			}
+			if rule.OrderType != "" && rule.OrderType != orderType {
+				continue
+			}
+
+			b := TaxBreakdownLine{
+				RuleID:      rule.ID,
+				Name:        rule.Name,
+				Type:        rule.Type,
+				Rate:        rule.Rate,
+				IsInclusive: rule.IsInclusive && rule.Type == TaxTypeTax,
+			}
+			for i, l := range lines {
+				if !applies(rule, l) {
+					continue
+				}
+				net := l.Amount - result.Lines[i].Discount
+				if rule.Type == TaxTypeServiceCharge {
+					amount := net.MulRate(rule.Rate)
+					result.Lines[i].ServiceCharge += amount
+					b.TaxableAmount += net
+					b.Amount += amount
+					continue
+				}
+
+				base := net + result.Lines[i].ServiceCharge
+				var amount utils.Money
+				if b.IsInclusive {
+					amount = base.ExtractRate(rule.Rate)
+				} else {
+					amount = base.MulRate(rule.Rate)
+				}
+				result.Lines[i].Tax += amount
+				b.TaxableAmount += base
+				b.Amount += amount
+			}
+
+			if b.Type == TaxTypeServiceCharge {
+				result.ServiceCharge += b.Amount
+			} else {
+				result.Tax += b.Amount
+				if !b.IsInclusive {
+					result.ExclusiveTax += b.Amount
+				}
+			}
+			result.Breakdown = append(result.Breakdown, b)
+		}
+	}
+
+	result.Total = result.Subtotal + result.ServiceCharge + result.ExclusiveTax
+	return result
+}
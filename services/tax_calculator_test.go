@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	category_model "pos-go/models/category_model"
+	tax_model "pos-go/models/tax_model"
+	transaction_model "pos-go/models/transaction_model"
+	"pos-go/utils"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// randomTaxRules 0-3 aturan pajak / service charge acak (tarif berpecahan, inklusif, dan kategori bebas pajak)
+func randomTaxRules(rng *rand.Rand, categories []uuid.UUID) []tax_model.TaxRule {
+	rates := []float64{5, 10, 11, 2.5, 12.3456, 0.0001}
+	rules := make([]tax_model.TaxRule, rng.Intn(4))
+	for i := range rules {
+		rules[i] = tax_model.TaxRule{
+			ID:          uuid.New(),
+			Name:        "Aturan",
+			Type:        TaxTypeTax,
+			Rate:        rates[rng.Intn(len(rates))],
+			IsInclusive: rng.Intn(2) == 0,
+			IsActive:    true,
+		}
+		if rng.Intn(3) == 0 {
+			rules[i].Type = TaxTypeServiceCharge
+		}
+		if rng.Intn(3) == 0 {
+			rules[i].ExemptCategories = []category_model.Category{{ID: categories[rng.Intn(len(categories))]}}
+		}
+	}
+	return rules
+}
+
+// TestCalculateTaxesItemSums jumlah per baris (diskon, service charge, pajak) selalu sama dengan total transaksi,
+// dan item_details Midtrans yang dibangun dari hasilnya selalu berjumlah persis gross_amount
+func TestCalculateTaxesItemSums(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	categories := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	for i := 0; i < 5000; i++ {
+		items := make([]transaction_model.TransactionItem, rng.Intn(6)+1)
+		lines := make([]TaxableLine, len(items))
+		var gross utils.Money
+		for j := range items {
+			price := utils.Money(rng.Int63n(200000) + 1)
+			modifier := utils.Money(rng.Int63n(3)) * 2500
+			qty := rng.Intn(5) + 1
+			items[j] = transaction_model.TransactionItem{
+				MenuID:        uuid.New(),
+				MenuName:      "Menu",
+				MenuPrice:     price,
+				ModifierPrice: modifier,
+				Quantity:      qty,
+				Subtotal:      (price + modifier).Mul(qty),
+			}
+			lines[j] = TaxableLine{CategoryID: categories[rng.Intn(len(categories))], Amount: items[j].Subtotal}
+			gross += items[j].Subtotal
+		}
+		var discount utils.Money
+		if rng.Intn(2) == 0 {
+			discount = utils.Money(rng.Int63n(int64(gross) + 1))
+		}
+		rules := randomTaxRules(rng, categories)
+
+		calc := CalculateTaxes(lines, discount, rules, "dine_in")
+
+		var lineDiscount, lineService, lineTax utils.Money
+		for _, l := range calc.Lines {
+			lineDiscount += l.Discount
+			lineService += l.ServiceCharge
+			lineTax += l.Tax
+		}
+		if lineDiscount != discount {
+			t.Fatalf("case %d: sum(diskon baris) = %d, want %d", i, lineDiscount, discount)
+		}
+		if lineService != calc.ServiceCharge || lineTax != calc.Tax {
+			t.Fatalf("case %d: sum(baris) service %d / pajak %d, want %d / %d", i, lineService, lineTax, calc.ServiceCharge, calc.Tax)
+		}
+		if calc.Subtotal != gross-discount || calc.Total != calc.Subtotal+calc.ServiceCharge+calc.ExclusiveTax {
+			t.Fatalf("case %d: subtotal %d / total %d tidak konsisten", i, calc.Subtotal, calc.Total)
+		}
+
+		taxes := make([]transaction_model.TransactionTax, 0, len(calc.Breakdown))
+		for _, b := range calc.Breakdown {
+			taxes = append(taxes, transaction_model.TransactionTax{Name: b.Name, Rate: b.Rate, IsInclusive: b.IsInclusive, Amount: b.Amount})
+		}
+		transaction := transaction_model.Transaction{
+			Items:       items,
+			Taxes:       taxes,
+			Discount:    discount,
+			TotalAmount: calc.Total,
+		}
+		details, err := snapItemDetails(transaction)
+		if err != nil {
+			t.Fatalf("case %d: snapItemDetails: %v", i, err)
+		}
+		var sum int64
+		for _, d := range details {
+			sum += d.Price * int64(d.Qty)
+		}
+		if sum != calc.Total.Int64() {
+			t.Fatalf("case %d: sum(item_details) = %d, want %d", i, sum, calc.Total)
+		}
+	}
+}
+
+func TestSnapItemDetailsRejectsMismatch(t *testing.T) {
+	transaction := transaction_model.Transaction{
+		Items: []transaction_model.TransactionItem{
+			{MenuID: uuid.New(), MenuName: "Kopi", MenuPrice: 15000, Quantity: 2, Subtotal: 30000},
+		},
+		TotalAmount: 30001,
+	}
+	if _, err := snapItemDetails(transaction); !errors.Is(err, ErrAmountMismatch) {
+		t.Fatalf("snapItemDetails error = %v, want ErrAmountMismatch", err)
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"pos-go/config"
+	"pos-go/dto"
+	category_model "pos-go/models/category_model"
+	tax_model "pos-go/models/tax_model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sentinel errors
+var (
+	ErrTaxRuleNotFound     = errors.New("Aturan pajak tidak ditemukan")
+	ErrCreateTaxRuleFailed = errors.New("Gagal membuat aturan pajak")
+	ErrUpdateTaxRuleFailed = errors.New("Gagal mengupdate aturan pajak")
+	ErrDeleteTaxRuleFailed = errors.New("Gagal menghapus aturan pajak")
+	ErrGetTaxRulesFailed   = errors.New("Gagal mengambil daftar aturan pajak")
+)
+
+type TaxService interface {
+	CreateTaxRule(input dto.CreateTaxRuleDTO) (tax_model.TaxRule, error)
+	UpdateTaxRule(ruleID string, input dto.UpdateTaxRuleDTO) (tax_model.TaxRule, error)
+	DeleteTaxRule(ruleID string) error
+	GetAllTaxRules() ([]tax_model.TaxRule, error)
+	GetActiveTaxRules() ([]tax_model.TaxRule, error)
+}
+
+type taxService struct{}
+
+func NewTaxService() TaxService {
+	return &taxService{}
+}
+
+// findCategories memastikan semua kategori pengecualian ada
+func findCategories(ids []string) ([]category_model.Category, error) {
+	categories := make([]category_model.Category, 0, len(ids))
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	if err := config.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	if len(categories) != len(ids) {
+		return nil, ErrCategoryNotFound
+	}
+	return categories, nil
+}
+
+// CreateTaxRule membuat aturan pajak / service charge baru
+func (s *taxService) CreateTaxRule(input dto.CreateTaxRuleDTO) (tax_model.TaxRule, error) {
+	categories, err := findCategories(input.ExemptCategoryIDs)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return tax_model.TaxRule{}, err
+		}
+		return tax_model.TaxRule{}, ErrCreateTaxRuleFailed
+	}
+
+	rule := tax_model.TaxRule{
+		Name:             input.Name,
+		Type:             input.Type,
+		Rate:             input.Rate,
+		IsInclusive:      input.IsInclusive && input.Type == TaxTypeTax,
+		OrderType:        input.OrderType,
+		IsActive:         input.IsActive,
+		ExemptCategories: categories,
+	}
+	if err := config.DB.Create(&rule).Error; err != nil {
+		return tax_model.TaxRule{}, ErrCreateTaxRuleFailed
+	}
+
+	return rule, nil
+}
+
+// UpdateTaxRule mengupdate aturan pajak (partial update)
+func (s *taxService) UpdateTaxRule(ruleID string, input dto.UpdateTaxRuleDTO) (tax_model.TaxRule, error) {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return tax_model.TaxRule{}, ErrTaxRuleNotFound
+	}
+
+	var rule tax_model.TaxRule
+	if err := config.DB.Where("id = ?", id).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tax_model.TaxRule{}, ErrTaxRuleNotFound
+		}
+		return tax_model.TaxRule{}, ErrUpdateTaxRuleFailed
+	}
+
+	if input.Name != "" {
+		rule.Name = input.Name
+	}
+	if input.Type != "" {
+		rule.Type = input.Type
+	}
+	if input.Rate > 0 {
+		rule.Rate = input.Rate
+	}
+	rule.IsInclusive = input.IsInclusive && rule.Type == TaxTypeTax
+	rule.OrderType = input.OrderType
+	rule.IsActive = input.IsActive
+
+	categories, err := findCategories(input.ExemptCategoryIDs)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return tax_model.TaxRule{}, err
+		}
+		return tax_model.TaxRule{}, ErrUpdateTaxRuleFailed
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&rule).Error; err != nil {
+			return err
+		}
+		return tx.Model(&rule).Association("ExemptCategories").Replace(categories)
+	})
+	if err != nil {
+		return tax_model.TaxRule{}, ErrUpdateTaxRuleFailed
+	}
+	rule.ExemptCategories = categories
+
+	return rule, nil
+}
+
+// DeleteTaxRule menghapus aturan pajak (soft delete). Transaksi lama tetap menyimpan snapshot rinciannya.
+func (s *taxService) DeleteTaxRule(ruleID string) error {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return ErrTaxRuleNotFound
+	}
+
+	var rule tax_model.TaxRule
+	if err := config.DB.Where("id = ?", id).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTaxRuleNotFound
+		}
+		return ErrDeleteTaxRuleFailed
+	}
+
+	if err := config.DB.Delete(&rule).Error; err != nil {
+		return ErrDeleteTaxRuleFailed
+	}
+	return nil
+}
+
+// GetAllTaxRules mengambil semua aturan pajak (admin)
+func (s *taxService) GetAllTaxRules() ([]tax_model.TaxRule, error) {
+	var rules []tax_model.TaxRule
+	if err := config.DB.Preload("ExemptCategories").Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, ErrGetTaxRulesFailed
+	}
+	return rules, nil
+}
+
+// GetActiveTaxRules aturan aktif (public, untuk estimasi pajak di halaman checkout)
+func (s *taxService) GetActiveTaxRules() ([]tax_model.TaxRule, error) {
+	return activeTaxRules(config.DB)
+}
+
+// activeTaxRules aturan aktif yang dipakai perhitungan transaksi. db boleh berupa DB transaction.
+func activeTaxRules(db *gorm.DB) ([]tax_model.TaxRule, error) {
+	var rules []tax_model.TaxRule
+	if err := db.Preload("ExemptCategories").Where("is_active = ?", true).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, ErrGetTaxRulesFailed
+	}
+	return rules, nil
+}
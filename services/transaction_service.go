@@ -2,12 +2,15 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"pos-go/config"
 	"pos-go/dto"
 	menu_model "pos-go/models/menu_model"
 	promo_model "pos-go/models/promo_model"
 	transaction_model "pos-go/models/transaction_model"
 	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +25,8 @@ var (
 	ErrTransactionNotFound = errors.New("Transaksi tidak ditemukan")
 	ErrDatabaseError       = errors.New("Database error")
 	ErrInvalidStatus       = errors.New("Status pesanan tidak valid untuk role ini")
+	ErrStatusConflict      = errors.New("Status transaksi sudah berubah, silakan muat ulang")
+	ErrAmountMismatch      = errors.New("Total item tidak sama dengan total transaksi")
 )
 
 type TransactionService struct {
@@ -45,34 +50,18 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 		}
 	}()
 
-	// Calculate subtotal and prepare items
-	var subtotal float64
-	var items []transaction_model.TransactionItem
-	var appliedPromoID *uuid.UUID
-	var discount float64
-
-	for _, itemReq := range req.Items {
-		var menu menu_model.Menu
-		if err := tx.First(&menu, "id = ? AND is_available = ?", itemReq.MenuID, true).Error; err != nil {
-			tx.Rollback()
-			if err == gorm.ErrRecordNotFound {
-				return nil, "", "", ErrMenuNotFound
-			}
-			return nil, "", "", ErrDatabaseError
-		}
-
-		itemSubtotal := menu.Price * float64(itemReq.Quantity)
-		subtotal += itemSubtotal
-
-		item := transaction_model.TransactionItem{
-			MenuID:    menu.ID,
-			MenuName:  menu.Name,
-			MenuPrice: menu.Price,
-			Quantity:  itemReq.Quantity,
-			Subtotal:  itemSubtotal,
-		}
-		items = append(items, item)
+	// Validasi menu & modifier lalu siapkan items (ronde pertama)
+	items, taxableLines, stockTracked, err := buildTransactionItems(tx, req.Items, 1)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", "", err
+	}
+	var subtotal utils.Money
+	for _, item := range items {
+		subtotal += item.Subtotal
 	}
+	var appliedPromoID *uuid.UUID
+	var discount utils.Money
 
 	// Jika ada promo_code, validasi dan hitung diskon
 	if req.PromoCode != "" {
@@ -84,12 +73,21 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 		}
 		appliedPromoID = &promo.ID
 		discount = disc
-		subtotal = subtotal - discount
 	}
 
-	// Hitung PPN 10% otomatis dari subtotal setelah diskon
-	tax := subtotal * 0.10
-	totalAmount := subtotal + tax
+	// Hitung service charge & pajak dari aturan pajak aktif (lihat tax_calculator.go)
+	rules, err := activeTaxRules(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", "", ErrDatabaseError
+	}
+	calc := CalculateTaxes(taxableLines, discount, rules, req.OrderType)
+	for i := range items {
+		items[i].Discount = calc.Lines[i].Discount
+		items[i].ServiceCharge = calc.Lines[i].ServiceCharge
+		items[i].TaxAmount = calc.Lines[i].Tax
+	}
+	taxes := transactionTaxes(calc)
 
 	// Validasi order type & table number
 	if req.OrderType == "dine_in" {
@@ -100,6 +98,10 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 	} else {
 		// take_away: pastikan table_number kosong
 		req.TableNumber = nil
+		if req.OpenBill {
+			tx.Rollback()
+			return nil, "", "", ErrOpenBillDineInOnly
+		}
 	}
 
 	// Create transaction
@@ -109,28 +111,41 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 	// LOGIC BARU (best practice kasir):
 	// - Cash: pending dulu, dibayar di kasir -> kasir update jadi paid
 	// - Non-cash: pending dulu, jadi paid via webhook Midtrans
+	// - Open bill: belum ada token / expired_at sampai bill ditutup (CloseBill)
 	paymentStatus = "pending"
 	orderStatus = "pending"
-	if req.PaymentMethod != "cash" {
+	if req.PaymentMethod != "cash" && !req.OpenBill {
 		// Set expired 24 jam dari sekarang untuk non-cash
 		expirationTime := time.Now().Add(24 * time.Hour)
 		expiredAt = &expirationTime
 	}
 
+	// Nomor pesanan harian (A-042) untuk dipanggil kasir / ditampilkan di layar antrian
+	businessDate := config.StoreCalendar.Today()
+	orderNumber, err := nextOrderNumber(config.DB, businessDate)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", "", err
+	}
+
 	transaction := transaction_model.Transaction{
+		OrderNumber:   orderNumber,
+		BusinessDate:  &businessDate,
 		CustomerName:  req.CustomerName,
 		CustomerPhone: req.CustomerPhone,
 		OrderType:     req.OrderType,
 		TableNumber:   req.TableNumber,
 		PromoCode:     req.PromoCode,
 		Discount:      discount,
-		Subtotal:      subtotal,
-		Tax:           tax,
-		TotalAmount:   totalAmount,
+		Subtotal:      calc.Subtotal,
+		ServiceCharge: calc.ServiceCharge,
+		Tax:           calc.Tax,
+		TotalAmount:   calc.Total,
 		PaymentMethod: req.PaymentMethod,
 		PaymentStatus: paymentStatus,
 		OrderStatus:   orderStatus,
 		ExpiredAt:     expiredAt,
+		IsOpenBill:    req.OpenBill,
 		Notes:         req.Notes,
 	}
 
@@ -139,6 +154,15 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 		return nil, "", "", ErrDatabaseError
 	}
 
+	// Dine-in: tempati meja (gagal jika meja tidak ada atau sedang dipakai pesanan lain).
+	// Toko yang belum mengatur meja sama sekali tetap bisa mencatat nomor meja tanpa status meja.
+	if transaction.TableNumber != nil {
+		if err := occupyTable(tx, *transaction.TableNumber, transaction.ID); err != nil {
+			tx.Rollback()
+			return nil, "", "", err
+		}
+	}
+
 	// Create transaction items
 	for i := range items {
 		items[i].TransactionID = transaction.ID
@@ -146,11 +170,28 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 			tx.Rollback()
 			return nil, "", "", ErrDatabaseError
 		}
+		// Kurangi stok secara atomik; gagal jika stok tidak cukup (semua perubahan di-rollback)
+		if stockTracked[items[i].MenuID] {
+			if err := deductStockForSale(tx, transaction.ID, items[i].MenuID, items[i].Quantity); err != nil {
+				tx.Rollback()
+				return nil, "", "", err
+			}
+		}
 	}
 
+	// Simpan rincian pajak per aturan
+	for i := range taxes {
+		taxes[i].TransactionID = transaction.ID
+		if err := tx.Create(&taxes[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, "", "", ErrDatabaseError
+		}
+	}
+	transaction.Taxes = taxes
+
 	// Untuk non-cash: dapatkan snap token SEBELUM commit. Jika gagal, rollback (data tidak masuk DB).
 	var snapToken, snapURL string
-	if req.PaymentMethod != "cash" {
+	if req.PaymentMethod != "cash" && !req.OpenBill {
 		transaction.Items = items
 		var errSnap error
 		snapToken, snapURL, errSnap = s.GenerateSnapToken(transaction)
@@ -171,73 +212,159 @@ func (s TransactionService) CreateTransaction(req dto.CreateTransactionRequest)
 		config.DB.Model(&promo_model.Promo{}).Where("id = ?", *appliedPromoID).Update("usage_count", gorm.Expr("usage_count + ?", 1))
 		var promo promo_model.Promo
 		if err := config.DB.First(&promo, "id = ?", *appliedPromoID).Error; err == nil && promo.UsageLimit > 0 && promo.UsageCount >= promo.UsageLimit {
-			config.DB.Model(&promo_model.Promo{}).Where("id = ?", *appliedPromoID).Update("is_active", false)
+			config.DB.Model(&promo_model.Promo{}).Where("id = ?", *appliedPromoID).
+				Updates(map[string]interface{}{"is_active": false, "exhausted": true})
 		}
 	}
 
 	// Load items for response
 	transaction.Items = items
 
+	publishTransactionEvent(EventOrderCreated, &transaction, "")
+
 	return &transaction, snapToken, snapURL, nil
 }
 
+// buildTransactionItems memvalidasi menu (harus tersedia) dan pilihan modifier tiap item lalu menyiapkan item
+// (belum disimpan) untuk ronde batch, beserta baris pajak dan menu yang stoknya dilacak.
+// Dipakai saat membuat transaksi dan saat menambah ronde open bill.
+func buildTransactionItems(tx *gorm.DB, reqs []dto.CreateTransactionItemRequest, batch int) ([]transaction_model.TransactionItem, []TaxableLine, map[uuid.UUID]bool, error) {
+	var items []transaction_model.TransactionItem
+	var taxableLines []TaxableLine
+	stockTracked := make(map[uuid.UUID]bool)
+
+	for _, itemReq := range reqs {
+		var menu menu_model.Menu
+		if err := preloadModifierGroups(tx, false).Preload("Category").First(&menu, "id = ? AND is_available = ?", itemReq.MenuID, true).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, nil, nil, ErrMenuNotFound
+			}
+			return nil, nil, nil, ErrDatabaseError
+		}
+
+		// Validasi pilihan modifier (wajib/opsional, min/max) dan hitung tambahan harga per porsi
+		modifiers, modifierPrice, err := resolveModifiers(menu, itemReq.ModifierOptionIDs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		itemSubtotal := (menu.Price + modifierPrice).Mul(itemReq.Quantity)
+		items = append(items, transaction_model.TransactionItem{
+			MenuID:        menu.ID,
+			MenuName:      menu.Name,
+			MenuPrice:     menu.Price,
+			ModifierPrice: modifierPrice,
+			Modifiers:     modifiers,
+			Quantity:      itemReq.Quantity,
+			Subtotal:      itemSubtotal,
+			Notes:         itemReq.Notes,
+			Station:       resolveStation(menu),
+			Status:        ItemStatusQueued,
+			Batch:         batch,
+		})
+		taxableLines = append(taxableLines, TaxableLine{CategoryID: menu.CategoryID, Amount: itemSubtotal})
+		if menu.TrackStock {
+			stockTracked[menu.ID] = true
+		}
+	}
+	return items, taxableLines, stockTracked, nil
+}
+
+// transactionTaxes rincian pajak per aturan dari hasil perhitungan (belum disimpan)
+func transactionTaxes(calc TaxCalculation) []transaction_model.TransactionTax {
+	taxes := make([]transaction_model.TransactionTax, 0, len(calc.Breakdown))
+	for _, b := range calc.Breakdown {
+		ruleID := b.RuleID
+		taxes = append(taxes, transaction_model.TransactionTax{
+			TaxRuleID:     &ruleID,
+			Name:          b.Name,
+			Type:          b.Type,
+			Rate:          b.Rate,
+			IsInclusive:   b.IsInclusive,
+			TaxableAmount: b.TaxableAmount,
+			Amount:        b.Amount,
+		})
+	}
+	return taxes
+}
+
 // GenerateSnapToken untuk request ke Midtrans. Mengembalikan error jika Midtrans gagal.
 // Penting: sum(item_details) harus persis sama dengan GrossAmt (Midtrans validation).
+// item_details dibangun dari rincian yang sama dengan struk: item, diskon, service charge, dan pajak eksklusif (transaction.Taxes).
 func (s TransactionService) GenerateSnapToken(transaction transaction_model.Transaction) (string, string, error) {
+	itemDetails, err := snapItemDetails(transaction)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Prepare request
+	req := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  midtransOrderID(transaction),
+			GrossAmt: transaction.TotalAmount.Int64(),
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: transaction.CustomerName,
+			Email: "",
+			Phone: transaction.CustomerPhone,
+		},
+		Items: &itemDetails,
+	}
+
+	return requestSnapToken(req)
+}
+
+// snapItemDetails item_details Midtrans untuk transaksi; ErrAmountMismatch jika jumlahnya tidak sama dengan total transaksi
+func snapItemDetails(transaction transaction_model.Transaction) ([]midtrans.ItemDetails, error) {
 	// Prepare items for Midtrans (harga per item * qty = subtotal per line)
 	var itemDetails []midtrans.ItemDetails
 	for _, item := range transaction.Items {
 		itemDetails = append(itemDetails, midtrans.ItemDetails{
 			ID:    item.MenuID.String(),
-			Name:  item.MenuName,
-			Price: int64(item.MenuPrice),
+			Name:  midtransItemName(itemDisplayName(item)),
+			Price: (item.MenuPrice + item.ModifierPrice).Int64(),
 			Qty:   int32(item.Quantity),
 		})
 	}
 
 	// Jika ada diskon: tambahkan line item negatif agar sum(item_details) = TotalAmount
-	// sum(items) = subtotal sebelum diskon; TotalAmount = subtotal_setelah_diskon + tax
 	if transaction.Discount > 0 {
 		itemDetails = append(itemDetails, midtrans.ItemDetails{
 			ID:    "DISC",
 			Name:  "Diskon",
-			Price: -int64(transaction.Discount),
+			Price: -transaction.Discount.Int64(),
 			Qty:   1,
 		})
 	}
 
-	// Hitung sum item_details sebelum PPN (agar bisa set PPN = sisa ke GrossAmt)
-	var sumBeforeTax int64
-	for _, it := range itemDetails {
-		sumBeforeTax += it.Price * int64(it.Qty)
-	}
-	grossAmt := int64(transaction.TotalAmount)
-	taxLineAmount := grossAmt - sumBeforeTax
-	// PPN 10% sebagai line item (jumlah diset agar sum(item_details) = GrossAmt)
-	if taxLineAmount != 0 {
+	// Service charge & pajak eksklusif per aturan (pajak inklusif sudah ada di harga item)
+	for i, t := range transaction.Taxes {
+		if t.IsInclusive || t.Amount == 0 {
+			continue
+		}
 		itemDetails = append(itemDetails, midtrans.ItemDetails{
-			ID:    "TAX-PPN",
-			Name:  "PPN 10%",
-			Price: taxLineAmount,
+			ID:    fmt.Sprintf("TAX-%d", i+1),
+			Name:  fmt.Sprintf("%s %s%%", t.Name, strconv.FormatFloat(t.Rate, 'f', -1, 64)),
+			Price: t.Amount.Int64(),
 			Qty:   1,
 		})
 	}
 
-	// Prepare request
-	req := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  transaction.ID.String(),
-			GrossAmt: grossAmt,
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: transaction.CustomerName,
-			Email: "",
-			Phone: transaction.CustomerPhone,
-		},
-		Items: &itemDetails,
+	// Semua nominal sudah rupiah bulat (utils.Money), jadi sum(item_details) harus sama persis dengan GrossAmt.
+	// Jika tidak, ada bug perhitungan: tolak daripada menambal dengan baris penyeimbang.
+	var sum int64
+	for _, it := range itemDetails {
+		sum += it.Price * int64(it.Qty)
 	}
+	grossAmt := transaction.TotalAmount.Int64()
+	if sum != grossAmt {
+		return nil, fmt.Errorf("%w: item_details %d, gross_amount %d", ErrAmountMismatch, sum, grossAmt)
+	}
+	return itemDetails, nil
+}
 
-	// Request snap token
+// requestSnapToken meminta token Snap ke Midtrans (dipakai tagihan penuh maupun pembayaran sebagian)
+func requestSnapToken(req *snap.Request) (string, string, error) {
 	snapResp, err := config.MidtransClient.CreateTransaction(req)
 	if err != nil {
 		return "", "", err
@@ -249,36 +376,133 @@ func (s TransactionService) GenerateSnapToken(transaction transaction_model.Tran
 	return snapResp.Token, snapResp.RedirectURL, nil
 }
 
-// UpdateTransactionStatus updates payment and order status
-func (s TransactionService) UpdateTransactionStatus(id uuid.UUID, paymentStatus, orderStatus string) (*transaction_model.Transaction, error) {
-	var transaction transaction_model.Transaction
-	if err := config.DB.First(&transaction, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, ErrTransactionNotFound
-		}
-		return nil, ErrDatabaseError
+// midtransOrderID order_id Snap transaksi: MidtransOrderID jika ada (open bill yang ditutup), selain itu id transaksi
+func midtransOrderID(transaction transaction_model.Transaction) string {
+	if transaction.MidtransOrderID != "" {
+		return transaction.MidtransOrderID
+	}
+	return transaction.ID.String()
+}
+
+// midtransItemName memotong nama item ke batas 50 karakter item_details Midtrans
+func midtransItemName(name string) string {
+	const maxLen = 50
+	if r := []rune(name); len(r) > maxLen {
+		return string(r[:maxLen-3]) + "..."
+	}
+	return name
+}
+
+// applyStatusTransition memvalidasi perubahan status lewat tabel transisi (order_state.go) lalu menyimpannya.
+// Update dijaga dengan status lama di WHERE agar dua request bersamaan tidak saling timpa.
+// String kosong berarti status tersebut tidak diubah; extra berisi kolom tambahan (mis. closed_by_user_id).
+func applyStatusTransition(db *gorm.DB, transaction *transaction_model.Transaction, paymentStatus, orderStatus string, extra map[string]interface{}) error {
+	if err := ValidateStatusTransition(transaction.PaymentStatus, transaction.OrderStatus, paymentStatus, orderStatus); err != nil {
+		return err
 	}
 
 	updates := map[string]interface{}{}
-	if paymentStatus != "" {
+	if paymentStatus != "" && paymentStatus != transaction.PaymentStatus {
 		updates["payment_status"] = paymentStatus
 	}
-	if orderStatus != "" {
+	if orderStatus != "" && orderStatus != transaction.OrderStatus {
 		updates["order_status"] = orderStatus
 	}
+	// Status sama (mis. webhook dikirim ulang): no-op
+	if len(updates) == 0 {
+		return nil
+	}
+	for k, v := range extra {
+		updates[k] = v
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&transaction_model.Transaction{}).
+			Where("id = ? AND payment_status = ? AND order_status = ?", transaction.ID, transaction.PaymentStatus, transaction.OrderStatus).
+			Updates(updates)
+		if res.Error != nil {
+			return ErrDatabaseError
+		}
+		if res.RowsAffected == 0 {
+			return ErrStatusConflict
+		}
+
+		// Pesanan batal (manual, webhook, atau expiry worker): kembalikan kuota promo dan stok yang dipakai
+		if orderStatus == OrderStatusCancelled && transaction.OrderStatus != OrderStatusCancelled {
+			if err := releasePromoUsage(tx, transaction.PromoCode); err != nil {
+				return ErrDatabaseError
+			}
+			if err := restoreStockForTransaction(tx, transaction.ID); err != nil {
+				return err
+			}
+		}
+
+		// Pesanan selesai / batal: meja dine-in kembali kosong
+		if (orderStatus == OrderStatusCompleted || orderStatus == OrderStatusCancelled) && transaction.OrderStatus != orderStatus {
+			if err := releaseTable(tx, transaction.ID); err != nil {
+				return ErrDatabaseError
+			}
+		}
 
-	if err := config.DB.Model(&transaction).Updates(updates).Error; err != nil {
+		// Pesanan ditandai ready untuk seluruh order: item yang belum selesai ikut ready
+		if orderStatus == OrderStatusReady && transaction.OrderStatus != OrderStatusReady {
+			if err := tx.Model(&transaction_model.TransactionItem{}).
+				Where("transaction_id = ? AND status <> ?", transaction.ID, ItemStatusReady).
+				Update("status", ItemStatusReady).Error; err != nil {
+				return ErrDatabaseError
+			}
+		}
+
+		// Pembayaran berhasil: potong stok bahan baku sesuai resep
+		if paymentStatus == PaymentStatusPaid && transaction.PaymentStatus != PaymentStatusPaid {
+			if err := deductIngredientsForTransaction(tx, transaction.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateTransactionStatus updates payment and order status (dipakai webhook Midtrans).
+// Status kosong berarti tidak diubah. Transisi yang tidak sah dikembalikan sebagai *TransitionError.
+func (s TransactionService) UpdateTransactionStatus(id uuid.UUID, paymentStatus, orderStatus string) (*transaction_model.Transaction, error) {
+	var transaction transaction_model.Transaction
+	if err := config.DB.First(&transaction, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTransactionNotFound
+		}
 		return nil, ErrDatabaseError
 	}
 
+	previous := transaction
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Snap tagihan penuh dibayar: catat sebagai satu payment (di-rollback jika transisi ditolak)
+		if paymentStatus == PaymentStatusPaid && transaction.PaymentStatus == PaymentStatusPending {
+			if err := recordRemainingPayment(tx, transaction, transaction.PaymentMethod, midtransOrderID(transaction), nil); err != nil {
+				return err
+			}
+		}
+		return applyStatusTransition(tx, &transaction, paymentStatus, orderStatus, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Reload with items
-	if err := config.DB.Preload("Items").First(&transaction, "id = ?", id).Error; err != nil {
+	if err := config.DB.Preload("Items.Modifiers").First(&transaction, "id = ?", id).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
+	publishStatusChange(&previous, &transaction)
 
 	return &transaction, nil
 }
 
+// roleOrderTargets order_status tujuan yang boleh di-set oleh tiap role. Status asal dicek oleh tabel transisi.
+var roleOrderTargets = map[string][]string{
+	"kasir": {OrderStatusCooking, OrderStatusCompleted, OrderStatusCancelled},
+	"koki":  {OrderStatusReady},
+}
+
 // UpdateOrderStatusForRole mengubah order_status dengan aturan per role (kasir / koki). closedByUserID diisi saat kasir menandai completed (untuk laporan).
 func (s TransactionService) UpdateOrderStatusForRole(id uuid.UUID, role string, newStatus string, closedByUserID *uuid.UUID) (*transaction_model.Transaction, error) {
 	var tx transaction_model.Transaction
@@ -289,60 +513,107 @@ func (s TransactionService) UpdateOrderStatusForRole(id uuid.UUID, role string,
 		return nil, ErrDatabaseError
 	}
 
-	current := tx.OrderStatus
-
-	switch role {
-	case "kasir":
-		switch newStatus {
-		case "cooking":
-			if current != "pending" {
-				return nil, ErrInvalidStatus
-			}
-		case "completed":
-			if current != "ready" {
-				return nil, ErrInvalidStatus
-			}
-			// Best practice: hanya boleh selesai jika sudah dibayar
-			if tx.PaymentStatus != "paid" {
-				return nil, errors.New("Transaksi belum berstatus paid, tidak dapat diselesaikan")
-			}
-		case "cancelled":
-			// Kasir boleh batalkan pesanan yang belum selesai: pending, cooking, atau ready
-			if current != "pending" && current != "cooking" && current != "ready" {
-				return nil, ErrInvalidStatus
-			}
-		default:
-			return nil, ErrInvalidStatus
-		}
-	case "koki":
-		switch newStatus {
-		case "ready":
-			if current != "cooking" {
-				return nil, ErrInvalidStatus
-			}
-		default:
-			return nil, ErrInvalidStatus
+	allowed := false
+	for _, target := range roleOrderTargets[role] {
+		if target == newStatus {
+			allowed = true
+			break
 		}
-	default:
+	}
+	if !allowed {
 		return nil, ErrInvalidStatus
 	}
 
-	updates := map[string]interface{}{"order_status": newStatus}
+	paymentStatus := ""
+	// Pesanan yang dibatalkan sebelum dibayar: pembayaran ikut dibatalkan agar webhook paid yang telat tidak menghidupkannya lagi
+	if newStatus == OrderStatusCancelled && tx.PaymentStatus == PaymentStatusPending {
+		paymentStatus = PaymentStatusCancelled
+	}
+
+	extra := map[string]interface{}{}
 	// Saat kasir menandai selesai (completed), simpan kasir yang memproses untuk laporan per kasir
-	if newStatus == "completed" && closedByUserID != nil {
-		updates["closed_by_user_id"] = closedByUserID
+	if newStatus == OrderStatusCompleted && closedByUserID != nil {
+		extra["closed_by_user_id"] = closedByUserID
 	}
-	if err := config.DB.Model(&tx).Updates(updates).Error; err != nil {
-		return nil, ErrDatabaseError
+	previous := tx
+	if err := applyStatusTransition(config.DB, &tx, paymentStatus, newStatus, extra); err != nil {
+		return nil, err
 	}
 
-	if err := config.DB.Preload("Items").First(&tx, "id = ?", id).Error; err != nil {
+	if err := config.DB.Preload("Items.Modifiers").First(&tx, "id = ?", id).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
+	publishStatusChange(&previous, &tx)
 
 	return &tx, nil
 }
 
+// UpdateItemStatus mengubah status satu item (per stasiun dapur) lalu menurunkan order_status dari status semua item:
+// item pertama mulai dikerjakan -> cooking, semua item ready -> ready. Hanya untuk pesanan pending / cooking.
+func (s TransactionService) UpdateItemStatus(transactionID, itemID uuid.UUID, newStatus string) (*transaction_model.Transaction, error) {
+	var transaction transaction_model.Transaction
+	if err := config.DB.Preload("Items").First(&transaction, "id = ?", transactionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+	if transaction.OrderStatus != OrderStatusPending && transaction.OrderStatus != OrderStatusCooking {
+		return nil, &TransitionError{Field: "order_status", From: transaction.OrderStatus, To: OrderStatusCooking}
+	}
+
+	idx := -1
+	for i, it := range transaction.Items {
+		if it.ID == itemID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, ErrItemNotFound
+	}
+	item := transaction.Items[idx]
+	if !allowedTransition(itemTransitions, item.Status, newStatus) {
+		return nil, &TransitionError{Field: "item_status", From: item.Status, To: newStatus}
+	}
+
+	previous := transaction
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&transaction_model.TransactionItem{}).
+			Where("id = ? AND status = ?", item.ID, item.Status).
+			Update("status", newStatus)
+		if res.Error != nil {
+			return ErrDatabaseError
+		}
+		if res.RowsAffected == 0 {
+			return ErrStatusConflict
+		}
+		transaction.Items[idx].Status = newStatus
+
+		for _, step := range derivedOrderSteps(transaction.OrderStatus, deriveOrderStatus(transaction.Items)) {
+			if err := applyStatusTransition(tx, &transaction, "", step, nil); err != nil {
+				return err
+			}
+			transaction.OrderStatus = step
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Preload("Items.Modifiers").First(&transaction, "id = ?", transactionID).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	if previous.OrderStatus != transaction.OrderStatus {
+		publishStatusChange(&previous, &transaction)
+	} else {
+		publishTransactionEvent(EventItemStatusChanged, &transaction, previous.OrderStatus)
+	}
+
+	return &transaction, nil
+}
+
 // CancelOrder membatalkan pesanan. Kasir atau Admin; status saat ini harus pending, cooking, atau ready.
 func (s TransactionService) CancelOrder(id uuid.UUID, role string) (*transaction_model.Transaction, error) {
 	role = strings.ToLower(strings.TrimSpace(role))
@@ -354,11 +625,12 @@ func (s TransactionService) CancelOrder(id uuid.UUID, role string) (*transaction
 	if role == "admin" {
 		effectiveRole = "kasir"
 	}
-	return s.UpdateOrderStatusForRole(id, effectiveRole, "cancelled", nil)
+	return s.UpdateOrderStatusForRole(id, effectiveRole, OrderStatusCancelled, nil)
 }
 
-// ConfirmCashPaid - khusus kasir untuk konfirmasi pembayaran tunai. closedByUserID = user_id kasir yang login (untuk laporan per kasir).
-func (s TransactionService) ConfirmCashPaid(id uuid.UUID, closedByUserID *uuid.UUID) (*transaction_model.Transaction, error) {
+// ConfirmCashPaid - khusus kasir untuk konfirmasi pembayaran tunai (sisa tagihan). closedByUserID = user_id kasir yang login (untuk laporan per kasir).
+// amountTendered = uang yang diserahkan pelanggan; minimal sisa tagihan, selisihnya dicatat sebagai kembalian.
+func (s TransactionService) ConfirmCashPaid(id uuid.UUID, closedByUserID *uuid.UUID, amountTendered utils.Money) (*transaction_model.Transaction, error) {
 	var transaction transaction_model.Transaction
 	if err := config.DB.First(&transaction, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -371,41 +643,139 @@ func (s TransactionService) ConfirmCashPaid(id uuid.UUID, closedByUserID *uuid.U
 	if transaction.PaymentMethod != "cash" {
 		return nil, errors.New("Hanya transaksi tunai yang bisa dikonfirmasi oleh kasir")
 	}
-	if transaction.PaymentStatus != "pending" {
+	if transaction.PaymentStatus != PaymentStatusPending {
 		return nil, errors.New("Status pembayaran sudah bukan pending")
 	}
-	if transaction.OrderStatus == "cancelled" {
+	if transaction.OrderStatus == OrderStatusCancelled {
 		return nil, errors.New("Transaksi sudah dibatalkan")
 	}
 
-	updates := map[string]interface{}{"payment_status": "paid"}
-	if closedByUserID != nil {
-		updates["closed_by_user_id"] = closedByUserID
-	}
-	if err := config.DB.Model(&transaction).Updates(updates).Error; err != nil {
-		return nil, ErrDatabaseError
+	// Sisa tagihan (setelah tender split bill yang sudah dibayar) dicatat sebagai payment tunai kasir ini
+	// beserta uang diterima & kembalian; settleTransactionPayments menandai paid dan menutup open bill
+	previous := transaction
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		payments, err := loadPayments(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		paid, pending := sumPayments(payments)
+		if pending > 0 {
+			return ErrPendingTenderExists
+		}
+		due := transaction.TotalAmount - paid
+		if due > 0 {
+			if amountTendered < due {
+				return fmt.Errorf("%w: Rp %s", ErrTenderedBelowAmount, due)
+			}
+			now := time.Now()
+			payment := transaction_model.Payment{
+				TransactionID:  transaction.ID,
+				Method:         "cash",
+				Amount:         due,
+				Status:         TenderStatusPaid,
+				AmountTendered: amountTendered,
+				ChangeAmount:   amountTendered - due,
+				CashierUserID:  closedByUserID,
+				PaidAt:         &now,
+			}
+			if err := tx.Create(&payment).Error; err != nil {
+				return ErrDatabaseError
+			}
+		}
+		return settleTransactionPayments(tx, &transaction, closedByUserID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Reload with items
-	if err := config.DB.Preload("Items").First(&transaction, "id = ?", id).Error; err != nil {
+	// Reload with items (dan payment agar kembalian tampil ke kasir)
+	if err := config.DB.Preload("Items.Modifiers").Preload("Payments").First(&transaction, "id = ?", id).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
+	publishStatusChange(&previous, &transaction)
 	return &transaction, nil
 }
 
-// GetAllTransactions retrieves all transactions with items
-func (s TransactionService) GetAllTransactions() ([]transaction_model.Transaction, error) {
+// TransactionSortColumns kolom yang boleh dipakai untuk sort_by pada GET /transaction
+var TransactionSortColumns = map[string]string{
+	"created_at":    "created_at",
+	"total_amount":  "total_amount",
+	"customer_name": "customer_name",
+}
+
+// GetAllTransactions retrieves transactions (dengan items) sesuai filter, satu halaman, beserta total baris.
+func (s TransactionService) GetAllTransactions(filter dto.TransactionListFilter, page utils.PageQuery) ([]transaction_model.Transaction, int64, error) {
+	q, err := transactionListQuery(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var transactions []transaction_model.Transaction
-	if err := config.DB.Preload("Items").Order("created_at DESC").Find(&transactions).Error; err != nil {
-		return nil, ErrDatabaseError
+	total, err := utils.Paginate(q, page, &transactions, "Items.Modifiers", "Taxes")
+	if err != nil {
+		return nil, 0, ErrDatabaseError
 	}
-	return transactions, nil
+	return transactions, total, nil
+}
+
+// transactionListQuery query transaksi sesuai filter list (dipakai list berhalaman dan ekspor)
+func transactionListQuery(filter dto.TransactionListFilter) (*gorm.DB, error) {
+	q := config.DB.Model(&transaction_model.Transaction{})
+
+	if filter.PaymentStatus != "" {
+		q = q.Where("payment_status = ?", filter.PaymentStatus)
+	}
+	if filter.OrderStatus != "" {
+		q = q.Where("order_status = ?", filter.OrderStatus)
+	}
+	if filter.PaymentMethod != "" {
+		q = q.Where("payment_method = ?", filter.PaymentMethod)
+	}
+	if filter.OrderType != "" {
+		q = q.Where("order_type = ?", filter.OrderType)
+	}
+	if filter.DateFrom != "" {
+		from, _, err := config.StoreCalendar.ParseDay(filter.DateFrom)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("created_at >= ?", from)
+	}
+	if filter.DateTo != "" {
+		_, to, err := config.StoreCalendar.ParseDay(filter.DateTo)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("created_at < ?", to)
+	}
+	if filter.ClosedByUserID != "" {
+		q = q.Where("closed_by_user_id = ?", filter.ClosedByUserID)
+	}
+	if filter.TableNumber != nil {
+		q = q.Where("table_number = ?", *filter.TableNumber)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := utils.ContainsPattern(search)
+		q = q.Where("LOWER(customer_name) LIKE ? OR customer_phone LIKE ? OR LOWER(order_number) = ?", like, like, strings.ToLower(search))
+	}
+	return q, nil
+}
+
+// ExportTransactions menulis transaksi sesuai filter (sheet transaksi + rincian item) ke w secara streaming
+func (s TransactionService) ExportTransactions(filter dto.TransactionListFilter, w utils.TableWriter) error {
+	if _, err := transactionListQuery(filter); err != nil {
+		return err
+	}
+	return exportTransactionSheets(w, func() *gorm.DB {
+		q, _ := transactionListQuery(filter)
+		return q
+	}, true)
 }
 
 // GetTransactionByID retrieves a transaction by ID with items
 func (s TransactionService) GetTransactionByID(id uuid.UUID) (*transaction_model.Transaction, error) {
 	var transaction transaction_model.Transaction
-	if err := config.DB.Preload("Items").First(&transaction, "id = ?", id).Error; err != nil {
+	if err := config.DB.Preload("Items.Modifiers").Preload("Taxes").Preload("Payments").First(&transaction, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, ErrTransactionNotFound
 		}
@@ -427,18 +797,27 @@ func (s TransactionService) GetTransactionReceipt(id uuid.UUID) (*dto.ReceiptRes
 			closedByName = u.Name
 		}
 	}
+	var tendered, change utils.Money
+	for _, p := range tx.Payments {
+		if p.Method == "cash" && p.Status == TenderStatusPaid {
+			tendered += p.AmountTendered
+			change += p.ChangeAmount
+		}
+	}
 	items := make([]dto.ReceiptItemResponse, 0, len(tx.Items))
 	for _, it := range tx.Items {
 		items = append(items, dto.ReceiptItemResponse{
 			MenuName:  it.MenuName,
+			Modifiers: ItemModifierResponses(it),
 			Quantity:  it.Quantity,
-			MenuPrice: it.MenuPrice,
+			MenuPrice: it.MenuPrice + it.ModifierPrice,
 			Subtotal:  it.Subtotal,
 		})
 	}
 	return &dto.ReceiptResponse{
 		ID:               tx.ID,
-		CreatedAt:        tx.CreatedAt.Format(time.RFC3339),
+		OrderNumber:      tx.OrderNumber,
+		CreatedAt:        config.StoreCalendar.Format(tx.CreatedAt),
 		CustomerName:     tx.CustomerName,
 		CustomerPhone:    tx.CustomerPhone,
 		OrderType:        tx.OrderType,
@@ -446,10 +825,66 @@ func (s TransactionService) GetTransactionReceipt(id uuid.UUID) (*dto.ReceiptRes
 		Items:            items,
 		Subtotal:         tx.Subtotal,
 		Discount:         tx.Discount,
+		ServiceCharge:    tx.ServiceCharge,
 		Tax:              tx.Tax,
+		Taxes:            TransactionTaxResponses(tx.Taxes),
 		TotalAmount:      tx.TotalAmount,
 		PaymentMethod:    tx.PaymentMethod,
 		PaymentStatus:    tx.PaymentStatus,
+		AmountTendered:   tendered,
+		ChangeAmount:     change,
 		ClosedByUserName: closedByName,
 	}, nil
 }
+
+// TransactionTaxResponses mengubah rincian pajak transaksi ke bentuk response (struk & detail transaksi)
+func TransactionTaxResponses(taxes []transaction_model.TransactionTax) []dto.TransactionTaxResponse {
+	res := make([]dto.TransactionTaxResponse, 0, len(taxes))
+	for _, t := range taxes {
+		res = append(res, dto.TransactionTaxResponse{
+			Name:          t.Name,
+			Type:          t.Type,
+			Rate:          t.Rate,
+			IsInclusive:   t.IsInclusive,
+			TaxableAmount: t.TaxableAmount,
+			Amount:        t.Amount,
+		})
+	}
+	return res
+}
+
+// GetKitchenQueue pesanan aktif (dengan items) yang relevan untuk role, urut dari yang terlama.
+// Jika station diisi, hanya item stasiun tersebut yang disertakan (pesanan tanpa item di stasiun itu dilewati).
+// Dipakai sebagai snapshot awal stream dapur sebelum event berikutnya dikirim.
+func (s TransactionService) GetKitchenQueue(role, station string) ([]transaction_model.Transaction, error) {
+	statuses := KitchenStatusesForRole(role)
+	if statuses == nil {
+		statuses = []string{OrderStatusPending, OrderStatusCooking, OrderStatusReady}
+	}
+
+	var transactions []transaction_model.Transaction
+	if err := config.DB.Preload("Items.Modifiers").
+		Where("order_status IN ?", statuses).
+		Order("created_at ASC").
+		Find(&transactions).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	if station == "" {
+		return transactions, nil
+	}
+
+	filtered := make([]transaction_model.Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		items := make([]transaction_model.TransactionItem, 0, len(t.Items))
+		for _, it := range t.Items {
+			if it.Station == station {
+				items = append(items, it)
+			}
+		}
+		if len(items) > 0 {
+			t.Items = items
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pos-go/config"
+	"pos-go/dto"
+	report_model "pos-go/models/report_model"
+	settlement_model "pos-go/models/settlement_model"
+	transaction_model "pos-go/models/transaction_model"
+	user_model "pos-go/models/user_model"
+	"pos-go/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status Z-report
+const (
+	ZReportStatusDraft  = "draft"
+	ZReportStatusClosed = "closed"
+)
+
+// ZReportTopItems jumlah menu terlaris di Z-report
+const ZReportTopItems = 10
+
+var (
+	ErrZReportAlreadyClosed = errors.New("Z-report untuk tanggal ini sudah ditutup")
+	ErrZReportDayNotOver    = errors.New("Hari bisnis belum berakhir, belum bisa ditutup")
+	ErrZReportOpenShifts    = errors.New("Masih ada shift kasir yang belum ditutup pada tanggal ini")
+)
+
+// GetZReport Z-report tanggal dateStr (YYYY-MM-DD). Jika hari sudah ditutup, dikembalikan snapshot saat penutupan
+// (nomor dan isi tetap); jika belum, dihitung dari data terkini sebagai draft tanpa nomor.
+func (s ReportService) GetZReport(dateStr string) (*dto.ZReportResponse, error) {
+	if _, _, err := config.StoreCalendar.ParseDay(dateStr); err != nil {
+		return nil, err
+	}
+	var closed report_model.ZReport
+	err := config.DB.Where("business_date = ?", dateStr).First(&closed).Error
+	if err == nil {
+		var report dto.ZReportResponse
+		if err := json.Unmarshal([]byte(closed.Snapshot), &report); err != nil {
+			return nil, ErrDatabaseError
+		}
+		return &report, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDatabaseError
+	}
+	return buildZReport(config.DB, dateStr)
+}
+
+// CloseZReport menutup hari dateStr: menghitung Z-report, memberi nomor berikutnya, dan menyimpan snapshot-nya.
+// Nomor dialokasikan di bawah lock tabel sehingga berurutan tanpa celah walau dua admin menutup bersamaan; isi laporan
+// dihitung di transaksi yang sama (repeatable read) sehingga snapshot sesuai dengan saat nomor diberikan.
+// Hari hanya bisa ditutup sekali, setelah hari bisnisnya berakhir dan semua shift yang dibuka di hari itu ditutup.
+func (s ReportService) CloseZReport(dateStr string, userID uuid.UUID) (*dto.ZReportResponse, error) {
+	start, end, err := config.StoreCalendar.ParseDay(dateStr)
+	if err != nil {
+		return nil, err
+	}
+	if end.After(time.Now()) {
+		return nil, ErrZReportDayNotOver
+	}
+	var report *dto.ZReportResponse
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE z_reports IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return ErrDatabaseError
+		}
+		var exists int64
+		if err := tx.Model(&report_model.ZReport{}).Where("business_date = ?", dateStr).Count(&exists).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if exists > 0 {
+			return ErrZReportAlreadyClosed
+		}
+		var openShifts int64
+		if err := tx.Model(&settlement_model.Shift{}).
+			Where("opened_at >= ? AND opened_at < ? AND status = ?", start, end, ShiftStatusOpen).
+			Count(&openShifts).Error; err != nil {
+			return ErrDatabaseError
+		}
+		if openShifts > 0 {
+			return ErrZReportOpenShifts
+		}
+		var number int
+		if err := tx.Model(&report_model.ZReport{}).Select("COALESCE(MAX(number), 0) + 1").Scan(&number).Error; err != nil {
+			return ErrDatabaseError
+		}
+		var err error
+		if report, err = buildZReport(tx, dateStr); err != nil {
+			return err
+		}
+
+		closedAt := time.Now()
+		closedAtStr := config.StoreCalendar.Format(closedAt)
+		closedBy := userID.String()
+		report.Number = &number
+		report.Status = ZReportStatusClosed
+		report.ClosedAt = &closedAtStr
+		report.ClosedByUserID = &closedBy
+		report.ClosedByUserName = "-"
+		var u user_model.User
+		if err := tx.Select("name").First(&u, "id = ?", userID).Error; err == nil {
+			report.ClosedByUserName = u.Name
+		}
+
+		snapshot, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		businessDate, _ := time.Parse("2006-01-02", dateStr)
+		if err := tx.Create(&report_model.ZReport{
+			Number:         number,
+			BusinessDate:   businessDate,
+			ClosedByUserID: userID,
+			ClosedAt:       closedAt,
+			Snapshot:       string(snapshot),
+		}).Error; err != nil {
+			return ErrDatabaseError
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// buildZReport menghitung isi Z-report dari data terkini (status draft) lewat koneksi / transaksi db
+func buildZReport(db *gorm.DB, dateStr string) (*dto.ZReportResponse, error) {
+	start, end, err := config.StoreCalendar.ParseDay(dateStr)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := reportByDate(db, dateStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	scope := reportScope{db: db, start: start, end: end}
+	paymentMethods, err := reportByPaymentMethod(scope)
+	if err != nil {
+		return nil, err
+	}
+	topItems, err := reportByMenu(scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(topItems) > ZReportTopItems {
+		topItems = topItems[:ZReportTopItems]
+	}
+	voids, err := zReportVoids(db, start, end)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := settlementStatusByDate(db, dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ZReportResponse{
+		Date:             dateStr,
+		Status:           ZReportStatusDraft,
+		GeneratedAt:      config.StoreCalendar.Format(time.Now()),
+		ClosedByUserName: "-",
+		Summary:          daily.Summary,
+		PaymentMethods:   paymentMethods,
+		Voids:            *voids,
+		Settlements:      settlements.Items,
+		TopItems:         topItems,
+	}, nil
+}
+
+// zReportVoids pesanan batal (dibuat di hari tersebut), item open bill yang dihapus, dan refund yang diproses di hari tersebut
+func zReportVoids(db *gorm.DB, start, end time.Time) (*dto.ZReportVoids, error) {
+	var voids dto.ZReportVoids
+	var cancelled struct {
+		Count  int
+		Amount utils.Money
+	}
+	if err := db.Model(&transaction_model.Transaction{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(total_amount), 0) AS amount").
+		Where("created_at >= ? AND created_at < ? AND order_status = ?", start, end, OrderStatusCancelled).
+		Scan(&cancelled).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	voids.CancelledOrders = cancelled.Count
+	voids.CancelledAmount = cancelled.Amount
+
+	var voided struct {
+		Quantity int
+		Amount   utils.Money
+	}
+	if err := db.Unscoped().Model(&transaction_model.TransactionItem{}).
+		Select("COALESCE(SUM(quantity), 0) AS quantity, COALESCE(SUM(subtotal), 0) AS amount").
+		Where("deleted_at >= ? AND deleted_at < ?", start, end).
+		Scan(&voided).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	voids.VoidedItems = voided.Quantity
+	voids.VoidedItemsAmount = voided.Amount
+
+	var refunds struct {
+		Count  int
+		Amount utils.Money
+	}
+	if err := db.Table("refunds").
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Where("created_at >= ? AND created_at < ? AND status = ? AND deleted_at IS NULL", start, end, "succeeded").
+		Scan(&refunds).Error; err != nil {
+		return nil, ErrDatabaseError
+	}
+	voids.Refunds = refunds.Count
+	voids.RefundAmount = refunds.Amount
+	return &voids, nil
+}
+
+// zReportWidth lebar baris Z-report (karakter) pada ukuran font zReportFontSize
+const (
+	zReportFontSize = 9.0
+	zReportWidth    = 90
+)
+
+// WriteZReportPDF menulis Z-report sebagai PDF A4 (layout monospace seperti struk panjang)
+func WriteZReportPDF(report *dto.ZReportResponse, w io.Writer) error {
+	title := "Z-Report " + report.Date
+	if report.Number != nil {
+		title = fmt.Sprintf("Z-Report #%04d %s", *report.Number, report.Date)
+	}
+	doc := utils.NewPDFDocument(title)
+	line := func(text string) { doc.Text(text, zReportFontSize, false) }
+	bold := func(text string) { doc.Text(text, zReportFontSize, true) }
+	pair := func(label string, value interface{}) {
+		v := fmt.Sprint(value)
+		if m, ok := value.(utils.Money); ok {
+			v = m.Rupiah()
+		}
+		line(label + strings.Repeat(".", max(1, zReportWidth-len([]rune(label))-len([]rune(v))-1)) + " " + v)
+	}
+	section := func(name string, rows int) {
+		doc.Space(6)
+		doc.KeepTogether(rows+2, zReportFontSize)
+		bold(strings.ToUpper(name))
+		doc.Rule()
+	}
+
+	doc.Text("LAPORAN TUTUP HARI (Z-REPORT)", 14, true)
+	doc.Space(4)
+	if report.Number != nil {
+		pair("Nomor", fmt.Sprintf("%04d", *report.Number))
+	} else {
+		pair("Nomor", "DRAFT - BELUM DITUTUP")
+	}
+	pair("Tanggal bisnis", report.Date)
+	if report.ClosedAt != nil {
+		pair("Ditutup", *report.ClosedAt)
+		pair("Ditutup oleh", report.ClosedByUserName)
+	}
+	pair("Dicetak dari data", report.GeneratedAt)
+
+	sum := report.Summary
+	section("Ringkasan penjualan", 8)
+	pair("Jumlah transaksi", sum.TotalTransactions)
+	pair("Penjualan kotor", sum.TotalSales)
+	pair("Diskon", sum.TotalDiscount)
+	pair("Service charge", sum.TotalServiceCharge)
+	pair("Pajak", sum.TotalTax)
+	pair("Refund", sum.TotalRefund)
+	pair("Penjualan bersih", sum.NetSales)
+	pair("Tunai (setelah refund)", sum.TotalCash)
+	pair("Non-tunai (setelah refund)", sum.TotalNonCash)
+
+	section("Metode pembayaran", len(report.PaymentMethods))
+	if len(report.PaymentMethods) == 0 {
+		line("-")
+	}
+	for _, pm := range report.PaymentMethods {
+		pair(fmt.Sprintf("%s (%d trx)", pm.PaymentMethod, pm.TotalTransactions), pm.Amount)
+	}
+
+	section("Pajak & service charge", len(sum.Taxes)+1)
+	for _, t := range sum.Taxes {
+		label := fmt.Sprintf("%s %.2f%%", t.Name, t.Rate)
+		if t.IsInclusive {
+			label += " (inklusif)"
+		}
+		pair(label+" dari "+t.TaxableAmount.Rupiah(), t.Amount)
+	}
+	pair("Total pajak", sum.TotalTax)
+
+	v := report.Voids
+	section("Pembatalan & refund", 3)
+	pair(fmt.Sprintf("Pesanan dibatalkan (%d)", v.CancelledOrders), v.CancelledAmount)
+	pair(fmt.Sprintf("Item open bill dihapus (%d)", v.VoidedItems), v.VoidedItemsAmount)
+	pair(fmt.Sprintf("Refund (%d)", v.Refunds), v.RefundAmount)
+
+	section("Settlement kasir", 2)
+	if len(report.Settlements) == 0 {
+		line("-")
+	}
+	for _, st := range report.Settlements {
+		doc.KeepTogether(4, zReportFontSize)
+		status := "shift " + st.Status
+		if st.Settlement != nil {
+			status += ", settlement " + st.Settlement.Status
+		}
+		bold(fmt.Sprintf("%s (%s)", st.UserName, status))
+		pair("  Expected cash", st.ExpectedCash)
+		if st.Settlement != nil {
+			pair("  Uang fisik", st.Settlement.ActualCash)
+			pair("  Selisih", st.Settlement.Discrepancy)
+		} else {
+			pair("  Uang fisik", "belum settlement")
+		}
+	}
+
+	section("Menu terlaris", len(report.TopItems))
+	if len(report.TopItems) == 0 {
+		line("-")
+	}
+	for i, it := range report.TopItems {
+		pair(fmt.Sprintf("%2d. %s x%d", i+1, it.MenuName, it.Quantity), it.NetSales)
+	}
+
+	doc.Space(10)
+	doc.Rule()
+	if report.Number == nil {
+		line("DRAFT: angka masih bisa berubah sampai hari ditutup.")
+	} else {
+		line("Laporan sudah ditutup. Cetak ulang selalu menampilkan angka yang sama.")
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
@@ -0,0 +1,58 @@
+package utils
+
+import "time"
+
+// BusinessCalendar menentukan hari bisnis toko: zona waktu toko dan jam tutup buku (cutoff).
+// Dengan CutoffHour = 4, hari bisnis 2024-05-01 berlangsung 2024-05-01 04:00 s.d. 2024-05-02 04:00 waktu lokal,
+// sehingga pesanan pukul 01:00 masih masuk hari sebelumnya.
+type BusinessCalendar struct {
+	Location   *time.Location
+	CutoffHour int // 0-23, 0 = hari berganti tengah malam
+}
+
+func (c BusinessCalendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+// DayStart awal hari bisnis untuk tanggal kalender date (hanya tahun/bulan/tanggal yang dipakai)
+func (c BusinessCalendar) DayStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), c.CutoffHour, 0, 0, 0, c.location())
+}
+
+// DayRange rentang [start, end) hari bisnis untuk tanggal kalender date
+func (c BusinessCalendar) DayRange(date time.Time) (time.Time, time.Time) {
+	return c.DayStart(date), c.DayStart(date.AddDate(0, 0, 1))
+}
+
+// ParseDay membaca tanggal YYYY-MM-DD dan mengembalikan rentang [start, end) hari bisnisnya
+func (c BusinessCalendar) ParseDay(s string) (time.Time, time.Time, error) {
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start, end := c.DayRange(date)
+	return start, end, nil
+}
+
+// DateOf tanggal hari bisnis dari suatu waktu, sebagai tengah malam UTC (cocok untuk kolom date dan key YYYY-MM-DD)
+func (c BusinessCalendar) DateOf(t time.Time) time.Time {
+	local := t.In(c.location())
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	if local.Hour() < c.CutoffHour {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// Today tanggal hari bisnis saat ini
+func (c BusinessCalendar) Today() time.Time {
+	return c.DateOf(time.Now())
+}
+
+// Format waktu dalam zona toko (RFC3339, dengan offset lokal) untuk response dan struk
+func (c BusinessCalendar) Format(t time.Time) string {
+	return t.In(c.location()).Format(time.RFC3339)
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	zoneWIB  = time.FixedZone("WIB", 7*3600)
+	zoneWITA = time.FixedZone("WITA", 8*3600)
+	zoneWIT  = time.FixedZone("WIT", 9*3600)
+)
+
+func mustUTC(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestBusinessCalendarDateOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		cal    BusinessCalendar
+		at     string // instant UTC
+		wantDt string
+	}{
+		// cutoff 04:00: hari bisnis 2024-05-02 mulai 04:00 lokal
+		{"WIB sebelum cutoff", BusinessCalendar{zoneWIB, 4}, "2024-05-01T20:59:59.999999999Z", "2024-05-01"},
+		{"WIB tepat cutoff", BusinessCalendar{zoneWIB, 4}, "2024-05-01T21:00:00Z", "2024-05-02"},
+		{"WIB tengah malam lokal", BusinessCalendar{zoneWIB, 4}, "2024-05-01T17:30:00Z", "2024-05-01"},
+		{"WITA sebelum cutoff", BusinessCalendar{zoneWITA, 4}, "2024-05-01T19:59:59Z", "2024-05-01"},
+		{"WITA tepat cutoff", BusinessCalendar{zoneWITA, 4}, "2024-05-01T20:00:00Z", "2024-05-02"},
+		{"WIT sebelum cutoff", BusinessCalendar{zoneWIT, 4}, "2024-05-01T18:59:59Z", "2024-05-01"},
+		{"WIT tepat cutoff", BusinessCalendar{zoneWIT, 4}, "2024-05-01T19:00:00Z", "2024-05-02"},
+		// cutoff 0: hari berganti tengah malam lokal, bukan tengah malam UTC
+		{"WIB cutoff 0 sebelum", BusinessCalendar{zoneWIB, 0}, "2024-05-01T16:59:59Z", "2024-05-01"},
+		{"WIB cutoff 0 sesudah", BusinessCalendar{zoneWIB, 0}, "2024-05-01T17:00:00Z", "2024-05-02"},
+		{"WITA cutoff 0 sebelum", BusinessCalendar{zoneWITA, 0}, "2024-05-01T15:59:59Z", "2024-05-01"},
+		{"WITA cutoff 0 sesudah", BusinessCalendar{zoneWITA, 0}, "2024-05-01T16:00:00Z", "2024-05-02"},
+		{"WIT cutoff 0 sebelum", BusinessCalendar{zoneWIT, 0}, "2024-05-01T14:59:59Z", "2024-05-01"},
+		{"WIT cutoff 0 sesudah", BusinessCalendar{zoneWIT, 0}, "2024-05-01T15:00:00Z", "2024-05-02"},
+		// lewat batas bulan / tahun kabisat
+		{"WIT awal Maret sebelum cutoff", BusinessCalendar{zoneWIT, 4}, "2024-02-29T18:59:59Z", "2024-02-29"},
+		{"WIB tahun baru sebelum cutoff", BusinessCalendar{zoneWIB, 4}, "2024-12-31T20:59:59Z", "2024-12-31"},
+		{"WIB tahun baru sesudah cutoff", BusinessCalendar{zoneWIB, 4}, "2024-12-31T21:00:00Z", "2025-01-01"},
+		{"tanpa Location = UTC", BusinessCalendar{nil, 4}, "2024-05-02T03:59:59Z", "2024-05-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cal.DateOf(mustUTC(t, tt.at))
+			if s := got.Format("2006-01-02"); s != tt.wantDt {
+				t.Errorf("DateOf(%s) = %s, want %s", tt.at, s, tt.wantDt)
+			}
+			if got.Location() != time.UTC || got.Hour() != 0 || got.Minute() != 0 {
+				t.Errorf("DateOf(%s) = %v, want tengah malam UTC", tt.at, got)
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarParseDay(t *testing.T) {
+	tests := []struct {
+		name       string
+		cal        BusinessCalendar
+		day        string
+		start, end string // instant UTC
+	}{
+		{"WIB cutoff 4", BusinessCalendar{zoneWIB, 4}, "2024-05-01", "2024-04-30T21:00:00Z", "2024-05-01T21:00:00Z"},
+		{"WITA cutoff 4", BusinessCalendar{zoneWITA, 4}, "2024-05-01", "2024-04-30T20:00:00Z", "2024-05-01T20:00:00Z"},
+		{"WIT cutoff 4", BusinessCalendar{zoneWIT, 4}, "2024-05-01", "2024-04-30T19:00:00Z", "2024-05-01T19:00:00Z"},
+		{"WIB cutoff 0", BusinessCalendar{zoneWIB, 0}, "2024-05-01", "2024-04-30T17:00:00Z", "2024-05-01T17:00:00Z"},
+		{"WITA cutoff 0", BusinessCalendar{zoneWITA, 0}, "2024-05-01", "2024-04-30T16:00:00Z", "2024-05-01T16:00:00Z"},
+		{"WIT cutoff 0", BusinessCalendar{zoneWIT, 0}, "2024-05-01", "2024-04-30T15:00:00Z", "2024-05-01T15:00:00Z"},
+		{"WIB akhir Februari kabisat", BusinessCalendar{zoneWIB, 4}, "2024-02-29", "2024-02-28T21:00:00Z", "2024-02-29T21:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := tt.cal.ParseDay(tt.day)
+			if err != nil {
+				t.Fatalf("ParseDay(%q): %v", tt.day, err)
+			}
+			if want := mustUTC(t, tt.start); !start.Equal(want) {
+				t.Errorf("start = %v, want %v", start.UTC(), want)
+			}
+			if want := mustUTC(t, tt.end); !end.Equal(want) {
+				t.Errorf("end = %v, want %v", end.UTC(), want)
+			}
+
+			// rentang [start, end) harus persis satu hari bisnis menurut DateOf
+			if got := tt.cal.DateOf(start).Format("2006-01-02"); got != tt.day {
+				t.Errorf("DateOf(start) = %s, want %s", got, tt.day)
+			}
+			if got := tt.cal.DateOf(end.Add(-time.Nanosecond)).Format("2006-01-02"); got != tt.day {
+				t.Errorf("DateOf(end-1ns) = %s, want %s", got, tt.day)
+			}
+			if got := tt.cal.DateOf(end).Format("2006-01-02"); got == tt.day {
+				t.Errorf("DateOf(end) = %s, want hari berikutnya", got)
+			}
+			if got := tt.cal.DateOf(start.Add(-time.Nanosecond)).Format("2006-01-02"); got == tt.day {
+				t.Errorf("DateOf(start-1ns) = %s, want hari sebelumnya", got)
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarParseDayInvalid(t *testing.T) {
+	cal := BusinessCalendar{zoneWIB, 4}
+	for _, s := range []string{"", "2024-13-01", "2024-02-30", "01-05-2024", "2024-05-01T00:00:00Z"} {
+		if _, _, err := cal.ParseDay(s); err == nil {
+			t.Errorf("ParseDay(%q) tidak error", s)
+		}
+	}
+}
+
+func TestBusinessCalendarFormat(t *testing.T) {
+	at := mustUTC(t, "2024-05-01T21:30:00Z")
+	tests := []struct {
+		cal  BusinessCalendar
+		want string
+	}{
+		{BusinessCalendar{zoneWIB, 4}, "2024-05-02T04:30:00+07:00"},
+		{BusinessCalendar{zoneWITA, 4}, "2024-05-02T05:30:00+08:00"},
+		{BusinessCalendar{zoneWIT, 4}, "2024-05-02T06:30:00+09:00"},
+		{BusinessCalendar{nil, 4}, "2024-05-01T21:30:00Z"},
+	}
+	for _, tt := range tests {
+		if got := tt.cal.Format(at); got != tt.want {
+			t.Errorf("Format dengan %v = %s, want %s", tt.cal.location(), got, tt.want)
+		}
+	}
+}
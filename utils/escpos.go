@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lebar kertas printer thermal yang didukung (mm) beserta jumlah kolom font A dan lebar titik (dot) cetak
+var paperSizes = map[int]struct{ columns, dots int }{
+	58: {32, 384},
+	80: {48, 576},
+}
+
+// Perataan teks ESC/POS
+const (
+	AlignLeft   = 0
+	AlignCenter = 1
+	AlignRight  = 2
+)
+
+// ESCPOSWriter penyusun byte stream ESC/POS (Epson TM / kompatibel) untuk printer thermal 58mm / 80mm.
+// Teks dikirim sebagai ASCII (karakter lain jadi "?") agar aman di code page bawaan printer.
+type ESCPOSWriter struct {
+	buf     bytes.Buffer
+	columns int
+	dots    int
+	scale   int // lebar karakter saat ini (1 = normal, 2 = double width)
+}
+
+// PaperSupported true jika lebar kertas (mm) didukung
+func PaperSupported(widthMM int) bool {
+	_, ok := paperSizes[widthMM]
+	return ok
+}
+
+// NewESCPOSWriter writer untuk lebar kertas widthMM (58 atau 80; selain itu dianggap 58) dan langsung reset printer
+func NewESCPOSWriter(widthMM int) *ESCPOSWriter {
+	size, ok := paperSizes[widthMM]
+	if !ok {
+		size = paperSizes[58]
+	}
+	w := &ESCPOSWriter{columns: size.columns, dots: size.dots, scale: 1}
+	w.buf.Write([]byte{0x1B, 0x40}) // ESC @ initialize
+	return w
+}
+
+// Columns jumlah karakter per baris pada ukuran huruf saat ini
+func (w *ESCPOSWriter) Columns() int {
+	return w.columns / w.scale
+}
+
+// Align ESC a n
+func (w *ESCPOSWriter) Align(align int) {
+	w.buf.Write([]byte{0x1B, 0x61, byte(align)})
+}
+
+// Bold ESC E n
+func (w *ESCPOSWriter) Bold(on bool) {
+	n := byte(0)
+	if on {
+		n = 1
+	}
+	w.buf.Write([]byte{0x1B, 0x45, n})
+}
+
+// Size GS ! n — pembesaran lebar & tinggi karakter (1-8)
+func (w *ESCPOSWriter) Size(width, height int) {
+	width, height = clampInt(width, 1, 8), clampInt(height, 1, 8)
+	w.scale = width
+	w.buf.Write([]byte{0x1D, 0x21, byte((width-1)<<4 | (height - 1))})
+}
+
+// Line satu baris teks; teks yang lebih panjang dari lebar kertas dibungkus ke baris berikutnya
+func (w *ESCPOSWriter) Line(text string) {
+	for _, l := range wrapText(escposText(text), w.Columns()) {
+		w.buf.WriteString(l)
+		w.buf.WriteByte('\n')
+	}
+}
+
+// Columns2 baris dua kolom: left rata kiri, right rata kanan. Jika tidak muat, left dibungkus dan right di baris terakhir.
+func (w *ESCPOSWriter) Columns2(left, right string) {
+	left, right = escposText(left), escposText(right)
+	cols := w.Columns()
+	lines := wrapText(left, cols-len(right)-1)
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	for _, l := range lines[:len(lines)-1] {
+		w.buf.WriteString(l)
+		w.buf.WriteByte('\n')
+	}
+	last := lines[len(lines)-1]
+	w.buf.WriteString(last + strings.Repeat(" ", max(1, cols-len(last)-len(right))) + right)
+	w.buf.WriteByte('\n')
+}
+
+// Rule garis pemisah selebar kertas
+func (w *ESCPOSWriter) Rule(ch byte) {
+	w.buf.WriteString(strings.Repeat(string(ch), w.Columns()))
+	w.buf.WriteByte('\n')
+}
+
+// Feed ESC d n — maju n baris
+func (w *ESCPOSWriter) Feed(lines int) {
+	w.buf.Write([]byte{0x1B, 0x64, byte(clampInt(lines, 0, 255))})
+}
+
+// Cut GS V 66 0 — maju kertas sampai posisi pisau lalu potong sebagian
+func (w *ESCPOSWriter) Cut() {
+	w.buf.Write([]byte{0x1D, 0x56, 0x42, 0x00})
+}
+
+// QR GS ( k — QR code model 2, error correction M; moduleSize 1-16 titik per modul
+func (w *ESCPOSWriter) QR(data string, moduleSize int) {
+	if data == "" || len(data) > 7000 {
+		return
+	}
+	w.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})                        // model 2
+	w.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, byte(clampInt(moduleSize, 1, 16))}) // ukuran modul
+	w.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})                              // error correction M
+	n := len(data) + 3
+	w.buf.Write([]byte{0x1D, 0x28, 0x6B, byte(n & 0xFF), byte(n >> 8), 0x31, 0x50, 0x30}) // simpan data
+	w.buf.WriteString(data)
+	w.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30}) // cetak
+	w.buf.WriteByte('\n')
+}
+
+// Image GS v 0 — cetak gambar raster hitam-putih (threshold luminance 50%), diperkecil jika lebih lebar dari kertas
+func (w *ESCPOSWriter) Image(img image.Image) {
+	if img == nil {
+		return
+	}
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW == 0 || srcH == 0 {
+		return
+	}
+	dstW, dstH := srcW, srcH
+	if dstW > w.dots {
+		dstW = w.dots
+		dstH = srcH * dstW / srcW
+	}
+	if dstH > 0xFFFF {
+		dstH = 0xFFFF
+	}
+	rowBytes := (dstW + 7) / 8
+
+	w.buf.Write([]byte{0x1D, 0x76, 0x30, 0x00, byte(rowBytes & 0xFF), byte(rowBytes >> 8), byte(dstH & 0xFF), byte(dstH >> 8)})
+	row := make([]byte, rowBytes)
+	for y := 0; y < dstH; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		sy := b.Min.Y + y*srcH/dstH
+		for x := 0; x < dstW; x++ {
+			sx := b.Min.X + x*srcW/dstW
+			if isDark(img.At(sx, sy)) {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		w.buf.Write(row)
+	}
+	w.buf.WriteByte('\n')
+}
+
+// Bytes isi byte stream
+func (w *ESCPOSWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// isDark piksel dianggap hitam jika gelap dan tidak transparan
+func isDark(c color.Color) bool {
+	g := color.Gray16Model.Convert(c).(color.Gray16)
+	_, _, _, a := c.RGBA()
+	return a > 0x7FFF && g.Y < 0x7FFF
+}
+
+// escposText ubah ke ASCII yang bisa dicetak; karakter kontrol dibuang, non-ASCII jadi "?"
+func escposText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r == 0x7F:
+		case r >= utf8.RuneSelf:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// wrapText bungkus teks per kata ke lebar width (kata yang lebih panjang dipotong). Spasi di awal baris dipertahankan
+// sebagai indentasi, juga untuk baris lanjutan.
+func wrapText(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		body := strings.TrimLeft(para, " ")
+		indent := para[:len(para)-len(body)]
+		if len(indent) >= width {
+			indent = ""
+		}
+		inner := width - len(indent)
+		line := ""
+		for _, word := range strings.Fields(body) {
+			for len(word) > inner {
+				if line != "" {
+					lines = append(lines, indent+line)
+					line = ""
+				}
+				lines = append(lines, indent+word[:inner])
+				word = word[inner:]
+			}
+			switch {
+			case line == "":
+				line = word
+			case len(line)+1+len(word) <= inner:
+				line += " " + word
+			default:
+				lines = append(lines, indent+line)
+				line = word
+			}
+		}
+		lines = append(lines, indent+line)
+	}
+	return lines
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
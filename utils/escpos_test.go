@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"Nasi Goreng Spesial", 32, []string{"Nasi Goreng Spesial"}},
+		{"Es Kopi Susu Gula Aren", 10, []string{"Es Kopi", "Susu Gula", "Aren"}},
+		{"  + Telur Mata Sapi", 12, []string{"  + Telur", "  Mata Sapi"}},
+		{"ABCDEFGHIJKL", 5, []string{"ABCDE", "FGHIJ", "KL"}},
+		{"  ABCDEFGH", 5, []string{"  ABC", "  DEF", "  GH"}},
+		{"baris 1\nbaris 2", 32, []string{"baris 1", "baris 2"}},
+		{"", 32, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.in, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
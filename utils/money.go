@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money nominal uang dalam rupiah utuh (integer, tanpa sen).
+//
+// Semua perhitungan uang (harga * qty, diskon, pajak, refund, settlement) memakai Money agar hasilnya
+// exact: jumlah baris selalu sama dengan total, dan nilai yang dikirim ke Midtrans (gross_amount / item_details)
+// tidak perlu dipotong dari float.
+//
+// Aturan pembulatan: setiap kali ada pecahan rupiah (persentase, pembagian proporsional, input desimal),
+// dibulatkan ke rupiah terdekat dengan half-up menjauhi nol (0,5 -> 1; -0,5 -> -1), sama dengan math.Round.
+// Pembulatan hanya terjadi di fungsi-fungsi pada file ini sehingga mudah ditelusuri.
+//
+// Kolom database tetap decimal(15,2); nilai yang tersimpan selalu bulat (.00).
+type Money int64
+
+// RoundMoney mengubah nilai float (mis. dari perhitungan rate) ke Money dengan pembulatan half-up.
+func RoundMoney(v float64) Money {
+	return Money(math.Round(v))
+}
+
+// ParseMoney membaca angka desimal ("15000", "15000.50", "-2500.5") secara exact lalu membulatkan half-up.
+// NaN, Inf, dan nilai di luar jangkauan int64 ditolak.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("money: nilai kosong")
+	}
+	neg := false
+	body := s
+	switch body[0] {
+	case '-':
+		neg = true
+		body = body[1:]
+	case '+':
+		body = body[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(body, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || strings.ContainsAny(fracPart, "+-eE") {
+		// Format lain (mis. eksponen 1.5e4): lewat float lalu dibulatkan
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("money: %q bukan angka", s)
+		}
+		f = math.Round(f)
+		if f >= math.MaxInt64 || f < math.MinInt64 {
+			return 0, fmt.Errorf("money: %q di luar jangkauan", s)
+		}
+		return Money(f), nil
+	}
+	for _, r := range fracPart {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("money: %q bukan angka", s)
+		}
+	}
+	if fracPart != "" && fracPart[0] >= '5' {
+		if n == math.MaxInt64 {
+			return 0, fmt.Errorf("money: %q di luar jangkauan", s)
+		}
+		n++
+	}
+	if neg {
+		n = -n
+	}
+	return Money(n), nil
+}
+
+// Int64 nilai rupiah sebagai int64 (mis. untuk gross_amount Midtrans)
+func (m Money) Int64() int64 {
+	return int64(m)
+}
+
+// Float64 nilai rupiah sebagai float64 (hanya untuk tampilan / grafik)
+func (m Money) Float64() float64 {
+	return float64(m)
+}
+
+// Mul harga * qty
+func (m Money) Mul(qty int) Money {
+	return m * Money(qty)
+}
+
+// MulRate menghitung m * rate / 100 (rate dalam persen, presisi 4 desimal), dibulatkan half-up.
+func (m Money) MulRate(rate float64) Money {
+	bp := rateBasisPoints(rate)
+	return mulDivRound(m, bp, 100*10000)
+}
+
+// ExtractRate menghitung porsi pajak inklusif dari m: m * rate / (100 + rate), dibulatkan half-up.
+func (m Money) ExtractRate(rate float64) Money {
+	bp := rateBasisPoints(rate)
+	return mulDivRound(m, bp, 100*10000+bp)
+}
+
+// MulDiv menghitung m * num / den, dibulatkan half-up (mis. porsi proporsional). den harus > 0.
+func (m Money) MulDiv(num, den Money) Money {
+	return mulDivRound(m, int64(num), int64(den))
+}
+
+// Allocate membagi total ke beberapa bagian secara proporsional terhadap weights (largest remainder).
+// Jumlah hasil selalu sama persis dengan total dan setiap bagian berselisih < 1 rupiah dari nilai idealnya.
+// Bobot <= 0 mendapat 0; jika semua bobot <= 0, seluruh total tidak teralokasi (semua 0).
+func Allocate(total Money, weights []Money) []Money {
+	shares := make([]Money, len(weights))
+	var sumW int64
+	for _, w := range weights {
+		if w > 0 {
+			sumW += int64(w)
+		}
+	}
+	if sumW == 0 || total == 0 {
+		return shares
+	}
+
+	sign := int64(1)
+	t := int64(total)
+	if t < 0 {
+		sign, t = -1, -t
+	}
+
+	tBig, sumBig := big.NewInt(t), big.NewInt(sumW)
+	remainders := make([]int64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		if w <= 0 {
+			remainders[i] = -1
+			continue
+		}
+		q, r := new(big.Int).QuoRem(new(big.Int).Mul(tBig, big.NewInt(int64(w))), sumBig, new(big.Int))
+		shares[i] = Money(q.Int64())
+		remainders[i] = r.Int64()
+		allocated += q.Int64()
+	}
+
+	// Sisa (< jumlah bagian) diberikan 1 rupiah ke bagian dengan sisa pembagian terbesar; seri -> index terkecil
+	for left := t - allocated; left > 0; left-- {
+		best := -1
+		for i, r := range remainders {
+			if r >= 0 && (best < 0 || r > remainders[best]) {
+				best = i
+			}
+		}
+		shares[best]++
+		remainders[best] = -1
+	}
+
+	if sign < 0 {
+		for i := range shares {
+			shares[i] = -shares[i]
+		}
+	}
+	return shares
+}
+
+// String format rupiah tanpa pemisah ribuan (mis. "15000")
+func (m Money) String() string {
+	return strconv.FormatInt(int64(m), 10)
+}
+
+// Rupiah format tampilan "Rp 15.000" (pemisah ribuan titik) untuk struk dan dokumen cetak
+func (m Money) Rupiah() string {
+	s := strconv.FormatInt(int64(m), 10)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+// Value menyimpan Money ke kolom decimal
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan membaca kolom decimal / numeric / integer; pecahan (data lama) dibulatkan half-up.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = RoundMoney(v)
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	default:
+		return fmt.Errorf("money: tipe %T tidak didukung", src)
+	}
+	return nil
+}
+
+// MarshalJSON menulis Money sebagai angka bulat
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON menerima angka (boleh desimal) atau string angka
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam dipakai gin saat binding form / query (mis. price pada multipart form menu)
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := ParseMoney(param)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// rateBasisPoints mengubah persen (maks 4 desimal, sesuai kolom decimal(7,4)) ke satuan 1/10000 persen
+func rateBasisPoints(rate float64) int64 {
+	return int64(math.Round(rate * 10000))
+}
+
+// mulDivRound menghitung a * num / den dengan pembulatan half-up menjauhi nol, tanpa overflow.
+func mulDivRound(a Money, num, den int64) Money {
+	if den == 0 {
+		return 0
+	}
+	prod := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	d := big.NewInt(den)
+	neg := (prod.Sign() < 0) != (d.Sign() < 0)
+	prod.Abs(prod)
+	d.Abs(d)
+
+	q, r := new(big.Int).QuoRem(prod, d, new(big.Int))
+	// half-up: sisa*2 >= pembagi -> naik
+	if r.Mul(r, big.NewInt(2)).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return Money(q.Int64())
+}
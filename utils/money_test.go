@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   Money
+		weights []Money
+		want    []Money
+	}{
+		{"sisa ke index terkecil", 100, []Money{1, 1, 1}, []Money{34, 33, 33}},
+		{"total negatif", -100, []Money{1, 1, 1}, []Money{-34, -33, -33}},
+		{"proporsional", 1000, []Money{3000, 1000}, []Money{750, 250}},
+		{"sisa terbesar", 10, []Money{1, 2, 4}, []Money{1, 3, 6}},
+		{"bobot nol & negatif dapat 0", 10, []Money{0, 5, -3, 5}, []Money{0, 5, 0, 5}},
+		{"semua bobot nol", 10, []Money{0, 0}, []Money{0, 0}},
+		{"total nol", 0, []Money{1, 2}, []Money{0, 0}},
+		{"tanpa bobot", 10, nil, []Money{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allocate(tt.total, tt.weights); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAllocateProperties jumlah porsi selalu sama persis dengan total (juga untuk total negatif dan bobot nol),
+// bobot <= 0 selalu 0, dan tiap porsi berselisih < 1 rupiah dari nilai idealnya
+func TestAllocateProperties(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		total := Money(rng.Int63n(2_000_000_001) - 1_000_000_000)
+		weights := make([]Money, rng.Intn(9))
+		var sumW int64
+		for j := range weights {
+			switch rng.Intn(4) {
+			case 0:
+				weights[j] = 0
+			case 1:
+				weights[j] = -Money(rng.Int63n(1000))
+			default:
+				weights[j] = Money(rng.Int63n(5_000_000) + 1)
+				sumW += int64(weights[j])
+			}
+		}
+
+		shares := Allocate(total, weights)
+		if len(shares) != len(weights) {
+			t.Fatalf("Allocate(%d, %v): %d porsi, want %d", total, weights, len(shares), len(weights))
+		}
+		var sum Money
+		for j, s := range shares {
+			sum += s
+			if weights[j] <= 0 && s != 0 {
+				t.Fatalf("Allocate(%d, %v)[%d] = %d, bobot <= 0 harus 0", total, weights, j, s)
+			}
+			if weights[j] > 0 {
+				// |s - total*w/sumW| < 1  <=>  |s*sumW - total*w| < sumW
+				diff := int64(s)*sumW - int64(total)*int64(weights[j])
+				if diff < 0 {
+					diff = -diff
+				}
+				if diff >= sumW {
+					t.Fatalf("Allocate(%d, %v)[%d] = %d, terlalu jauh dari nilai ideal", total, weights, j, s)
+				}
+			}
+		}
+		want := total
+		if sumW == 0 {
+			want = 0
+		}
+		if sum != want {
+			t.Fatalf("sum(Allocate(%d, %v)) = %d, want %d", total, weights, sum, want)
+		}
+	}
+}
+
+func TestMulRate(t *testing.T) {
+	tests := []struct {
+		m    Money
+		rate float64
+		want Money
+	}{
+		{1000, 10, 100},
+		{15, 10, 2},  // 1,5 -> 2
+		{5, 10, 1},   // 0,5 -> 1
+		{14, 10, 1},  // 1,4 -> 1
+		{-5, 10, -1}, // -0,5 -> -1 (menjauhi nol)
+		{-14, 10, -1},
+		{12345, 11, 1358}, // 1357,95
+		{100, 2.5, 3},     // 2,5
+		{99, 2.5, 2},      // 2,475
+		{10000, 12.3456, 1235},
+		{1, 0.0001, 0},
+		{0, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.MulRate(tt.rate); got != tt.want {
+			t.Errorf("Money(%d).MulRate(%v) = %d, want %d", tt.m, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestExtractRate(t *testing.T) {
+	tests := []struct {
+		m    Money
+		rate float64
+		want Money
+	}{
+		{11000, 10, 1000},
+		{110, 10, 10},
+		{105, 10, 10}, // 9,545
+		{16, 10, 1},   // 1,4545
+		{17, 10, 2},   // 1,5454
+		{-17, 10, -2},
+		{111, 11, 11},
+		{0, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.m.ExtractRate(tt.rate); got != tt.want {
+			t.Errorf("Money(%d).ExtractRate(%v) = %d, want %d", tt.m, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{in: "15000", want: 15000},
+		{in: "  15000 ", want: 15000},
+		{in: "15000.50", want: 15001},
+		{in: "15000.49", want: 15000},
+		{in: "15000.4999999", want: 15000},
+		{in: "-2500.5", want: -2501},
+		{in: "-2500.4", want: -2500},
+		{in: "+7", want: 7},
+		{in: ".5", want: 1},
+		{in: "12.", want: 12},
+		{in: "0.4999", want: 0},
+		{in: "1.5e4", want: 15000},
+		{in: "9223372036854775807", want: 9223372036854775807},
+		{in: "-9223372036854775807", want: -9223372036854775807},
+		{in: "-9.2e18", want: -9200000000000000000},
+		{in: "", wantErr: true},
+		{in: "   ", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "12.3a", wantErr: true},
+		{in: "Rp 1000", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "Inf", wantErr: true},
+		{in: "-Infinity", wantErr: true},
+		{in: "1e19", wantErr: true},
+		{in: "-1e19", wantErr: true},
+		{in: "1e400", wantErr: true},
+		{in: "9223372036854775808", wantErr: true},
+		{in: "9223372036854775807.5", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
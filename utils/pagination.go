@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageQuery parameter paginasi + sorting dari query string (?page=&limit=&sort_by=&sort_dir=)
+type PageQuery struct {
+	Page    int
+	Limit   int
+	SortBy  string // nama kolom (sudah divalidasi whitelist)
+	SortDir string // asc | desc
+}
+
+// PageMeta metadata paginasi pada response
+type PageMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// PagedData bentuk response list yang dipaginasi
+type PagedData struct {
+	Items      interface{} `json:"items"`
+	Pagination PageMeta    `json:"pagination"`
+}
+
+// ParsePageQuery membaca page/limit/sort_by/sort_dir dari query.
+// sortColumns memetakan nilai sort_by yang diizinkan -> nama kolom (whitelist agar aman dari SQL injection).
+// Nilai sort_by di luar whitelist diganti defaultSort.
+func ParsePageQuery(c *gin.Context, sortColumns map[string]string, defaultSort string) PageQuery {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	sortBy, ok := sortColumns[c.Query("sort_by")]
+	if !ok {
+		sortBy = sortColumns[defaultSort]
+	}
+	sortDir := "desc"
+	if strings.EqualFold(c.Query("sort_dir"), "asc") {
+		sortDir = "asc"
+	}
+
+	return PageQuery{Page: page, Limit: limit, SortBy: sortBy, SortDir: sortDir}
+}
+
+// Offset untuk query OFFSET
+func (q PageQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// Paginate menghitung total baris dari query (sudah berisi filter) lalu mengambil satu halaman ke dest.
+// Query db harus sudah memakai Model(...) agar Count bisa berjalan; relasi yang perlu di-preload dikirim lewat preloads
+// (Preload tidak boleh dipasang sebelum Count).
+func Paginate(db *gorm.DB, q PageQuery, dest interface{}, preloads ...string) (int64, error) {
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	query := db.Session(&gorm.Session{})
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+	if q.SortBy != "" {
+		query = query.Order(q.SortBy + " " + q.SortDir)
+	}
+	if err := query.Offset(q.Offset()).Limit(q.Limit).Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// NewPagedData membungkus items + metadata paginasi untuk response
+func NewPagedData(items interface{}, q PageQuery, total int64) PagedData {
+	return PagedData{
+		Items: items,
+		Pagination: PageMeta{
+			Page:       q.Page,
+			Limit:      q.Limit,
+			Total:      total,
+			TotalPages: int(math.Ceil(float64(total) / float64(q.Limit))),
+		},
+	}
+}
+
+// likeEscaper meloloskan karakter khusus pola LIKE (escape default PostgreSQL adalah backslash)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern pola LIKE "mengandung" untuk kata kunci pencarian (huruf kecil, dibandingkan dengan LOWER(kolom)).
+// % dan _ dari input pengguna dicocokkan apa adanya, bukan sebagai wildcard.
+func ContainsPattern(search string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(search)) + "%"
+}
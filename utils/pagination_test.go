@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Budi", "%budi%"},
+		{"50%", `%50\%%`},
+		{"es_teh", `%es\_teh%`},
+		{`a\b`, `%a\\b%`},
+		{"", "%%"},
+	}
+	for _, tt := range tests {
+		if got := ContainsPattern(tt.in); got != tt.want {
+			t.Errorf("ContainsPattern(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ukuran halaman A4 dan margin dalam point (1/72 inci)
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 40.0
+	pdfFooterSize = 8.0
+)
+
+// PDFDocument penulis PDF teks minimal tanpa dependensi: halaman A4, font bawaan Courier / Courier-Bold
+// (monospace, sehingga kolom bisa dirapikan dengan padding spasi), garis pemisah, dan nomor halaman otomatis.
+// Teks di luar Latin-1 diganti "?" karena font bawaan hanya mendukung WinAnsiEncoding.
+type PDFDocument struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64 // posisi baris berikutnya dari bawah halaman
+}
+
+// NewPDFDocument dokumen kosong; title masuk metadata dan footer setiap halaman
+func NewPDFDocument(title string) *PDFDocument {
+	d := &PDFDocument{title: title}
+	d.newPage()
+	return d
+}
+
+// PDFColumns lebar karakter yang muat satu baris untuk ukuran font tertentu (Courier: lebar glyph 0.6 x ukuran)
+func PDFColumns(size float64) int {
+	return int((pdfPageWidth - 2*pdfMargin) / (0.6 * size))
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure pindah halaman jika sisa ruang kurang dari h
+func (d *PDFDocument) ensure(h float64) {
+	if d.y-h < pdfMargin+2*pdfFooterSize {
+		d.newPage()
+	}
+}
+
+// Text menulis satu baris teks; baris yang lebih panjang dari lebar halaman dipotong
+func (d *PDFDocument) Text(text string, size float64, bold bool) {
+	lineHeight := size * 1.35
+	d.ensure(lineHeight)
+	d.y -= lineHeight
+	if r := []rune(text); len(r) > PDFColumns(size) {
+		text = string(r[:PDFColumns(size)])
+	}
+	d.writeText(d.pages[len(d.pages)-1], pdfMargin, d.y, text, size, bold)
+}
+
+// Rule garis horizontal selebar area tulis
+func (d *PDFDocument) Rule() {
+	d.ensure(8)
+	d.y -= 4
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, d.y, pdfPageWidth-pdfMargin, d.y)
+	d.y -= 4
+}
+
+// Space jarak vertikal kosong
+func (d *PDFDocument) Space(h float64) {
+	d.ensure(h)
+	d.y -= h
+}
+
+// KeepTogether pindah halaman lebih dulu jika n baris berukuran size tidak muat di halaman ini
+func (d *PDFDocument) KeepTogether(n int, size float64) {
+	d.ensure(float64(n) * size * 1.35)
+}
+
+func (d *PDFDocument) writeText(buf *bytes.Buffer, x, y float64, text string, size float64, bold bool) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(buf, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(text))
+}
+
+// WriteTo menulis file PDF lengkap (xref dihitung dari offset setiap objek)
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	offsets := []int{0}
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, body)
+	}
+
+	n := len(d.pages)
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// 1 catalog, 2 pages, 3-4 font, 5 info, lalu (page, content) per halaman
+	kids := make([]string, n)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (pos-go) >>", pdfEscape(d.title)))
+	for i, page := range d.pages {
+		content := bytes.NewBuffer(append([]byte{}, page.Bytes()...))
+		footer := fmt.Sprintf("%s - Halaman %d/%d", d.title, i+1, n)
+		d.writeText(content, pdfMargin, pdfMargin, footer, pdfFooterSize, false)
+
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 7+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+	return out.WriteTo(w)
+}
+
+// pdfEscape string literal PDF dalam WinAnsi (Latin-1); karakter lain jadi "?"
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
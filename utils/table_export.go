@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format ekspor yang didukung (query format=)
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// ErrUnknownExportFormat format ekspor selain csv / xlsx
+var ErrUnknownExportFormat = errors.New("Format ekspor tidak dikenal. Gunakan csv atau xlsx")
+
+// TableWriter penulis tabel streaming: baris langsung ditulis ke io.Writer tanpa ditampung di memori.
+// Satu ekspor bisa berisi beberapa sheet (mis. transaksi + rincian item); CSV hanya memuat satu sheet.
+type TableWriter interface {
+	// Accepts false jika sheet tidak ikut ditulis (CSV dengan sheet lain yang dipilih), sehingga query-nya bisa dilewati
+	Accepts(sheet string) bool
+	// NewSheet memulai sheet baru dengan baris header; sheet sebelumnya otomatis ditutup
+	NewSheet(sheet string, header []string) error
+	// WriteRow menulis satu baris ke sheet aktif. Nilai: string, angka, bool, Money, *string, nil.
+	WriteRow(values ...interface{}) error
+	// Close menyelesaikan file (wajib dipanggil sekali setelah baris terakhir)
+	Close() error
+}
+
+// NewTableWriter TableWriter dan content type untuk format ekspor.
+// sheet hanya berlaku untuk CSV: nama sheet yang diekspor (kosong = sheet pertama).
+func NewTableWriter(format string, w io.Writer, sheet string) (TableWriter, string, error) {
+	switch format {
+	case ExportFormatCSV:
+		return NewCSVTableWriter(w, sheet), "text/csv; charset=utf-8", nil
+	case ExportFormatXLSX:
+		return NewXLSXWriter(w), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return nil, "", ErrUnknownExportFormat
+}
+
+// CSVTableWriter TableWriter CSV (UTF-8 dengan BOM agar terbaca benar di Excel). Hanya satu sheet yang ditulis.
+type CSVTableWriter struct {
+	w       *csv.Writer
+	out     *bufio.Writer
+	sheet   string // sheet yang dipilih; kosong = sheet pertama yang dibuat
+	active  bool
+	written bool
+}
+
+// NewCSVTableWriter CSV untuk sheet tertentu (kosong = sheet pertama)
+func NewCSVTableWriter(w io.Writer, sheet string) *CSVTableWriter {
+	out := bufio.NewWriter(w)
+	return &CSVTableWriter{w: csv.NewWriter(out), out: out, sheet: sheet}
+}
+
+// Accepts true hanya untuk sheet yang dipilih (atau sheet pertama jika tidak memilih)
+func (c *CSVTableWriter) Accepts(sheet string) bool {
+	if c.sheet == "" {
+		return !c.written
+	}
+	return sheet == c.sheet && !c.written
+}
+
+// NewSheet menulis header jika sheet dipilih; baris sheet lain diabaikan
+func (c *CSVTableWriter) NewSheet(sheet string, header []string) error {
+	c.w.Flush()
+	c.active = c.Accepts(sheet)
+	if !c.active {
+		return nil
+	}
+	c.written = true
+	if _, err := c.out.WriteString("\uFEFF"); err != nil {
+		return err
+	}
+	return c.w.Write(header)
+}
+
+// WriteRow menulis satu baris (diabaikan jika sheet aktif tidak dipilih)
+func (c *CSVTableWriter) WriteRow(values ...interface{}) error {
+	if !c.active {
+		return nil
+	}
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = cellText(v)
+	}
+	return c.w.Write(record)
+}
+
+// Close flush sisa buffer
+func (c *CSVTableWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.out.Flush()
+}
+
+// cellText representasi teks nilai sel (CSV dan sel string XLSX). Teks diamankan dengan escapeFormula;
+// angka (termasuk Money negatif) ditulis apa adanya.
+func cellText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(x)
+	case *string:
+		if x == nil {
+			return ""
+		}
+		return escapeFormula(*x)
+	case Money:
+		return x.String()
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		if x {
+			return "true"
+		}
+		return "false"
+	case fmt.Stringer:
+		return escapeFormula(x.String())
+	}
+	return escapeFormula(fmt.Sprint(v))
+}
+
+// escapeFormula mencegah formula injection: teks dari input pengguna (nama pelanggan, catatan) yang diawali
+// = + - @ tab atau CR akan dijalankan sebagai formula oleh Excel, jadi diberi awalan ' agar dibaca sebagai teks.
+// Teks satu karakter (mis. placeholder "-" untuk nama kosong) tidak bisa menjadi formula sehingga dibiarkan.
+func escapeFormula(s string) string {
+	if len(s) > 1 && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
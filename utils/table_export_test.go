@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "tulis ulang file golden di testdata/")
+
+// assertGolden membandingkan got byte per byte dengan testdata/name (atau menulisnya dengan -update)
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v (jalankan go test -run %s -update)", path, err, t.Name())
+	}
+	if !bytes.Equal(got, want) {
+		i := 0
+		for i < len(got) && i < len(want) && got[i] == want[i] {
+			i++
+		}
+		t.Fatalf("%s: output berbeda mulai byte %d (panjang %d, golden %d)", name, i, len(got), len(want))
+	}
+}
+
+// writeExportFixture dua sheet berisi tipe nilai yang didukung dan teks berbahaya dari input pengguna
+func writeExportFixture(t *testing.T, tw TableWriter) {
+	t.Helper()
+	note := "-tanpa es"
+	rows := [][]interface{}{
+		{"TRX-001", "Budi", Money(125000), 2, true, &note},
+		{"TRX-002", "=HYPERLINK(\"http://evil\",\"klik\")", Money(-5000), int64(1), false, nil},
+		{"TRX-003", "+62812345678", Money(0), 0, true, "@SUM(A1:A9)"},
+		{"TRX-004", "\tTab", Money(7500), 3, false, "\rCR"},
+		{"TRX-005", "-", Money(0), 0, false, "="},
+		{"TRX-006", "Kopi \"Susu\", <Aren> & ☕", Money(18000), 1, true, "baris 1\nbaris 2\x01"},
+	}
+	if err := tw.NewSheet("Transaksi", []string{"ID", "Pelanggan", "Total", "Jumlah", "Lunas", "Catatan"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if err := tw.WriteRow(r...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tw.Accepts("Item") {
+		if err := tw.NewSheet("Item", []string{"ID Transaksi", "Menu", "Qty", "Subtotal"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.WriteRow("TRX-001", "Nasi Goreng", 2, Money(50000)); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.WriteRow("TRX-002", "-Es Teh", 1, float64(2.5)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCSVTableWriterGolden(t *testing.T) {
+	tests := []struct {
+		sheet, golden string
+	}{
+		{"", "export_transaksi.csv"},
+		{"Item", "export_item.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeExportFixture(t, NewCSVTableWriter(&buf, tt.sheet))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestXLSXWriterGolden(t *testing.T) {
+	var buf bytes.Buffer
+	writeExportFixture(t, NewXLSXWriter(&buf))
+	assertGolden(t, "export.xlsx", buf.Bytes())
+
+	// isi sheet juga disimpan sebagai XML agar perubahan mudah dibaca di diff
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" && f.Name != "xl/worksheets/sheet2.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, "export_"+filepath.Base(f.Name), body)
+	}
+}
+
+func TestCellTextEscapesFormula(t *testing.T) {
+	s := "=1+1"
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{"=SUM(A1)", "'=SUM(A1)"},
+		{"+62812", "'+62812"},
+		{"-tanpa es", "'-tanpa es"},
+		{"@cmd", "'@cmd"},
+		{"\tx", "'\tx"},
+		{"\rx", "'\rx"},
+		{&s, "'=1+1"},
+		{"Budi = teman", "Budi = teman"},
+		{"", ""},
+		{"-", "-"},
+		{Money(-5000), "-5000"},
+		{-3, "-3"},
+		{int64(-7), "-7"},
+		{float64(-1.5), "-1.5"},
+	}
+	for _, tt := range tests {
+		if got := cellText(tt.in); got != tt.want {
+			t.Errorf("cellText(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
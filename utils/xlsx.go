@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrXLSXNoSheet WriteRow dipanggil sebelum NewSheet
+var ErrXLSXNoSheet = errors.New("xlsx: belum ada sheet aktif")
+
+// XLSXWriter encoder XLSX (Office Open XML) minimal tanpa dependensi: setiap sheet ditulis langsung ke entri zip
+// sehingga ukuran memori tidak bergantung jumlah baris. String ditulis inline (tanpa sharedStrings) dan
+// workbook / content types ditulis saat Close karena daftar sheet baru diketahui di akhir.
+type XLSXWriter struct {
+	zw     *zip.Writer
+	sheets []string
+	cur    *bufio.Writer
+	row    int
+	closed bool
+}
+
+// NewXLSXWriter XLSXWriter yang menulis ke w
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zw: zip.NewWriter(w)}
+}
+
+// Accepts XLSX memuat semua sheet
+func (x *XLSXWriter) Accepts(sheet string) bool {
+	return true
+}
+
+// NewSheet menutup sheet aktif, membuka worksheet baru dan menulis header (baris tebal, dibekukan)
+func (x *XLSXWriter) NewSheet(sheet string, header []string) error {
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+	x.sheets = append(x.sheets, xlsxSheetName(sheet, len(x.sheets)+1))
+	f, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return err
+	}
+	x.cur = bufio.NewWriter(f)
+	x.row = 0
+	x.cur.WriteString(xml.Header)
+	x.cur.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	x.cur.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	x.cur.WriteString(`<sheetData>`)
+
+	values := make([]interface{}, len(header))
+	for i, h := range header {
+		values[i] = h
+	}
+	return x.writeRow(values, 1)
+}
+
+// WriteRow menulis satu baris ke sheet aktif
+func (x *XLSXWriter) WriteRow(values ...interface{}) error {
+	return x.writeRow(values, 0)
+}
+
+func (x *XLSXWriter) writeRow(values []interface{}, style int) error {
+	if x.cur == nil {
+		return ErrXLSXNoSheet
+	}
+	x.row++
+	fmt.Fprintf(x.cur, `<row r="%d">`, x.row)
+	for i, v := range values {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		styleAttr := ""
+		if style > 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		if num, ok := xlsxNumber(v); ok {
+			fmt.Fprintf(x.cur, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, num)
+			continue
+		}
+		if b, ok := v.(bool); ok {
+			n := 0
+			if b {
+				n = 1
+			}
+			fmt.Fprintf(x.cur, `<c r="%s"%s t="b"><v>%d</v></c>`, ref, styleAttr, n)
+			continue
+		}
+		text := cellText(v)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(x.cur, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, styleAttr)
+		if err := xml.EscapeText(x.cur, []byte(xlsxCleanText(text))); err != nil {
+			return err
+		}
+		x.cur.WriteString(`</t></is></c>`)
+	}
+	_, err := x.cur.WriteString(`</row>`)
+	return err
+}
+
+func (x *XLSXWriter) endSheet() error {
+	if x.cur == nil {
+		return nil
+	}
+	x.cur.WriteString(`</sheetData></worksheet>`)
+	err := x.cur.Flush()
+	x.cur = nil
+	return err
+}
+
+// Close menutup sheet terakhir lalu menulis workbook, styles, relasi dan content types
+func (x *XLSXWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+	if err := x.endSheet(); err != nil {
+		return err
+	}
+	if len(x.sheets) == 0 {
+		// workbook wajib punya minimal satu sheet
+		if err := x.NewSheet("Sheet1", nil); err != nil {
+			return err
+		}
+		if err := x.endSheet(); err != nil {
+			return err
+		}
+	}
+
+	var workbook, workbookRels, contentTypes strings.Builder
+	workbook.WriteString(xml.Header)
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header)
+	workbookRels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	contentTypes.WriteString(xml.Header)
+	contentTypes.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	contentTypes.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	contentTypes.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	contentTypes.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	contentTypes.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i, name := range x.sheets {
+		n := i + 1
+		workbook.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxAttr(name), n, n))
+		workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n))
+		contentTypes.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n))
+	}
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(x.sheets)+1))
+	workbookRels.WriteString(`</Relationships>`)
+	contentTypes.WriteString(`</Types>`)
+
+	parts := []struct{ name, body string }{
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStyles},
+		{"_rels/.rels", xlsxRootRels},
+		{"[Content_Types].xml", contentTypes.String()},
+	}
+	for _, p := range parts {
+		f, err := x.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+// xlsxNumber nilai numerik sel; Money ditulis sebagai angka rupiah agar bisa dijumlah di spreadsheet
+func xlsxNumber(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case Money:
+		return n.String(), true
+	case int:
+		return strconv.Itoa(n), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// xlsxColumn nama kolom dari indeks 0-based (0 = A, 26 = AA)
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName nama sheet valid: maks 31 karakter tanpa []:*?/\
+func xlsxSheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// xlsxCleanText membuang karakter kontrol yang tidak valid di XML
+func xlsxCleanText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func xlsxAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xlsxStyles style 0 = normal, 1 = tebal (header)
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`